@@ -0,0 +1,352 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ListAlbums возвращает список альбомов с количеством треков.
+// @Summary Получить список альбомов
+// @Description Возвращает список альбомов с количеством треков, фильтрацией по группе и названию и пагинацией.
+// @Tags Альбомы
+// @Produce json
+// @Param group query string false "Фильтр по названию группы"
+// @Param title query string false "Фильтр по названию альбома"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество записей на странице" default(10)
+// @Success 200 {array} models.AlbumResponse
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /albums [get]
+func (s *Server) ListAlbums(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение списка альбомов")
+	limit, offset := parsePagination(r)
+
+	albums, err := s.albums.List(db.AlbumFilter{
+		Group:  r.URL.Query().Get("group"),
+		Title:  r.URL.Query().Get("title"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	responses := make([]models.AlbumResponse, 0, len(albums))
+	for _, album := range albums {
+		responses = append(responses, models.AlbumResponse{
+			ID:          album.ID,
+			Title:       album.Title,
+			GroupID:     album.GroupID,
+			Group:       album.GroupName,
+			ReleaseDate: album.ReleaseDate.Format("2006-01-02"),
+			CoverLink:   album.CoverLink,
+			TrackCount:  album.TrackCount,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, responses)
+	logrus.Info("Ответ успешно отправлен")
+}
+
+// CreateAlbum добавляет новый альбом.
+// @Summary Добавить альбом
+// @Description Создаёт альбом группы. Если группы с таким названием нет, она будет создана.
+// @Tags Альбомы
+// @Accept json
+// @Produce json
+// @Param album body models.CreateAlbumRequest true "Данные альбома"
+// @Success 201 {object} models.AlbumDetailsResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /albums [post]
+func (s *Server) CreateAlbum(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на создание альбома")
+	var request models.CreateAlbumRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil ||
+		strings.TrimSpace(request.Group) == "" || strings.TrimSpace(request.Title) == "" {
+		logrus.Errorf("Некорректные данные запроса: %v", err)
+		writeError(w, http.StatusBadRequest, "Некорректные данные запроса: укажите group и title")
+		return
+	}
+
+	album := models.Album{
+		Title:     strings.TrimSpace(request.Title),
+		CoverLink: request.CoverLink,
+	}
+	if request.ReleaseDate != "" {
+		releaseDate, err := time.Parse("2006-01-02", request.ReleaseDate)
+		if err != nil {
+			logrus.Errorf("Некорректный формат даты: %v", err)
+			writeError(w, http.StatusBadRequest, "Некорректный формат даты, ожидается формат YYYY-MM-DD")
+			return
+		}
+		album.ReleaseDate = releaseDate
+	}
+
+	group, err := s.groups.FindOrCreate(request.Group)
+	if err != nil {
+		logrus.Errorf("Ошибка при поиске или создании группы: %v", err)
+		writeError(w, http.StatusInternalServerError, "Ошибка при создании группы")
+		return
+	}
+	album.GroupID = group.ID
+
+	if err := s.albums.Create(&album); err != nil {
+		logrus.Errorf("Ошибка при сохранении альбома в базу данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+	album.Group = *group
+
+	logrus.Infof("Альбом %s группы %s сохранён с ID %d", album.Title, group.Name, album.ID)
+	writeJSON(w, http.StatusCreated, newAlbumDetailsResponse(album, nil))
+}
+
+// GetAlbum возвращает альбом вместе с треками.
+// @Summary Получить альбом
+// @Description Возвращает альбом по ID вместе с треками, упорядоченными по номеру диска и трека.
+// @Tags Альбомы
+// @Produce json
+// @Param id path int true "ID альбома"
+// @Success 200 {object} models.AlbumDetailsResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} models.ErrorResponse "Альбом не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /albums/{id} [get]
+func (s *Server) GetAlbum(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение альбома")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	album, ok := s.findAlbum(w, id)
+	if !ok {
+		return
+	}
+
+	tracks, err := s.albums.ListTracks(album.ID)
+	if err != nil {
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAlbumDetailsResponse(*album, tracks))
+	logrus.Info("Ответ успешно отправлен")
+}
+
+// GetAlbumSongs возвращает треки альбома по порядку.
+// @Summary Получить треки альбома
+// @Description Возвращает песни альбома, упорядоченные по номеру диска, номеру трека и ID.
+// @Tags Альбомы
+// @Produce json
+// @Param id path int true "ID альбома"
+// @Success 200 {array} models.SongResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} models.ErrorResponse "Альбом не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /albums/{id}/songs [get]
+func (s *Server) GetAlbumSongs(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение треков альбома")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if _, ok := s.findAlbum(w, id); !ok {
+		return
+	}
+
+	tracks, err := s.albums.ListTracks(id)
+	if err != nil {
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newSongResponses(tracks))
+	logrus.Info("Ответ успешно отправлен")
+}
+
+// UpdateAlbum изменяет данные альбома.
+// @Summary Изменить альбом
+// @Description Обновляет название, дату выпуска и ссылку на обложку альбома. Поля, которые не переданы, остаются без изменений.
+// @Tags Альбомы
+// @Accept json
+// @Produce json
+// @Param id path int true "ID альбома"
+// @Param album body models.UpdateAlbumRequest true "Изменяемые поля"
+// @Success 200 {object} models.MessageResponse "Альбом обновлён"
+// @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса"
+// @Failure 404 {object} models.ErrorResponse "Альбом не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /albums/{id} [patch]
+func (s *Server) UpdateAlbum(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на изменение альбома")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var request models.UpdateAlbumRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logrus.Errorf("Ошибка при декодировании запроса: %v", err)
+		writeError(w, http.StatusBadRequest, "Некорректные данные запроса")
+		return
+	}
+
+	album, ok := s.findAlbum(w, id)
+	if !ok {
+		return
+	}
+
+	if request.Title != nil {
+		if strings.TrimSpace(*request.Title) == "" {
+			logrus.Error("Пустое название альбома")
+			writeError(w, http.StatusBadRequest, "Название альбома не может быть пустым")
+			return
+		}
+		album.Title = strings.TrimSpace(*request.Title)
+	}
+	if request.ReleaseDate != nil {
+		if *request.ReleaseDate == "" {
+			album.ReleaseDate = time.Time{}
+		} else {
+			releaseDate, err := time.Parse("2006-01-02", *request.ReleaseDate)
+			if err != nil {
+				logrus.Errorf("Некорректный формат даты: %v", err)
+				writeError(w, http.StatusBadRequest, "Некорректный формат даты, ожидается формат YYYY-MM-DD")
+				return
+			}
+			album.ReleaseDate = releaseDate
+		}
+	}
+	if request.CoverLink != nil {
+		album.CoverLink = *request.CoverLink
+	}
+
+	if err := s.albums.Update(album); err != nil {
+		logrus.Errorf("Ошибка при обновлении альбома в базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Альбом с ID %d успешно обновлён", id)
+	writeJSON(w, http.StatusOK, models.MessageResponse{Message: "Альбом успешно обновлён"})
+}
+
+// DeleteAlbum удаляет альбом.
+// @Summary Удалить альбом
+// @Description Удаляет альбом по ID. Песни альбома сохраняются, но отвязываются от него.
+// @Tags Альбомы
+// @Produce json
+// @Param id path int true "ID альбома"
+// @Success 200 {object} models.MessageResponse "Альбом удалён"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} models.ErrorResponse "Альбом не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /albums/{id} [delete]
+func (s *Server) DeleteAlbum(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на удаление альбома")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	err := s.albums.Delete(id)
+	if errors.Is(err, db.ErrNotFound) {
+		logrus.Warnf("Альбом с ID %d не найден", id)
+		writeError(w, http.StatusNotFound, "Альбом не найден")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при удалении альбома из базы данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Альбом с ID %d успешно удалён", id)
+	writeJSON(w, http.StatusOK, models.MessageResponse{Message: "Альбом успешно удалён"})
+}
+
+// findAlbum возвращает альбом по ID. Если альбом не найден или произошла ошибка,
+// отправляет соответствующий ответ и возвращает false.
+func (s *Server) findAlbum(w http.ResponseWriter, id int) (*models.Album, bool) {
+	album, err := s.albums.GetByID(id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			logrus.Warnf("Альбом с ID %d не найден", id)
+			writeError(w, http.StatusNotFound, "Альбом не найден")
+			return nil, false
+		}
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return nil, false
+	}
+	return album, true
+}
+
+// setSongAlbum привязывает песню к альбому albumID с номерами диска и трека.
+// albumID = 0 отвязывает песню от альбома. Альбом должен принадлежать группе песни.
+// При ошибке отправляет ответ и возвращает false.
+func (s *Server) setSongAlbum(w http.ResponseWriter, song *models.Song, albumID int) bool {
+	if albumID == 0 {
+		song.AlbumID = nil
+		song.Album = nil
+		return true
+	}
+
+	album, err := s.albums.GetByID(albumID)
+	if errors.Is(err, db.ErrNotFound) {
+		logrus.Warnf("Альбом с ID %d не найден", albumID)
+		writeError(w, http.StatusBadRequest, "Альбом не найден")
+		return false
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return false
+	}
+	if album.GroupID != song.GroupID {
+		logrus.Warnf("Альбом с ID %d принадлежит другой группе", albumID)
+		writeError(w, http.StatusBadRequest, "Альбом принадлежит другой группе")
+		return false
+	}
+
+	song.AlbumID = &album.ID
+	song.Album = album
+	return true
+}
+
+// validTrackPosition проверяет номера диска и трека: 0 означает, что номер не задан.
+func validTrackPosition(w http.ResponseWriter, disc, track int) bool {
+	if disc < 0 || track < 0 {
+		logrus.Errorf("Некорректный номер диска или трека: %d, %d", disc, track)
+		writeError(w, http.StatusBadRequest, "Номера диска и трека не могут быть отрицательными")
+		return false
+	}
+	return true
+}
+
+// newAlbumDetailsResponse преобразует альбом и его треки в формат ответа API.
+func newAlbumDetailsResponse(album models.Album, tracks []models.Song) models.AlbumDetailsResponse {
+	return models.AlbumDetailsResponse{
+		ID:          album.ID,
+		Title:       album.Title,
+		GroupID:     album.GroupID,
+		Group:       album.Group.Name,
+		ReleaseDate: album.ReleaseDate.Format("2006-01-02"),
+		CoverLink:   album.CoverLink,
+		Tracks:      newSongResponses(tracks),
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// noopEnrichment — очередь обогащения, которая ничего не делает.
+type noopEnrichment struct{}
+
+func (noopEnrichment) Enqueue(int) {}
+
+func (noopEnrichment) ProcessNow(context.Context, int) error { return nil }
+
+// serveJSON вызывает handler с телом body и параметром пути id, если он не пустой.
+func serveJSON(handler http.HandlerFunc, method, target, body, id string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if id != "" {
+		r = mux.SetURLVars(r, map[string]string{"id": id})
+	}
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+// decodeJSON разбирает тело ответа со статусом want в v.
+func decodeJSON(t *testing.T, w *httptest.ResponseRecorder, want int, v any) {
+	t.Helper()
+	if w.Code != want {
+		t.Fatalf("статус %d, ожидался %d: %s", w.Code, want, w.Body)
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAlbumHandlers(t *testing.T) {
+	s := NewServer(db.NewMemoryStorage(), noopEnrichment{}, nil)
+
+	for name, body := range map[string]string{
+		"без названия":         `{"group":"Queen"}`,
+		"без группы":           `{"title":"Innuendo"}`,
+		"некорректная дата":    `{"group":"Queen","title":"Innuendo","releaseDate":"04.02.1991"}`,
+		"некорректное тело":    `{`,
+		"название из пробелов": `{"group":"Queen","title":"  "}`,
+	} {
+		if w := serveJSON(s.CreateAlbum, http.MethodPost, "/albums", body, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("создание альбома %s: статус %d", name, w.Code)
+		}
+	}
+
+	var album models.AlbumDetailsResponse
+	decodeJSON(t, serveJSON(s.CreateAlbum, http.MethodPost, "/albums",
+		`{"group":"Queen","title":" Innuendo ","releaseDate":"1991-02-04"}`, ""), http.StatusCreated, &album)
+	if album.ID == 0 || album.Title != "Innuendo" || album.Group != "Queen" || album.ReleaseDate != "1991-02-04" || len(album.Tracks) != 0 {
+		t.Fatalf("созданный альбом: %+v", album)
+	}
+	albumID := strconv.Itoa(album.ID)
+
+	var tracks []int
+	for _, body := range []string{
+		`{"group":"Queen","song":"Headlong","albumId":` + albumID + `,"discNumber":1,"trackNumber":2}`,
+		`{"group":"Queen","song":"Lost Opportunity","albumId":` + albumID + `,"discNumber":2,"trackNumber":1}`,
+		`{"group":"Queen","song":"Innuendo","albumId":` + albumID + `,"discNumber":1,"trackNumber":1}`,
+	} {
+		var song models.SongResponse
+		decodeJSON(t, serveJSON(s.CreateSong, http.MethodPost, "/songs", body, ""), http.StatusOK, &song)
+		if song.AlbumID != album.ID || song.Album != "Innuendo" {
+			t.Errorf("песня альбома: %+v", song)
+		}
+		tracks = append(tracks, song.ID)
+	}
+	for name, body := range map[string]string{
+		"альбом другой группы":      `{"group":"ABBA","song":"Arrival","albumId":` + albumID + `}`,
+		"неизвестный альбом":        `{"group":"Queen","song":"Bijou","albumId":100}`,
+		"отрицательный номер":       `{"group":"Queen","song":"Bijou","albumId":` + albumID + `,"trackNumber":-1}`,
+		"отрицательный номер диска": `{"group":"Queen","song":"Bijou","discNumber":-1}`,
+	} {
+		if w := serveJSON(s.CreateSong, http.MethodPost, "/songs", body, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("песня с %s: статус %d", name, w.Code)
+		}
+	}
+
+	// Треки упорядочены по номеру диска и трека.
+	want := []int{tracks[2], tracks[0], tracks[1]}
+	decodeJSON(t, serveJSON(s.GetAlbum, http.MethodGet, "/albums/"+albumID, "", albumID), http.StatusOK, &album)
+	var songs []models.SongResponse
+	decodeJSON(t, serveJSON(s.GetAlbumSongs, http.MethodGet, "/albums/"+albumID+"/songs", "", albumID), http.StatusOK, &songs)
+	for name, got := range map[string][]models.SongResponse{"альбом": album.Tracks, "треки альбома": songs} {
+		var ids []int
+		for _, song := range got {
+			ids = append(ids, song.ID)
+		}
+		if !slices.Equal(ids, want) {
+			t.Errorf("%s: треки %v, ожидалось %v", name, ids, want)
+		}
+	}
+
+	var albums []models.AlbumResponse
+	decodeJSON(t, serveJSON(s.ListAlbums, http.MethodGet, "/albums?group=queen", "", ""), http.StatusOK, &albums)
+	if len(albums) != 1 || albums[0].ID != album.ID || albums[0].TrackCount != 3 || albums[0].Group != "Queen" {
+		t.Errorf("список альбомов группы: %+v", albums)
+	}
+	decodeJSON(t, serveJSON(s.ListAlbums, http.MethodGet, "/albums?title=Arrival", "", ""), http.StatusOK, &albums)
+	if len(albums) != 0 {
+		t.Errorf("список альбомов по другому названию: %+v", albums)
+	}
+
+	if w := serveJSON(s.DeleteAlbum, http.MethodDelete, "/albums/"+albumID, "", albumID); w.Code != http.StatusOK {
+		t.Fatalf("удаление альбома: статус %d", w.Code)
+	}
+	for name, w := range map[string]*httptest.ResponseRecorder{
+		"получение": serveJSON(s.GetAlbum, http.MethodGet, "/albums/"+albumID, "", albumID),
+		"треки":     serveJSON(s.GetAlbumSongs, http.MethodGet, "/albums/"+albumID+"/songs", "", albumID),
+		"изменение": serveJSON(s.UpdateAlbum, http.MethodPatch, "/albums/"+albumID, `{"title":"Miracle"}`, albumID),
+		"удаление":  serveJSON(s.DeleteAlbum, http.MethodDelete, "/albums/"+albumID, "", albumID),
+	} {
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s удалённого альбома: статус %d", name, w.Code)
+		}
+	}
+	if w := serveJSON(s.GetAlbum, http.MethodGet, "/albums/abc", "", "abc"); w.Code != http.StatusBadRequest {
+		t.Errorf("некорректный ID: статус %d", w.Code)
+	}
+}
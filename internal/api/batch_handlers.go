@@ -0,0 +1,368 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultBatchChunkSize = 100
+	maxBatchChunkSize     = 1000
+	// defaultBatchConcurrency — число песен, одновременно обогащаемых при enrich=true.
+	defaultBatchConcurrency = 4
+	maxBatchConcurrency     = 16
+	// maxBatchLineSize — максимальная длина строки NDJSON.
+	maxBatchLineSize = 1 << 20
+)
+
+// batchItemError — элемент входных данных не удалось разобрать; чтение можно продолжить.
+type batchItemError struct {
+	reason string
+}
+
+func (e *batchItemError) Error() string {
+	return "некорректный элемент: " + e.reason
+}
+
+// ImportSongs добавляет песни пакетом.
+// @Summary Импортировать песни пакетом
+// @Description Добавляет песни из JSON-массива элементов models.CreateSongRequest или из потока NDJSON (по элементу в строке) и возвращает отчёт по каждому элементу. Формат определяется по первому символу тела: «[» — массив, иначе NDJSON. Данные читаются потоком и сохраняются частями по chunkSize песен: каждая часть сохраняется в одной транзакции, и при ошибке базы данных не сохраняется ни одна песня этой части. Элементы без группы или названия, с несуществующим альбомом или альбомом другой группы и с отрицательными номерами диска или трека пропускаются с ошибкой в отчёте. Группы ищутся по названию и псевдонимам один раз на пакет и создаются при необходимости.
+// @Description Без enrich песни, как и в POST /songs, сохраняются со статусом pending и обогащаются в фоновом режиме. С enrich=true каждая часть обогащается данными из внешнего API до ответа, не более чем concurrency песен одновременно, а статус и ошибка обогащения попадают в отчёт; песни, которые не удалось обогатить из-за недоступности источника, остаются в статусе pending и обогащаются позже в фоновом режиме.
+// @Description Если тело перестаёт читаться (например, синтаксическая ошибка в JSON-массиве), импорт прекращается: уже сохранённые песни остаются, а причина возвращается в поле error.
+// @Tags Песни
+// @Accept json
+// @Accept application/x-ndjson
+// @Produce json
+// @Param songs body []models.CreateSongRequest true "Песни: JSON-массив или NDJSON"
+// @Param chunkSize query int false "Количество песен, сохраняемых в одной транзакции (не больше 1000)" default(100)
+// @Param enrich query bool false "Обогатить песни до ответа" default(false)
+// @Param concurrency query int false "Число песен, одновременно обогащаемых при enrich=true (не больше 16)" default(4)
+// @Param X-User header string false "Автор изменения для истории ревизий"
+// @Success 200 {object} models.BatchImportResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
+// @Router /songs:batch [post]
+func (s *Server) ImportSongs(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на пакетный импорт песен")
+	chunkSize, ok := parseBoundedInt(w, r, "chunkSize", defaultBatchChunkSize, maxBatchChunkSize)
+	if !ok {
+		return
+	}
+	concurrency, ok := parseBoundedInt(w, r, "concurrency", defaultBatchConcurrency, maxBatchConcurrency)
+	if !ok {
+		return
+	}
+	enrich := false
+	if value := r.URL.Query().Get("enrich"); value != "" {
+		var err error
+		if enrich, err = strconv.ParseBool(value); err != nil {
+			logrus.Errorf("Некорректное значение enrich: %s", value)
+			writeError(w, http.StatusBadRequest, "Параметр enrich должен быть true или false")
+			return
+		}
+	}
+
+	reader, err := newBatchReader(r.Body)
+	if err != nil {
+		logrus.Errorf("Ошибка при чтении тела пакетного импорта: %v", err)
+		writeError(w, http.StatusBadRequest, "Ожидается JSON-массив песен или NDJSON")
+		return
+	}
+
+	batch := &songBatch{
+		server:      s,
+		ctx:         r.Context(),
+		author:      revisionAuthor(r),
+		enrich:      enrich,
+		concurrency: concurrency,
+		groups:      make(map[string]*models.Group),
+		albums:      make(map[int]*models.Album),
+		response:    models.BatchImportResponse{Items: []models.BatchItemResponse{}},
+	}
+	for index := 0; ; index++ {
+		request, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var itemErr *batchItemError
+		if err != nil && !errors.As(err, &itemErr) {
+			logrus.Errorf("Пакетный импорт прекращён на элементе %d: %v", index, err)
+			batch.response.Error = fmt.Sprintf("Ошибка чтения элемента %d: %v", index, err)
+			break
+		}
+
+		batch.response.Items = append(batch.response.Items, models.BatchItemResponse{Index: index, Group: request.Group, Song: request.Song})
+		if itemErr != nil {
+			batch.fail(index, "Некорректный элемент: "+itemErr.reason)
+			continue
+		}
+		song, message := batch.prepare(request)
+		if message != "" {
+			batch.fail(index, message)
+			continue
+		}
+		batch.add(index, song)
+		if len(batch.chunk) >= chunkSize {
+			batch.flush()
+		}
+	}
+	batch.flush()
+
+	logrus.Infof("Пакетный импорт завершён: добавлено %d, пропущено %d", batch.response.Created, batch.response.Failed)
+	writeJSON(w, http.StatusOK, batch.response)
+}
+
+// songBatch накапливает песни пакетного импорта и сохраняет их частями.
+type songBatch struct {
+	server      *Server
+	ctx         context.Context
+	author      string
+	enrich      bool
+	concurrency int
+	// groups и albums — найденные группы по нормализованному названию и альбомы по ID,
+	// чтобы не искать их заново для каждой песни. Группа, которой ещё нет, хранится
+	// с нулевым ID до сохранения части: её создаёт CreateMany.
+	groups map[string]*models.Group
+	albums map[int]*models.Album
+	// chunk — ещё не сохранённые песни, indexes — номера их элементов.
+	chunk    []models.Song
+	indexes  []int
+	response models.BatchImportResponse
+}
+
+// prepare проверяет элемент и собирает из него песню. Возвращает сообщение об ошибке,
+// если элемент нельзя импортировать.
+func (b *songBatch) prepare(request models.CreateSongRequest) (models.Song, string) {
+	name := db.NormalizeName(request.Group)
+	if name == "" || request.Song == "" {
+		return models.Song{}, "Не указаны группа или название песни"
+	}
+	if request.DiscNumber < 0 || request.TrackNumber < 0 {
+		return models.Song{}, "Номера диска и трека не могут быть отрицательными"
+	}
+
+	// Новые группы не создаются здесь: их создаёт CreateMany в транзакции части, чтобы
+	// при ошибке сохранения не оставалось групп без песен.
+	group, ok := b.groups[name]
+	if !ok {
+		var err error
+		group, err = b.server.groups.FindByName(request.Group)
+		if errors.Is(err, db.ErrNotFound) {
+			group, err = &models.Group{Name: request.Group}, nil
+		}
+		if err != nil {
+			logrus.Errorf("Ошибка при поиске группы: %v", err)
+			return models.Song{}, "Ошибка при поиске группы"
+		}
+		b.groups[name] = group
+	}
+
+	song := models.Song{
+		GroupID:          group.ID,
+		Group:            *group,
+		Song:             request.Song,
+		DiscNumber:       request.DiscNumber,
+		TrackNumber:      request.TrackNumber,
+		EnrichmentStatus: models.EnrichmentPending,
+	}
+	if request.AlbumID != nil && *request.AlbumID != 0 {
+		album, ok := b.albums[*request.AlbumID]
+		if !ok {
+			var err error
+			album, err = b.server.albums.GetByID(*request.AlbumID)
+			if errors.Is(err, db.ErrNotFound) {
+				return models.Song{}, "Альбом не найден"
+			}
+			if err != nil {
+				logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+				return models.Song{}, "Внутренняя ошибка сервера"
+			}
+			b.albums[album.ID] = album
+		}
+		if album.GroupID != song.GroupID {
+			return models.Song{}, "Альбом принадлежит другой группе"
+		}
+		song.AlbumID = &album.ID
+	}
+	return song, ""
+}
+
+// add добавляет песню элемента index в текущую часть.
+func (b *songBatch) add(index int, song models.Song) {
+	b.chunk = append(b.chunk, song)
+	b.indexes = append(b.indexes, index)
+}
+
+// fail отмечает элемент index как неимпортированный.
+func (b *songBatch) fail(index int, message string) {
+	b.response.Items[index].Error = message
+	b.response.Failed++
+}
+
+// flush сохраняет текущую часть в одной транзакции и обогащает или ставит в очередь её песни.
+func (b *songBatch) flush() {
+	if len(b.chunk) == 0 {
+		return
+	}
+	defer func() {
+		b.chunk = b.chunk[:0]
+		b.indexes = b.indexes[:0]
+		// Группы, которые создавались вместе с частью, появились или откатились вместе
+		// с ней: при следующем элементе их нужно найти заново.
+		for name, group := range b.groups {
+			if group.ID == 0 {
+				delete(b.groups, name)
+			}
+		}
+	}()
+
+	if err := b.server.songs.CreateMany(b.chunk, b.author); err != nil {
+		logrus.Errorf("Ошибка при сохранении части пакетного импорта (элементы %d–%d): %v", b.indexes[0], b.indexes[len(b.indexes)-1], err)
+		for _, index := range b.indexes {
+			b.fail(index, "Ошибка при сохранении песен, элементы этой части не сохранены")
+		}
+		return
+	}
+
+	for i, song := range b.chunk {
+		item := &b.response.Items[b.indexes[i]]
+		item.ID = song.ID
+		item.EnrichmentStatus = song.EnrichmentStatus
+		b.response.Created++
+	}
+	logrus.Infof("Сохранено песен пакетного импорта: %d", len(b.chunk))
+
+	if !b.enrich {
+		for _, song := range b.chunk {
+			b.server.enrichment.Enqueue(song.ID)
+		}
+		return
+	}
+	b.enrichChunk()
+}
+
+// enrichChunk обогащает песни текущей части, не более concurrency одновременно, и
+// записывает результат обогащения в отчёт.
+func (b *songBatch) enrichChunk() {
+	semaphore := make(chan struct{}, b.concurrency)
+	var wg sync.WaitGroup
+	for i, song := range b.chunk {
+		item := &b.response.Items[b.indexes[i]]
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func(id int) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			if err := b.server.enrichment.ProcessNow(b.ctx, id); err != nil {
+				logrus.Errorf("Ошибка обогащения песни с ID %d: %v", id, err)
+			}
+			enriched, err := b.server.songs.GetByID(id)
+			if err != nil {
+				logrus.Errorf("Ошибка при получении песни с ID %d после обогащения: %v", id, err)
+				return
+			}
+			item.EnrichmentStatus = enriched.EnrichmentStatus
+			item.EnrichmentError = enriched.EnrichmentError
+		}(song.ID)
+	}
+	wg.Wait()
+}
+
+// batchReader читает элементы пакетного импорта по одному из JSON-массива или NDJSON.
+type batchReader struct {
+	decoder *json.Decoder
+	lines   *bufio.Scanner
+}
+
+// newBatchReader определяет формат по первому непробельному символу тела: «[» — JSON-массив,
+// иначе NDJSON.
+func newBatchReader(body io.Reader) (*batchReader, error) {
+	buffered := bufio.NewReader(body)
+	for {
+		next, err := buffered.Peek(1)
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("пустое тело запроса")
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch next[0] {
+		case ' ', '\t', '\r', '\n':
+			buffered.ReadByte()
+			continue
+		case '[':
+			decoder := json.NewDecoder(buffered)
+			if _, err := decoder.Token(); err != nil {
+				return nil, err
+			}
+			return &batchReader{decoder: decoder}, nil
+		}
+		lines := bufio.NewScanner(buffered)
+		lines.Buffer(make([]byte, 0, 64*1024), maxBatchLineSize)
+		return &batchReader{lines: lines}, nil
+	}
+}
+
+// Next возвращает следующий элемент или io.EOF после последнего. Ошибка *batchItemError
+// относится только к этому элементу; после остальных ошибок чтение продолжить нельзя.
+func (b *batchReader) Next() (models.CreateSongRequest, error) {
+	var request models.CreateSongRequest
+	if b.decoder != nil {
+		if !b.decoder.More() {
+			if _, err := b.decoder.Token(); err != nil {
+				return request, err
+			}
+			return request, io.EOF
+		}
+		err := b.decoder.Decode(&request)
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return request, &batchItemError{reason: fmt.Sprintf("поле %s должно быть типа %s", typeErr.Field, typeErr.Type)}
+		}
+		return request, err
+	}
+
+	for b.lines.Scan() {
+		line := bytes.TrimSpace(b.lines.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(line, &request); err != nil {
+			return request, &batchItemError{reason: err.Error()}
+		}
+		return request, nil
+	}
+	if err := b.lines.Err(); err != nil {
+		return request, err
+	}
+	return request, io.EOF
+}
+
+// parseBoundedInt читает положительный целочисленный параметр запроса name, не больше max;
+// без параметра возвращает fallback. При ошибке отправляет ответ 400 и возвращает false.
+func parseBoundedInt(w http.ResponseWriter, r *http.Request, name string, fallback, max int) (int, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, true
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 1 || parsed > max {
+		logrus.Errorf("Некорректное значение %s: %s", name, value)
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Параметр %s должен быть целым числом от 1 до %d", name, max))
+		return 0, false
+	}
+	return parsed, true
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"errors"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// GetSongEnrichment возвращает состояние обогащения песни.
+// @Summary Получить статус обогащения песни
+// @Description Возвращает статус фонового обогащения песни данными из внешнего API, число попыток, последнюю ошибку и время следующей попытки.
+// @Tags Обогащение
+// @Produce json
+// @Param id path int true "ID песни"
+// @Success 200 {object} models.EnrichmentResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/enrichment [get]
+func (s *Server) GetSongEnrichment(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение статуса обогащения песни")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	song, err := s.songs.GetByID(id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			logrus.Warnf("Песня с ID %d не найдена", id)
+			writeError(w, http.StatusNotFound, "Песня не найдена")
+			return
+		}
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newEnrichmentResponse(song))
+	logrus.Info("Ответ успешно отправлен")
+}
+
+// RetrySongEnrichment повторно ставит песню в очередь на обогащение.
+// @Summary Перезапустить обогащение песни
+// @Description Сбрасывает счётчик попыток, переводит песню в статус pending и ставит её в очередь на обогащение данными из внешнего API.
+// @Tags Обогащение
+// @Produce json
+// @Param id path int true "ID песни"
+// @Success 202 {object} models.EnrichmentResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/enrichment [post]
+func (s *Server) RetrySongEnrichment(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на перезапуск обогащения песни")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	// Меняются только поля статуса обогащения: правки песни, сделанные параллельно, не теряются.
+	song, err := s.songs.ResetEnrichment(id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			logrus.Warnf("Песня с ID %d не найдена", id)
+			writeError(w, http.StatusNotFound, "Песня не найдена")
+			return
+		}
+		logrus.Errorf("Ошибка при обновлении песни в базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+	s.enrichment.Enqueue(song.ID)
+
+	logrus.Infof("Песня с ID %d поставлена в очередь на обогащение", id)
+	writeJSON(w, http.StatusAccepted, newEnrichmentResponse(song))
+}
+
+func newEnrichmentResponse(song *models.Song) models.EnrichmentResponse {
+	response := models.EnrichmentResponse{
+		SongID:   song.ID,
+		Status:   song.EnrichmentStatus,
+		Attempts: song.EnrichmentAttempts,
+		Error:    song.EnrichmentError,
+	}
+	if song.EnrichmentNextAt != nil {
+		response.NextAttemptAt = song.EnrichmentNextAt.Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,358 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// exportPageSize — число песен, которые выгрузка читает из базы данных за один запрос.
+const exportPageSize = 500
+
+// exportColumn — столбец выгрузки песен.
+type exportColumn struct {
+	name  string
+	value func(song models.Song) any
+}
+
+// exportColumns — столбцы выгрузки в порядке по умолчанию. Текст песни (столбец text)
+// добавляется последним при lyrics=true.
+var exportColumns = []exportColumn{
+	{"id", func(song models.Song) any { return song.ID }},
+	{"group", func(song models.Song) any { return song.Group.Name }},
+	{"song", func(song models.Song) any { return song.Song }},
+	{"album", func(song models.Song) any {
+		if song.Album == nil {
+			return ""
+		}
+		return song.Album.Title
+	}},
+	{"albumId", func(song models.Song) any {
+		if song.AlbumID == nil {
+			return nil
+		}
+		return *song.AlbumID
+	}},
+	{"discNumber", func(song models.Song) any { return song.DiscNumber }},
+	{"trackNumber", func(song models.Song) any { return song.TrackNumber }},
+	{"releaseDate", func(song models.Song) any {
+		if song.ReleaseDate.IsZero() {
+			return ""
+		}
+		return song.ReleaseDate.Format("2006-01-02")
+	}},
+	{"duration", func(song models.Song) any { return song.Duration }},
+	{"language", func(song models.Song) any { return song.Language }},
+	{"link", func(song models.Song) any { return song.Link }},
+	{"genres", func(song models.Song) any {
+		genres := make([]string, 0, len(song.Genres))
+		for _, genre := range song.Genres {
+			genres = append(genres, genre.Name)
+		}
+		return genres
+	}},
+	{"tags", func(song models.Song) any {
+		tags := make([]string, 0, len(song.Tags))
+		for _, tag := range song.Tags {
+			tags = append(tags, tag.Name)
+		}
+		return tags
+	}},
+	{"enrichmentStatus", func(song models.Song) any { return song.EnrichmentStatus }},
+}
+
+// lyricsColumn — столбец с текстом песни.
+var lyricsColumn = exportColumn{"text", func(song models.Song) any { return song.Text }}
+
+// exportFormats сопоставляет форматам выгрузки тип содержимого и расширение файла.
+var exportFormats = map[string]struct {
+	contentType string
+	extension   string
+}{
+	"csv":    {"text/csv; charset=utf-8", "csv"},
+	"ndjson": {"application/x-ndjson", "ndjson"},
+	"json":   {"application/json", "json"},
+}
+
+// ExportSongs выгружает песни в файл.
+// @Summary Выгрузить песни
+// @Description Выгружает все песни, подходящие под фильтры (те же, что у GET /songs, без пагинации), в формате CSV, NDJSON (по объекту в строке) или JSON-массива. Песни читаются из базы данных частями и сразу отправляются клиенту, поэтому выгрузка не ограничена размером библиотеки. Порядок песен тот же, что у GET /songs.
+// @Description Набор и порядок столбцов задаются параметром columns, по умолчанию выгружаются все: id, group, song, album, albumId, discNumber, trackNumber, releaseDate, duration, language, link, genres, tags, enrichmentStatus. Текст песни добавляется последним столбцом text при lyrics=true. В CSV первая строка — заголовок, а жанры и теги перечислены через точку с запятой; в NDJSON и JSON они выгружаются массивами. Неизвестная дата выпуска выгружается пустой строкой, а albumId песни без альбома — пустым значением (null в NDJSON и JSON).
+// @Description Если ошибка базы данных возникает после начала передачи, выгрузка обрывается. С q или fuzzy в SQLite каждая часть заново ищет среди всех песен, подходящих под остальные фильтры, поэтому время такой выгрузки растёт квадратично с их числом.
+// @Tags Песни
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce json
+// @Param format query string false "Формат выгрузки" Enums(csv, ndjson, json) default(csv)
+// @Param columns query []string false "Столбцы выгрузки (несколько через запятую или повтором параметра)" collectionFormat(multi)
+// @Param lyrics query bool false "Выгрузить текст песни" default(false)
+// @Param song query string false "Фильтр по названию песни"
+// @Param id query int false "Фильтр по id"
+// @Param group query string false "Фильтр по названию группы"
+// @Param text query string false "Фильтр по фрагменту текста песни (с учётом регистра)"
+// @Param q query string false "Полнотекстовый поиск по названию и тексту песни; без sort песни упорядочены по релевантности"
+// @Param fuzzy query bool false "Сравнивать song и group по сходству (с опечатками), а не на равенство"
+// @Param similarity query number false "Минимальное сходство названий при fuzzy=true, от 0 до 1" default(0.3)
+// @Param link query string false "Фильтр по ссылке"
+// @Param album query string false "Фильтр по названию альбома"
+// @Param releaseDate query string false "Фильтр по точной дате выпуска, ГГГГ-ММ-ДД"
+// @Param releasedFrom query string false "Выпущены не раньше даты, ГГГГ-ММ-ДД"
+// @Param releasedTo query string false "Выпущены не позже даты, ГГГГ-ММ-ДД"
+// @Param year query int false "Год выпуска"
+// @Param decade query int false "Десятилетие выпуска, например 1970"
+// @Param genre query []string false "Фильтр по жанрам" collectionFormat(multi)
+// @Param genreMatch query string false "any или all" Enums(any, all) default(any)
+// @Param tag query []string false "Фильтр по тегам" collectionFormat(multi)
+// @Param tagMatch query string false "any или all" Enums(any, all) default(any)
+// @Param sort query string false "Сортировка, как у GET /songs" example(-releaseDate,group,song)
+// @Success 200 {file} file "Файл с песнями"
+// @Failure 400 {object} models.ErrorResponse "Некорректные параметры выгрузки или фильтра"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/export [get]
+func (s *Server) ExportSongs(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на выгрузку песен")
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	spec, ok := exportFormats[format]
+	if !ok {
+		logrus.Errorf("Некорректный формат выгрузки: %s", format)
+		writeError(w, http.StatusBadRequest, "Параметр format должен быть csv, ndjson или json")
+		return
+	}
+	columns, ok := parseExportColumns(w, r)
+	if !ok {
+		return
+	}
+	filter, ok := parseSongFilter(w, r)
+	if !ok {
+		return
+	}
+
+	// Курсор хранит ключ сортировки, поэтому при сортировке по релевантности страницы
+	// читаются по смещению. Без поиска в базе данных (SQLite, хранилище в памяти) каждая
+	// страница с q или fuzzy заново проверяет все песни под остальными фильтрами, так что
+	// время такой выгрузки растёт квадратично с их числом; это допустимо для небольших
+	// библиотек, для которых эти хранилища и предназначены.
+	relevance := (filter.Query != "" || filter.Fuzzy) && len(filter.Sort) == 0
+	filter.Limit = exportPageSize
+	filter.Offset = 0
+
+	var writer *songExportWriter
+	exported := 0
+	for {
+		results, err := s.findSongs(filter)
+		if err != nil {
+			logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+			if writer == nil {
+				writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+			}
+			return
+		}
+		if writer == nil {
+			w.Header().Set("Content-Type", spec.contentType)
+			w.Header().Set("Content-Disposition", `attachment; filename="songs.`+spec.extension+`"`)
+			w.WriteHeader(http.StatusOK)
+			writer = newSongExportWriter(w, format, columns)
+			if err := writer.begin(); err != nil {
+				logrus.Errorf("Ошибка при отправке выгрузки: %v", err)
+				return
+			}
+		}
+		for _, result := range results {
+			if err := writer.write(result.Song); err != nil {
+				logrus.Errorf("Ошибка при отправке выгрузки: %v", err)
+				return
+			}
+		}
+		exported += len(results)
+		if len(results) < exportPageSize {
+			break
+		}
+		if err := writer.flush(); err != nil {
+			logrus.Errorf("Ошибка при отправке выгрузки: %v", err)
+			return
+		}
+		if relevance {
+			filter.Offset += len(results)
+		} else {
+			filter.Cursor = &db.SongCursor{Key: db.NewSongSortKey(results[len(results)-1].Song, filter.Sort)}
+		}
+	}
+	if err := writer.end(); err != nil {
+		logrus.Errorf("Ошибка при отправке выгрузки: %v", err)
+		return
+	}
+	logrus.Infof("Выгружено песен: %d", exported)
+}
+
+// parseExportColumns читает столбцы выгрузки из параметров columns и lyrics. При ошибке
+// отправляет ответ 400 и возвращает false.
+func parseExportColumns(w http.ResponseWriter, r *http.Request) ([]exportColumn, bool) {
+	columns := exportColumns
+	if names := parseList(r, "columns"); len(names) > 0 {
+		columns = make([]exportColumn, 0, len(names))
+		for _, name := range names {
+			column, ok := findExportColumn(name)
+			if !ok {
+				logrus.Errorf("Неизвестный столбец выгрузки: %s", name)
+				writeError(w, http.StatusBadRequest, "Неизвестный столбец "+name+", доступны: "+exportColumnNames()+"; текст песни выгружается при lyrics=true")
+				return nil, false
+			}
+			columns = append(columns, column)
+		}
+	}
+
+	if value := r.URL.Query().Get("lyrics"); value != "" {
+		lyrics, err := strconv.ParseBool(value)
+		if err != nil {
+			logrus.Errorf("Некорректное значение lyrics: %s", value)
+			writeError(w, http.StatusBadRequest, "Параметр lyrics должен быть true или false")
+			return nil, false
+		}
+		if lyrics {
+			columns = append(columns[:len(columns):len(columns)], lyricsColumn)
+		}
+	}
+	return columns, true
+}
+
+// findExportColumn возвращает столбец выгрузки по названию.
+func findExportColumn(name string) (exportColumn, bool) {
+	for _, column := range exportColumns {
+		if column.name == name {
+			return column, true
+		}
+	}
+	return exportColumn{}, false
+}
+
+// exportColumnNames перечисляет названия столбцов выгрузки через запятую.
+func exportColumnNames() string {
+	names := make([]string, 0, len(exportColumns))
+	for _, column := range exportColumns {
+		names = append(names, column.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// songExportWriter записывает песни в выбранном формате выгрузки.
+type songExportWriter struct {
+	w       http.ResponseWriter
+	out     *bufio.Writer
+	csv     *csv.Writer
+	format  string
+	columns []exportColumn
+	count   int
+}
+
+func newSongExportWriter(w http.ResponseWriter, format string, columns []exportColumn) *songExportWriter {
+	writer := &songExportWriter{w: w, out: bufio.NewWriter(w), format: format, columns: columns}
+	if format == "csv" {
+		writer.csv = csv.NewWriter(writer.out)
+	}
+	return writer
+}
+
+// begin записывает начало файла: заголовок CSV или открывающую скобку JSON-массива.
+func (e *songExportWriter) begin() error {
+	switch e.format {
+	case "csv":
+		header := make([]string, 0, len(e.columns))
+		for _, column := range e.columns {
+			header = append(header, column.name)
+		}
+		return e.csv.Write(header)
+	case "json":
+		_, err := e.out.WriteString("[")
+		return err
+	}
+	return nil
+}
+
+// write записывает одну песню.
+func (e *songExportWriter) write(song models.Song) error {
+	if e.format == "csv" {
+		record := make([]string, 0, len(e.columns))
+		for _, column := range e.columns {
+			record = append(record, formatCSVValue(column.value(song)))
+		}
+		return e.csv.Write(record)
+	}
+
+	var object bytes.Buffer
+	if e.format == "json" && e.count > 0 {
+		object.WriteString(",")
+	}
+	e.count++
+	// Объект собирается вручную, чтобы поля шли в порядке столбцов.
+	object.WriteString("{")
+	for i, column := range e.columns {
+		if i > 0 {
+			object.WriteString(",")
+		}
+		name, _ := json.Marshal(column.name)
+		value, err := json.Marshal(column.value(song))
+		if err != nil {
+			return err
+		}
+		object.Write(name)
+		object.WriteString(":")
+		object.Write(value)
+	}
+	object.WriteString("}")
+	if e.format == "ndjson" {
+		object.WriteString("\n")
+	}
+	_, err := e.out.Write(object.Bytes())
+	return err
+}
+
+// flush отправляет клиенту уже записанные песни.
+func (e *songExportWriter) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := e.out.Flush(); err != nil {
+		return err
+	}
+	if flusher, ok := e.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// end записывает конец файла и отправляет остаток клиенту.
+func (e *songExportWriter) end() error {
+	if e.format == "json" {
+		if _, err := e.out.WriteString("]"); err != nil {
+			return err
+		}
+	}
+	return e.flush()
+}
+
+// formatCSVValue записывает значение столбца строкой CSV: списки — через точку с запятой.
+func formatCSVValue(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case int:
+		return strconv.Itoa(value)
+	case []string:
+		return strings.Join(value, ";")
+	}
+	return ""
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newExportServer возвращает сервер с двумя песнями: с альбомом, жанрами и текстом и без них.
+func newExportServer(t *testing.T) *Server {
+	t.Helper()
+	storage := db.NewMemoryStorage()
+	group, err := storage.Groups.FindOrCreate("Queen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	album := models.Album{GroupID: group.ID, Title: "Innuendo"}
+	if err := storage.Albums.Create(&album); err != nil {
+		t.Fatal(err)
+	}
+	rock, err := storage.Genres.Create("Rock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opera, err := storage.Genres.Create("Opera")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, song := range []models.Song{
+		{GroupID: group.ID, Song: "Innuendo", AlbumID: &album.ID, TrackNumber: 1,
+			ReleaseDate: time.Date(1991, 1, 14, 0, 0, 0, 0, time.UTC), Text: "While the sun hangs in the sky,\n\"and the desert has sand\""},
+		{GroupID: group.ID, Song: "Mustapha"},
+	} {
+		if err := storage.Songs.Create(&song, "test"); err != nil {
+			t.Fatal(err)
+		}
+		if song.AlbumID != nil {
+			if err := storage.Songs.SetGenres(song.ID, []int{rock.ID, opera.ID}, "test"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return NewServer(storage, noopEnrichment{}, nil)
+}
+
+func TestExportColumns(t *testing.T) {
+	s := newExportServer(t)
+
+	w := serveJSON(s.ExportSongs, http.MethodGet, "/songs/export?columns=id,song&columns=genres,albumId,releaseDate&lyrics=true", "", "")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/csv; charset=utf-8" ||
+		w.Header().Get("Content-Disposition") != `attachment; filename="songs.csv"` {
+		t.Fatalf("статус %d, заголовки %v", w.Code, w.Header())
+	}
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"id", "song", "genres", "albumId", "releaseDate", "text"},
+		{"1", "Innuendo", "Opera;Rock", "1", "1991-01-14", "While the sun hangs in the sky,\n\"and the desert has sand\""},
+		{"2", "Mustapha", "", "", "", ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("строк CSV %d, ожидалось %d: %q", len(records), len(want), records)
+	}
+	for i := range want {
+		if !slices.Equal(records[i], want[i]) {
+			t.Errorf("строка CSV %d: %q, ожидалось %q", i, records[i], want[i])
+		}
+	}
+
+	// По умолчанию выгружаются все столбцы, кроме текста.
+	w = serveJSON(s.ExportSongs, http.MethodGet, "/songs/export?lyrics=false", "", "")
+	header, err := csv.NewReader(w.Body).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(header, ", "); got != exportColumnNames() || slices.Contains(header, "text") {
+		t.Errorf("столбцы по умолчанию: %s", got)
+	}
+
+	for name, query := range map[string]string{
+		"неизвестный столбец": "columns=id,text",
+		"некорректный lyrics": "lyrics=maybe",
+		"неизвестный формат":  "format=xml",
+		"некорректный фильтр": "releasedFrom=1991",
+	} {
+		if w := serveJSON(s.ExportSongs, http.MethodGet, "/songs/export?"+query, "", ""); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: статус %d", name, w.Code)
+		}
+	}
+}
+
+func TestExportJSONFormats(t *testing.T) {
+	s := newExportServer(t)
+	query := "columns=song,albumId,genres&lyrics=true"
+
+	w := serveJSON(s.ExportSongs, http.MethodGet, "/songs/export?format=ndjson&"+query, "", "")
+	if w.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Errorf("Content-Type NDJSON: %s", w.Header().Get("Content-Type"))
+	}
+	var lines []string
+	for scanner := bufio.NewScanner(w.Body); scanner.Scan(); {
+		lines = append(lines, scanner.Text())
+	}
+	want := []string{
+		`{"song":"Innuendo","albumId":1,"genres":["Opera","Rock"],"text":"While the sun hangs in the sky,\n\"and the desert has sand\""}`,
+		`{"song":"Mustapha","albumId":null,"genres":[],"text":""}`,
+	}
+	if !slices.Equal(lines, want) {
+		t.Errorf("NDJSON:\n%s\nожидалось:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+
+	w = serveJSON(s.ExportSongs, http.MethodGet, "/songs/export?format=json&"+query, "", "")
+	if got := w.Body.String(); got != "["+strings.Join(want, ",")+"]" {
+		t.Errorf("JSON: %s", got)
+	}
+	if !json.Valid(w.Body.Bytes()) {
+		t.Error("выгрузка JSON — некорректный JSON")
+	}
+
+	w = serveJSON(s.ExportSongs, http.MethodGet, "/songs/export?format=json&song=Bijou", "", "")
+	if got := w.Body.String(); w.Code != http.StatusOK || got != "[]" {
+		t.Errorf("пустая выгрузка JSON: статус %d, %s", w.Code, got)
+	}
+}
+
+func TestExportStreamsPages(t *testing.T) {
+	storage := db.NewMemoryStorage()
+	group, err := storage.Groups.FindOrCreate("Queen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Больше двух страниц выгрузки. У песен повторяются названия, поэтому курсор должен
+	// различать их по ID.
+	count := 2*exportPageSize + 7
+	for i := 0; i < count; i++ {
+		song := models.Song{GroupID: group.ID, Song: "Song " + strconv.Itoa(i%10), Text: "love"}
+		if i%3 == 0 {
+			song.Text = "love love"
+		}
+		if err := storage.Songs.Create(&song, "test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := NewServer(storage, noopEnrichment{}, nil)
+
+	for _, query := range []string{"sort=-song", "", "q=love", "q=love&sort=song"} {
+		t.Run(query, func(t *testing.T) {
+			w := serveJSON(s.ExportSongs, http.MethodGet, "/songs/export?format=ndjson&columns=id&"+query, "", "")
+			if w.Code != http.StatusOK || !w.Flushed {
+				t.Fatalf("статус %d, отправлено частями: %v", w.Code, w.Flushed)
+			}
+			var got []int
+			for scanner := bufio.NewScanner(w.Body); scanner.Scan(); {
+				var row struct{ ID int }
+				if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, row.ID)
+			}
+
+			filter, ok := parseSongFilter(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/songs?"+query, nil))
+			if !ok {
+				t.Fatal("некорректный фильтр")
+			}
+			filter.Limit, filter.Offset = 0, 0
+			results, err := s.findSongs(filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want []int
+			for _, result := range results {
+				want = append(want, result.Song.ID)
+			}
+			if len(want) != count || !slices.Equal(got, want) {
+				t.Errorf("выгружено %d песен, ожидалось %d в порядке GET /songs", len(got), len(want))
+			}
+		})
+	}
+}
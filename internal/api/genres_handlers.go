@@ -0,0 +1,225 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ListGenres возвращает справочник жанров.
+// @Summary Получить список жанров
+// @Description Возвращает все жанры по названию с количеством песен у каждого.
+// @Tags Жанры и теги
+// @Produce json
+// @Success 200 {array} models.GenreResponse
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /genres [get]
+func (s *Server) ListGenres(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение списка жанров")
+	genres, err := s.genres.List()
+	if err != nil {
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	responses := make([]models.GenreResponse, 0, len(genres))
+	for _, genre := range genres {
+		responses = append(responses, models.GenreResponse{ID: genre.ID, Name: genre.Name, SongCount: genre.SongCount})
+	}
+
+	writeJSON(w, http.StatusOK, responses)
+	logrus.Info("Ответ успешно отправлен")
+}
+
+// CreateGenre добавляет жанр в справочник.
+// @Summary Добавить жанр
+// @Tags Жанры и теги
+// @Accept json
+// @Produce json
+// @Param genre body models.CreateGenreRequest true "Название жанра"
+// @Success 201 {object} models.GenreResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса"
+// @Failure 409 {object} models.ErrorResponse "Жанр уже существует"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /genres [post]
+func (s *Server) CreateGenre(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на создание жанра")
+	var request models.CreateGenreRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || strings.TrimSpace(request.Name) == "" {
+		logrus.Errorf("Некорректные данные запроса: %v", err)
+		writeError(w, http.StatusBadRequest, "Некорректные данные запроса")
+		return
+	}
+
+	genre, err := s.genres.Create(request.Name)
+	if errors.Is(err, db.ErrNameConflict) {
+		logrus.Warnf("Жанр %s уже существует", request.Name)
+		writeError(w, http.StatusConflict, "Жанр с таким названием уже существует")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при сохранении жанра в базу данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Жанр %s сохранён с ID %d", genre.Name, genre.ID)
+	writeJSON(w, http.StatusCreated, models.GenreResponse{ID: genre.ID, Name: genre.Name})
+}
+
+// UpdateGenre переименовывает жанр.
+// @Summary Переименовать жанр
+// @Tags Жанры и теги
+// @Accept json
+// @Produce json
+// @Param id path int true "ID жанра"
+// @Param genre body models.UpdateGenreRequest true "Новое название жанра"
+// @Success 200 {object} models.MessageResponse "Жанр переименован"
+// @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса"
+// @Failure 404 {object} models.ErrorResponse "Жанр не найден"
+// @Failure 409 {object} models.ErrorResponse "Жанр с таким названием уже существует"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /genres/{id} [patch]
+func (s *Server) UpdateGenre(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на переименование жанра")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var request models.UpdateGenreRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || strings.TrimSpace(request.Name) == "" {
+		logrus.Errorf("Некорректные данные запроса: %v", err)
+		writeError(w, http.StatusBadRequest, "Некорректные данные запроса")
+		return
+	}
+
+	err := s.genres.Update(&models.Genre{ID: id, Name: request.Name})
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		logrus.Warnf("Жанр с ID %d не найден", id)
+		writeError(w, http.StatusNotFound, "Жанр не найден")
+		return
+	case errors.Is(err, db.ErrNameConflict):
+		logrus.Warnf("Жанр %s уже существует", request.Name)
+		writeError(w, http.StatusConflict, "Жанр с таким названием уже существует")
+		return
+	case err != nil:
+		logrus.Errorf("Ошибка при обновлении жанра в базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Жанр с ID %d переименован в %s", id, request.Name)
+	writeJSON(w, http.StatusOK, models.MessageResponse{Message: "Жанр успешно переименован"})
+}
+
+// DeleteGenre удаляет жанр из справочника.
+// @Summary Удалить жанр
+// @Description Удаляет жанр и снимает его со всех песен.
+// @Tags Жанры и теги
+// @Produce json
+// @Param id path int true "ID жанра"
+// @Success 200 {object} models.MessageResponse "Жанр удалён"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} models.ErrorResponse "Жанр не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /genres/{id} [delete]
+func (s *Server) DeleteGenre(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на удаление жанра")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	err := s.genres.Delete(id)
+	if errors.Is(err, db.ErrNotFound) {
+		logrus.Warnf("Жанр с ID %d не найден", id)
+		writeError(w, http.StatusNotFound, "Жанр не найден")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при удалении жанра из базы данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Жанр с ID %d успешно удалён", id)
+	writeJSON(w, http.StatusOK, models.MessageResponse{Message: "Жанр успешно удалён"})
+}
+
+// SetSongGenres заменяет жанры песни.
+// @Summary Задать жанры песни
+// @Description Заменяет жанры песни переданным списком. Жанры должны существовать в справочнике.
+// @Tags Жанры и теги
+// @Accept json
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param genres body models.SetSongGenresRequest true "Названия жанров"
+// @Param X-User header string false "Автор изменения для истории ревизий"
+// @Success 200 {object} models.SongResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса или неизвестный жанр"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/genres [put]
+func (s *Server) SetSongGenres(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на изменение жанров песни")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var request models.SetSongGenresRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logrus.Errorf("Ошибка при декодировании запроса: %v", err)
+		writeError(w, http.StatusBadRequest, "Некорректные данные запроса")
+		return
+	}
+
+	if _, ok := s.findSong(w, id); !ok {
+		return
+	}
+
+	genres, err := s.genres.FindByNames(request.Genres)
+	if err != nil {
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+	known := make(map[string]bool, len(genres))
+	ids := make([]int, 0, len(genres))
+	for _, genre := range genres {
+		known[genre.NormalizedName] = true
+		ids = append(ids, genre.ID)
+	}
+	var unknown []string
+	for _, name := range request.Genres {
+		if normalized := db.NormalizeName(name); normalized != "" && !known[normalized] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		logrus.Warnf("Неизвестные жанры: %v", unknown)
+		writeError(w, http.StatusBadRequest, "Неизвестные жанры: "+strings.Join(unknown, ", "))
+		return
+	}
+
+	if err := s.songs.SetGenres(id, ids, revisionAuthor(r)); err != nil {
+		logrus.Errorf("Ошибка при сохранении жанров песни: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+	logrus.Infof("Жанры песни с ID %d обновлены", id)
+
+	song, ok := s.findSong(w, id)
+	if !ok {
+		return
+	}
+	writeSong(w, http.StatusOK, *song)
+}
@@ -0,0 +1,437 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Политики обработки песен при удалении группы.
+const (
+	orphansRestrict = "restrict"
+	orphansDelete   = "delete"
+)
+
+// ListGroups возвращает список групп с количеством песен.
+// @Summary Получить список групп
+// @Description Возвращает список групп с количеством песен у каждой и пагинацией.
+// @Tags Группы
+// @Produce json
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество записей на странице" default(10)
+// @Success 200 {array} models.GroupResponse
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups [get]
+func (s *Server) ListGroups(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение списка групп")
+	limit, offset := parsePagination(r)
+
+	groups, err := s.groups.List(limit, offset)
+	if err != nil {
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	responses := make([]models.GroupResponse, 0, len(groups))
+	for _, group := range groups {
+		responses = append(responses, models.GroupResponse{
+			ID:        group.ID,
+			Name:      group.Name,
+			SongCount: group.SongCount,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, responses)
+	logrus.Info("Ответ успешно отправлен")
+}
+
+// GetGroup возвращает группу вместе с её песнями.
+// @Summary Получить группу
+// @Description Возвращает группу по ID вместе с псевдонимами и списком всех её песен.
+// @Tags Группы
+// @Produce json
+// @Param id path int true "ID группы"
+// @Success 200 {object} models.GroupDetailsResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} models.ErrorResponse "Группа не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/{id} [get]
+func (s *Server) GetGroup(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение группы")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	group, ok := s.findGroup(w, id)
+	if !ok {
+		return
+	}
+
+	details, err := s.groupDetails(group)
+	if err != nil {
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, details)
+	logrus.Info("Ответ успешно отправлен")
+}
+
+// GetGroupSongs возвращает песни группы с фильтрацией и пагинацией.
+// @Summary Получить песни группы
+// @Description Возвращает песни группы с теми же фильтрами и пагинацией, что и GET /songs.
+// @Tags Группы
+// @Produce json
+// @Param id path int true "ID группы"
+// @Param song query string false "Фильтр по названию песни"
+// @Param text query string false "Фильтр по фрагменту текста песни (с учётом регистра)"
+// @Param q query string false "Полнотекстовый поиск по названию и тексту песни с учётом словоформ (русский и английский). Результаты упорядочены по релевантности и содержат rank и snippet"
+// @Param fuzzy query bool false "Сравнивать song по сходству (с опечатками), а не на равенство; результаты упорядочены по сходству score"
+// @Param similarity query number false "Минимальное сходство названий при fuzzy=true, от 0 до 1" default(0.3)
+// @Param link query string false "Фильтр по ссылке"
+// @Param album query string false "Фильтр по названию альбома"
+// @Param releaseDate query string false "Фильтр по точной дате выпуска в формате ГГГГ-ММ-ДД" example(1975-10-31)
+// @Param releasedFrom query string false "Выпущены не раньше даты (включительно), ГГГГ-ММ-ДД" example(1970-01-01)
+// @Param releasedTo query string false "Выпущены не позже даты (включительно), ГГГГ-ММ-ДД" example(1979-12-31)
+// @Param year query int false "Год выпуска" example(1975)
+// @Param decade query int false "Десятилетие выпуска — первый год, кратный 10" example(1970)
+// @Param genre query []string false "Фильтр по жанрам (несколько через запятую или повтором параметра)" collectionFormat(multi)
+// @Param genreMatch query string false "any — есть хотя бы один из жанров, all — есть все" Enums(any, all) default(any)
+// @Param tag query []string false "Фильтр по тегам (несколько через запятую или повтором параметра)" collectionFormat(multi)
+// @Param tagMatch query string false "any — есть хотя бы один из тегов, all — есть все" Enums(any, all) default(any)
+// @Param sort query string false "Сортировка: поля id, song, group, releaseDate, album, discNumber, trackNumber через запятую, минус перед полем — по убыванию. При равенстве песни упорядочены по id" example(-releaseDate,group,song)
+// @Param cursor query string false "Курсор страницы из next или prev предыдущего ответа; пустое значение — первая страница. Ответ — объект SongPageResponse"
+// @Param total query bool false "Добавить в ответ общее количество песен total. Ответ — объект SongPageResponse"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество записей на странице" default(10)
+// @Success 200 {object} models.SongPageResponse "Страница песен (с cursor или total)"
+// @Success 200 {array} models.SongResponse "Список песен (без cursor и total)"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID или параметры фильтра"
+// @Failure 404 {object} models.ErrorResponse "Группа не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/{id}/songs [get]
+func (s *Server) GetGroupSongs(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение песен группы")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	group, ok := s.findGroup(w, id)
+	if !ok {
+		return
+	}
+
+	filter, ok := parseSongFilter(w, r)
+	if !ok {
+		return
+	}
+	filter.GroupID = group.ID
+
+	s.writeSongList(w, r, filter)
+}
+
+// UpdateGroup переименовывает группу.
+// @Summary Переименовать группу
+// @Description Изменяет название группы. Название не может быть пустым или совпадать с названием или псевдонимом другой группы (без учёта регистра и лишних пробелов).
+// @Tags Группы
+// @Accept json
+// @Produce json
+// @Param id path int true "ID группы"
+// @Param group body models.UpdateGroupRequest true "Новое название группы"
+// @Success 200 {object} models.MessageResponse "Группа переименована"
+// @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса"
+// @Failure 404 {object} models.ErrorResponse "Группа не найдена"
+// @Failure 409 {object} models.ErrorResponse "Название уже используется другой группой или псевдонимом"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/{id} [patch]
+func (s *Server) UpdateGroup(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на переименование группы")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var updateData models.UpdateGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateData); err != nil || strings.TrimSpace(updateData.Name) == "" {
+		logrus.Errorf("Некорректные данные запроса: %v", err)
+		writeError(w, http.StatusBadRequest, "Некорректные данные запроса")
+		return
+	}
+
+	group, ok := s.findGroup(w, id)
+	if !ok {
+		return
+	}
+
+	group.Name = strings.TrimSpace(updateData.Name)
+	err := s.groups.Update(group)
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		logrus.Warnf("Группа с ID %d не найдена", id)
+		writeError(w, http.StatusNotFound, "Группа не найдена")
+		return
+	case errors.Is(err, db.ErrNameConflict):
+		logrus.Warnf("Название %s уже используется", group.Name)
+		writeError(w, http.StatusConflict, "Название уже используется другой группой или псевдонимом")
+		return
+	case err != nil:
+		logrus.Errorf("Ошибка при обновлении группы в базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Группа с ID %d переименована в %s", id, group.Name)
+	writeJSON(w, http.StatusOK, models.MessageResponse{Message: "Группа успешно переименована"})
+}
+
+// DeleteGroup удаляет группу.
+// @Summary Удалить группу
+// @Description Удаляет группу по ID вместе с её альбомами. По умолчанию (orphans=restrict) группу с песнями удалить нельзя, даже если они в корзине; при orphans=delete песни группы, включая корзину, удаляются окончательно вместе с ней.
+// @Tags Группы
+// @Produce json
+// @Param id path int true "ID группы"
+// @Param orphans query string false "Что делать с песнями группы: restrict или delete" Enums(restrict, delete) default(restrict)
+// @Success 200 {object} models.MessageResponse "Группа удалена"
+// @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса"
+// @Failure 404 {object} models.ErrorResponse "Группа не найдена"
+// @Failure 409 {object} models.ErrorResponse "У группы есть песни"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/{id} [delete]
+func (s *Server) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на удаление группы")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	orphans := r.URL.Query().Get("orphans")
+	if orphans == "" {
+		orphans = orphansRestrict
+	}
+	if orphans != orphansRestrict && orphans != orphansDelete {
+		logrus.Errorf("Некорректная политика удаления песен: %s", orphans)
+		writeError(w, http.StatusBadRequest, "Параметр orphans должен быть restrict или delete")
+		return
+	}
+
+	err := s.groups.Delete(id, orphans == orphansDelete)
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		logrus.Warnf("Группа с ID %d не найдена", id)
+		writeError(w, http.StatusNotFound, "Группа не найдена")
+		return
+	case errors.Is(err, db.ErrGroupHasSongs):
+		logrus.Warnf("У группы с ID %d есть песни", id)
+		writeError(w, http.StatusConflict, "У группы есть песни, используйте orphans=delete, чтобы удалить их вместе с группой")
+		return
+	case err != nil:
+		logrus.Errorf("Ошибка при удалении группы из базы данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Группа с ID %d успешно удалена", id)
+	writeJSON(w, http.StatusOK, models.MessageResponse{Message: "Группа успешно удалена"})
+}
+
+// findGroup возвращает группу по ID. Если группа не найдена или произошла ошибка,
+// отправляет соответствующий ответ и возвращает false.
+func (s *Server) findGroup(w http.ResponseWriter, id int) (*models.Group, bool) {
+	group, err := s.groups.GetByID(id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			logrus.Warnf("Группа с ID %d не найдена", id)
+			writeError(w, http.StatusNotFound, "Группа не найдена")
+			return nil, false
+		}
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return nil, false
+	}
+	return group, true
+}
+
+// groupDetails собирает ответ с псевдонимами и песнями группы.
+func (s *Server) groupDetails(group *models.Group) (models.GroupDetailsResponse, error) {
+	aliases, err := s.groups.ListAliases(group.ID)
+	if err != nil {
+		return models.GroupDetailsResponse{}, err
+	}
+	songs, err := s.songs.GetFiltered(db.SongFilter{GroupID: group.ID})
+	if err != nil {
+		return models.GroupDetailsResponse{}, err
+	}
+
+	details := models.GroupDetailsResponse{
+		ID:      group.ID,
+		Name:    group.Name,
+		Aliases: make([]models.GroupAliasResponse, 0, len(aliases)),
+		Songs:   newSongResponses(songs),
+	}
+	for _, alias := range aliases {
+		details.Aliases = append(details.Aliases, models.GroupAliasResponse{ID: alias.ID, Name: alias.Name})
+	}
+	return details, nil
+}
+
+// AddGroupAlias добавляет группе альтернативное написание названия.
+// @Summary Добавить псевдоним группы
+// @Description Добавляет альтернативное написание названия группы. При создании песни с таким названием группы песня будет привязана к этой группе.
+// @Tags Группы
+// @Accept json
+// @Produce json
+// @Param id path int true "ID группы"
+// @Param alias body models.CreateGroupAliasRequest true "Псевдоним"
+// @Success 201 {object} models.GroupAliasResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса"
+// @Failure 404 {object} models.ErrorResponse "Группа не найдена"
+// @Failure 409 {object} models.ErrorResponse "Название уже используется"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/{id}/aliases [post]
+func (s *Server) AddGroupAlias(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на добавление псевдонима группы")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var request models.CreateGroupAliasRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || strings.TrimSpace(request.Name) == "" {
+		logrus.Errorf("Некорректные данные запроса: %v", err)
+		writeError(w, http.StatusBadRequest, "Некорректные данные запроса")
+		return
+	}
+
+	alias, err := s.groups.AddAlias(id, request.Name)
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		logrus.Warnf("Группа с ID %d не найдена", id)
+		writeError(w, http.StatusNotFound, "Группа не найдена")
+		return
+	case errors.Is(err, db.ErrNameConflict):
+		logrus.Warnf("Название %s уже используется", request.Name)
+		writeError(w, http.StatusConflict, "Название уже используется другой группой или псевдонимом")
+		return
+	case err != nil:
+		logrus.Errorf("Ошибка при сохранении псевдонима в базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Группе с ID %d добавлен псевдоним %s", id, alias.Name)
+	writeJSON(w, http.StatusCreated, models.GroupAliasResponse{ID: alias.ID, Name: alias.Name})
+}
+
+// DeleteGroupAlias удаляет псевдоним группы.
+// @Summary Удалить псевдоним группы
+// @Tags Группы
+// @Produce json
+// @Param id path int true "ID группы"
+// @Param aliasId path int true "ID псевдонима"
+// @Success 200 {object} models.MessageResponse "Псевдоним удалён"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} models.ErrorResponse "Псевдоним не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/{id}/aliases/{aliasId} [delete]
+func (s *Server) DeleteGroupAlias(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на удаление псевдонима группы")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	aliasID, ok := parseID(w, r, "aliasId")
+	if !ok {
+		return
+	}
+
+	err := s.groups.DeleteAlias(id, aliasID)
+	if errors.Is(err, db.ErrNotFound) {
+		logrus.Warnf("Псевдоним с ID %d группы %d не найден", aliasID, id)
+		writeError(w, http.StatusNotFound, "Псевдоним не найден")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при удалении псевдонима из базы данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.MessageResponse{Message: "Псевдоним успешно удалён"})
+}
+
+// MergeGroups объединяет группы-дубликаты с целевой группой.
+// @Summary Объединить группы
+// @Description Атомарно переносит все песни, альбомы и псевдонимы групп sourceIds в целевую группу, сохраняет их названия как псевдонимы целевой группы и удаляет исходные группы.
+// @Tags Группы
+// @Accept json
+// @Produce json
+// @Param id path int true "ID целевой группы"
+// @Param merge body models.MergeGroupsRequest true "ID объединяемых групп"
+// @Param X-User header string false "Автор изменения для истории ревизий"
+// @Success 200 {object} models.GroupDetailsResponse "Целевая группа после объединения"
+// @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса"
+// @Failure 404 {object} models.ErrorResponse "Группа не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/{id}/merge [post]
+func (s *Server) MergeGroups(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на объединение групп")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var request models.MergeGroupsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || len(request.SourceIDs) == 0 {
+		logrus.Errorf("Некорректные данные запроса: %v", err)
+		writeError(w, http.StatusBadRequest, "Некорректные данные запроса: укажите sourceIds")
+		return
+	}
+
+	seen := make(map[int]bool, len(request.SourceIDs))
+	var sourceIDs []int
+	for _, sourceID := range request.SourceIDs {
+		if sourceID == id {
+			logrus.Error("Группа не может быть объединена сама с собой")
+			writeError(w, http.StatusBadRequest, "Целевая группа не может быть в списке sourceIds")
+			return
+		}
+		if !seen[sourceID] {
+			seen[sourceID] = true
+			sourceIDs = append(sourceIDs, sourceID)
+		}
+	}
+
+	err := s.groups.Merge(id, sourceIDs, revisionAuthor(r))
+	if errors.Is(err, db.ErrNotFound) {
+		logrus.Warnf("Одна из групп %d, %v не найдена", id, sourceIDs)
+		writeError(w, http.StatusNotFound, "Группа не найдена")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при объединении групп: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+	logrus.Infof("Группы %v объединены с группой %d", sourceIDs, id)
+
+	group, ok := s.findGroup(w, id)
+	if !ok {
+		return
+	}
+	details, err := s.groupDetails(group)
+	if err != nil {
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+	writeJSON(w, http.StatusOK, details)
+}
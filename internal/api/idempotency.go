@@ -0,0 +1,107 @@
+package api
+
+import (
+	"bytes"
+	"music_storage/internal/models"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// idempotencyKeyHeader — заголовок с ключом, по которому повтор запроса получает тот же ответ.
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayedHeader отмечает ответ, сохранённый при первом выполнении запроса.
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// idempotent выполняет handle один раз для каждого ключа из заголовка Idempotency-Key:
+// ответ сохраняется, и повтор запроса с тем же ключом получает его без повторного
+// выполнения. Пока запрос выполняется, ключ зарезервирован, и повтор получает 409. Ответы
+// с ошибкой сервера не сохраняются, чтобы запрос можно было повторить. Без заголовка handle
+// выполняется как обычно.
+func (s *Server) idempotent(w http.ResponseWriter, r *http.Request, handle http.HandlerFunc) {
+	key := strings.TrimSpace(r.Header.Get(idempotencyKeyHeader))
+	if key == "" {
+		handle(w, r)
+		return
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		logrus.Errorf("Слишком длинный ключ идемпотентности: %d символов", len(key))
+		writeError(w, http.StatusBadRequest, "Ключ идемпотентности длиннее 255 символов")
+		return
+	}
+
+	request := r.Method + " " + r.URL.Path
+	saved, reserved, err := s.idempotency.Reserve(key, request)
+	if err != nil {
+		logrus.Errorf("Ошибка при резервировании ключа идемпотентности: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+	if !reserved {
+		if saved.Request != request {
+			logrus.Warnf("Ключ идемпотентности %q уже использован для запроса %s", key, saved.Request)
+			writeError(w, http.StatusUnprocessableEntity, "Ключ идемпотентности уже использован для другого запроса")
+			return
+		}
+		if saved.Pending() {
+			logrus.Warnf("Запрос %s с ключом идемпотентности %q ещё выполняется", request, key)
+			writeError(w, http.StatusConflict, "Запрос с этим ключом идемпотентности ещё выполняется, повторите его позже")
+			return
+		}
+		logrus.Infof("Повтор запроса %s с ключом идемпотентности %q, отправка сохранённого ответа", request, key)
+		if saved.ContentType != "" {
+			w.Header().Set("Content-Type", saved.ContentType)
+		}
+		w.Header().Set(idempotentReplayedHeader, "true")
+		w.WriteHeader(saved.Status)
+		w.Write([]byte(saved.Body))
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+	handle(recorder, r)
+	if recorder.status >= http.StatusInternalServerError {
+		s.releaseIdempotencyKey(key)
+		return
+	}
+	err = s.idempotency.Save(&models.IdempotentResponse{
+		Key:         key,
+		Request:     request,
+		Status:      recorder.status,
+		ContentType: w.Header().Get("Content-Type"),
+		Body:        recorder.body.String(),
+	})
+	if err != nil {
+		logrus.Errorf("Ошибка при сохранении ответа по ключу идемпотентности: %v", err)
+		s.releaseIdempotencyKey(key)
+	}
+}
+
+// releaseIdempotencyKey снимает резервацию ключа, ответ на который не сохранён. Если снять
+// её не удалось, ключ освободится по истечении срока резервации.
+func (s *Server) releaseIdempotencyKey(key string) {
+	if err := s.idempotency.Release(key); err != nil {
+		logrus.Errorf("Ошибка при снятии резервации ключа идемпотентности: %v", err)
+	}
+}
+
+// responseRecorder передаёт ответ клиенту и запоминает его статус и тело.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(data []byte) (int, error) {
+	rec.body.Write(data)
+	return rec.ResponseWriter.Write(data)
+}
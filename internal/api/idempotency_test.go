@@ -0,0 +1,83 @@
+package api
+
+import (
+	"music_storage/internal/db"
+	"music_storage/internal/idempotency"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newIdempotencyServer() *Server {
+	storage := db.NewMemoryStorage()
+	return NewServer(storage, nil, idempotency.New(storage.IdempotencyKeys, idempotency.DefaultConfig()))
+}
+
+// serveIdempotent выполняет запрос DELETE /songs/1 с ключом key через Server.idempotent.
+func serveIdempotent(s *Server, key string, handle http.HandlerFunc) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodDelete, "/songs/1", nil)
+	r.Header.Set(idempotencyKeyHeader, key)
+	w := httptest.NewRecorder()
+	s.idempotent(w, r, handle)
+	return w
+}
+
+func TestIdempotentRejectsRetryWhileInFlight(t *testing.T) {
+	s := newIdempotencyServer()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	calls := 0
+	handle := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			close(started)
+			<-release
+		}
+		writeJSON(w, http.StatusOK, map[string]int{"call": calls})
+	}
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- serveIdempotent(s, "k", handle) }()
+	<-started
+
+	if w := serveIdempotent(s, "k", handle); w.Code != http.StatusConflict {
+		t.Fatalf("повтор во время выполнения: статус %d, ожидался 409", w.Code)
+	}
+	close(release)
+	original := <-first
+	if original.Code != http.StatusOK {
+		t.Fatalf("первый запрос: статус %d", original.Code)
+	}
+
+	replay := serveIdempotent(s, "k", handle)
+	if replay.Code != http.StatusOK || replay.Body.String() != original.Body.String() || replay.Header().Get(idempotentReplayedHeader) != "true" {
+		t.Fatalf("повтор после ответа: статус %d, тело %q", replay.Code, replay.Body.String())
+	}
+	if calls != 1 {
+		t.Fatalf("обработчик выполнен %d раз", calls)
+	}
+}
+
+func TestIdempotentReleasesKeyAfterServerError(t *testing.T) {
+	s := newIdempotencyServer()
+	status := http.StatusInternalServerError
+	calls := 0
+	handle := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		writeError(w, status, "ошибка")
+	}
+
+	if w := serveIdempotent(s, "k", handle); w.Code != http.StatusInternalServerError {
+		t.Fatalf("статус %d", w.Code)
+	}
+	status = http.StatusNotFound
+	if w := serveIdempotent(s, "k", handle); w.Code != http.StatusNotFound {
+		t.Fatalf("повтор после ошибки сервера: статус %d, ожидался 404", w.Code)
+	}
+	if w := serveIdempotent(s, "k", handle); w.Code != http.StatusNotFound || w.Header().Get(idempotentReplayedHeader) != "true" {
+		t.Fatalf("повтор сохранённого ответа: статус %d", w.Code)
+	}
+	if calls != 2 {
+		t.Fatalf("обработчик выполнен %d раз, ожидалось 2", calls)
+	}
+}
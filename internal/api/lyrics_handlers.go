@@ -0,0 +1,442 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"music_storage/internal/db"
+	"music_storage/internal/lyrics"
+	"music_storage/internal/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// GetSongLyrics возвращает текст песни, разбитый на разделы.
+// @Summary Получить разделы текста песни
+// @Description Возвращает текст песни по разделам: куплеты, припевы, бридж, вступление и концовка. Повтор раздела ссылается на позицию повторяемого раздела в repeatOf и содержит его текст. С параметром section возвращаются только разделы этого типа без повторов.
+// @Tags Песни
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param section query string false "Тип раздела" Enums(verse, chorus, bridge, intro, outro)
+// @Success 200 {object} models.LyricsResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/lyrics [get]
+func (s *Server) GetSongLyrics(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение разделов текста песни")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	section, ok := parseSection(w, r)
+	if !ok {
+		return
+	}
+
+	sections, ok := s.findLyrics(w, id)
+	if !ok {
+		return
+	}
+
+	logrus.Infof("Отправка разделов текста песни с ID %d", id)
+	writeJSON(w, http.StatusOK, newLyricsResponse(id, sections, section))
+}
+
+// SetSongLyrics заменяет разделы текста песни.
+// @Summary Задать разделы текста песни
+// @Description Заменяет разделы текста песни. Текст песни собирается из разделов по порядку, разделы разделяются пустой строкой. Повтор раздела задаётся полем repeatOf — позицией более раннего раздела того же типа — вместо текста.
+// @Tags Песни
+// @Accept json
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param lyrics body models.SetLyricsRequest true "Разделы текста"
+// @Param X-User header string false "Автор изменения для истории ревизий"
+// @Success 200 {object} models.LyricsResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/lyrics [put]
+func (s *Server) SetSongLyrics(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на изменение разделов текста песни")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var request models.SetLyricsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logrus.Errorf("Ошибка при декодировании запроса: %v", err)
+		writeError(w, http.StatusBadRequest, "Некорректные данные запроса")
+		return
+	}
+	sections, message := newLyricsSections(request)
+	if message != "" {
+		logrus.Errorf("Некорректные разделы текста: %s", message)
+		writeError(w, http.StatusBadRequest, message)
+		return
+	}
+
+	err := s.songs.SetLyrics(id, sections, revisionAuthor(r))
+	if errors.Is(err, db.ErrNotFound) {
+		logrus.Warnf("Песня с ID %d не найдена", id)
+		writeError(w, http.StatusNotFound, "Песня не найдена")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при сохранении разделов текста песни: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+	logrus.Infof("Разделы текста песни с ID %d обновлены", id)
+
+	if sections, ok = s.findLyrics(w, id); !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, newLyricsResponse(id, sections, ""))
+}
+
+// parseSection читает тип раздела текста из параметра section.
+// При ошибке отправляет ответ 400 и возвращает false.
+func parseSection(w http.ResponseWriter, r *http.Request) (string, bool) {
+	section := r.URL.Query().Get("section")
+	if section != "" && !lyrics.IsType(section) {
+		logrus.Errorf("Некорректный тип раздела: %s", section)
+		writeError(w, http.StatusBadRequest, "Параметр section должен быть одним из: "+strings.Join(lyrics.Types, ", "))
+		return "", false
+	}
+	return section, true
+}
+
+// findLyrics загружает разделы текста песни. Если песни нет или произошла ошибка,
+// отправляет ответ 404 или 500 и возвращает false.
+func (s *Server) findLyrics(w http.ResponseWriter, id int) ([]models.LyricsSection, bool) {
+	sections, err := s.songs.GetLyrics(id)
+	if errors.Is(err, db.ErrNotFound) {
+		logrus.Warnf("Песня с ID %d не найдена", id)
+		writeError(w, http.StatusNotFound, "Песня не найдена")
+		return nil, false
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при получении разделов текста песни: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return nil, false
+	}
+	return sections, true
+}
+
+// newLyricsSections проверяет разделы из запроса и преобразует их в разделы текста песни.
+// Возвращает сообщение об ошибке, если разделы некорректны.
+func newLyricsSections(request models.SetLyricsRequest) ([]models.LyricsSection, string) {
+	sections := make([]models.LyricsSection, 0, len(request.Sections))
+	for i, item := range request.Sections {
+		position := i + 1
+		prefix := "Раздел " + strconv.Itoa(position) + ": "
+		if !lyrics.IsType(item.Type) {
+			return nil, prefix + "тип должен быть одним из: " + strings.Join(lyrics.Types, ", ")
+		}
+		text := strings.TrimSpace(strings.ReplaceAll(item.Text, "\r\n", "\n"))
+
+		if item.RepeatOf != nil {
+			if text != "" {
+				return nil, prefix + "у повтора не указывается текст"
+			}
+			original := *item.RepeatOf
+			if original < 1 || original >= position {
+				return nil, prefix + "repeatOf должен ссылаться на один из предыдущих разделов"
+			}
+			if sections[original-1].RepeatOf != nil {
+				return nil, prefix + "repeatOf должен ссылаться на раздел с текстом, а не на повтор"
+			}
+			if sections[original-1].Type != item.Type {
+				return nil, prefix + "повтор должен быть того же типа, что и повторяемый раздел"
+			}
+			sections = append(sections, models.LyricsSection{Position: position, Type: item.Type, RepeatOf: &original})
+			continue
+		}
+
+		if text == "" {
+			return nil, prefix + "не указан текст"
+		}
+		if len(lyrics.Split(text)) > 1 {
+			return nil, prefix + "текст не должен содержать пустых строк"
+		}
+		sections = append(sections, models.LyricsSection{Position: position, Type: item.Type, Text: text})
+	}
+	return sections, ""
+}
+
+// newLyricsResponse преобразует разделы текста песни в формат ответа API. Если задан
+// section, в ответ попадают только разделы этого типа без повторов.
+func newLyricsResponse(songID int, sections []models.LyricsSection, section string) models.LyricsResponse {
+	response := models.LyricsResponse{SongID: songID, Sections: []models.LyricsSectionResponse{}}
+	counts := make(map[string]int)
+	numbers := make(map[int]int)
+	for _, item := range sections {
+		var number int
+		if item.RepeatOf != nil {
+			number = numbers[*item.RepeatOf]
+		} else {
+			counts[item.Type]++
+			number = counts[item.Type]
+			numbers[item.Position] = number
+		}
+		if section != "" && (item.Type != section || item.RepeatOf != nil) {
+			continue
+		}
+		response.Sections = append(response.Sections, models.LyricsSectionResponse{
+			Position: item.Position,
+			Type:     item.Type,
+			Number:   number,
+			Text:     lyrics.Text(sections, item),
+			RepeatOf: item.RepeatOf,
+		})
+	}
+	return response
+}
+
+// maxLRCSize — максимальный размер загружаемого файла LRC.
+const maxLRCSize = 1 << 20
+
+// GetSongLRC возвращает синхронизированный текст песни.
+// @Summary Получить синхронизированный текст песни
+// @Description Возвращает синхронизированный текст песни в формате LRC (со словами — в расширенном формате) с тегами ti, ar, al и length из данных песни, а с format=json — строки с временем начала.
+// @Tags Песни
+// @Produce plain
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param format query string false "Формат ответа" Enums(lrc, json) default(lrc)
+// @Success 200 {object} models.SyncedLyricsResponse "Строки синхронизированного текста (format=json)"
+// @Success 200 {string} string "Текст в формате LRC"
+// @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
+// @Failure 404 {object} models.ErrorResponse "Песня или синхронизированный текст не найдены"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/lrc [get]
+func (s *Server) GetSongLRC(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение синхронизированного текста песни")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "lrc" && format != "json" {
+		logrus.Errorf("Некорректный формат: %s", format)
+		writeError(w, http.StatusBadRequest, "Параметр format должен быть lrc или json")
+		return
+	}
+
+	song, ok := s.findSong(w, id)
+	if !ok {
+		return
+	}
+	lines, ok := s.findSyncedLyrics(w, id)
+	if !ok {
+		return
+	}
+
+	if format == "json" {
+		logrus.Infof("Отправка синхронизированного текста песни с ID %d", id)
+		writeJSON(w, http.StatusOK, newSyncedLyricsResponse(*song, lines))
+		return
+	}
+
+	tags := map[string]string{"ti": song.Song, "ar": song.Group.Name}
+	if song.Album != nil {
+		tags["al"] = song.Album.Title
+	}
+	if song.Duration > 0 {
+		tags["length"] = lyrics.FormatTime(song.Duration * 1000)
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.WriteString(w, lyrics.FormatLRC(lines, tags)); err != nil {
+		logrus.Errorf("Ошибка при отправке ответа: %v", err)
+		return
+	}
+	logrus.Infof("Отправка синхронизированного текста песни с ID %d", id)
+}
+
+// SetSongLRC загружает синхронизированный текст песни.
+// @Summary Загрузить синхронизированный текст песни
+// @Description Заменяет синхронизированный текст песни файлом в формате LRC, в том числе расширенном с метками слов <мм:сс.хх>. Строки с несколькими метками повторяются для каждой из них, тег offset сдвигает все метки. Метки не должны быть позже конца песни: её длительности duration, а если она неизвестна — тега length.
+// @Tags Песни
+// @Accept plain
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param lrc body string true "Текст в формате LRC"
+// @Param X-User header string false "Автор изменения для истории ревизий"
+// @Success 200 {object} models.SyncedLyricsResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректный LRC"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/lrc [put]
+func (s *Server) SetSongLRC(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на загрузку синхронизированного текста песни")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxLRCSize))
+	if err != nil {
+		logrus.Errorf("Ошибка при чтении запроса: %v", err)
+		writeError(w, http.StatusBadRequest, "Некорректные данные запроса")
+		return
+	}
+	song, ok := s.findSong(w, id)
+	if !ok {
+		return
+	}
+	lines, err := lyrics.ParseLRC(string(data), song.Duration)
+	if err != nil {
+		logrus.Errorf("Некорректный LRC: %v", err)
+		writeError(w, http.StatusBadRequest, "Некорректный LRC: "+err.Error())
+		return
+	}
+
+	if err := s.songs.SetSyncedLyrics(id, lines, revisionAuthor(r)); err != nil {
+		logrus.Errorf("Ошибка при сохранении синхронизированного текста песни: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+	logrus.Infof("Синхронизированный текст песни с ID %d обновлён: %d строк", id, len(lines))
+
+	if lines, ok = s.findSyncedLyrics(w, id); !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, newSyncedLyricsResponse(*song, lines))
+}
+
+// DeleteSongLRC удаляет синхронизированный текст песни.
+// @Summary Удалить синхронизированный текст песни
+// @Tags Песни
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param X-User header string false "Автор изменения для истории ревизий"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/lrc [delete]
+func (s *Server) DeleteSongLRC(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на удаление синхронизированного текста песни")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	err := s.songs.SetSyncedLyrics(id, nil, revisionAuthor(r))
+	if errors.Is(err, db.ErrNotFound) {
+		logrus.Warnf("Песня с ID %d не найдена", id)
+		writeError(w, http.StatusNotFound, "Песня не найдена")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при удалении синхронизированного текста песни: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Синхронизированный текст песни с ID %d удалён", id)
+	writeJSON(w, http.StatusOK, models.MessageResponse{Message: "Синхронизированный текст удалён"})
+}
+
+// findSyncedLyrics загружает синхронизированный текст песни. Если его нет или произошла
+// ошибка, отправляет ответ 404 или 500 и возвращает false.
+func (s *Server) findSyncedLyrics(w http.ResponseWriter, id int) ([]models.LyricsLine, bool) {
+	lines, err := s.songs.GetSyncedLyrics(id)
+	if errors.Is(err, db.ErrNotFound) {
+		logrus.Warnf("Песня с ID %d не найдена", id)
+		writeError(w, http.StatusNotFound, "Песня не найдена")
+		return nil, false
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при получении синхронизированного текста песни: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return nil, false
+	}
+	if len(lines) == 0 {
+		logrus.Warnf("Синхронизированный текст песни с ID %d не найден", id)
+		writeError(w, http.StatusNotFound, "Синхронизированный текст песни не найден")
+		return nil, false
+	}
+	return lines, true
+}
+
+// writeLineAt отправляет строку синхронизированного текста, которая звучит в момент at
+// (параметр запроса в формате мм:сс), и слово, если слова размечены. До первой строки
+// текст пустой.
+func (s *Server) writeLineAt(w http.ResponseWriter, song *models.Song, at string) {
+	ms, ok := lyrics.ParseTime(at)
+	if !ok {
+		logrus.Errorf("Некорректное время: %s", at)
+		writeError(w, http.StatusBadRequest, "Параметр at должен быть временем в формате мм:сс или мм:сс.хх")
+		return
+	}
+	if song.Duration > 0 && ms > song.Duration*1000 {
+		logrus.Errorf("Время %s позже конца песни", at)
+		writeError(w, http.StatusBadRequest, "Параметр at позже конца песни")
+		return
+	}
+	lines, ok := s.findSyncedLyrics(w, song.ID)
+	if !ok {
+		return
+	}
+
+	response := map[string]string{"text": ""}
+	if line, ok := lyrics.LineAt(lines, ms); ok {
+		response["text"] = line.Text
+		response["start"] = lyrics.FormatTime(line.StartMs)
+		if word := lyrics.WordAt(line, ms); word != "" {
+			response["word"] = word
+		}
+	}
+	logrus.Infof("Отправка строки текста в момент %s", at)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// validDuration проверяет новую длительность песни в секундах: она не может быть
+// отрицательной и меньше последней метки синхронизированного текста. При ошибке
+// отправляет ответ 400 или 500 и возвращает false.
+func (s *Server) validDuration(w http.ResponseWriter, id, duration int) bool {
+	if duration < 0 {
+		logrus.Errorf("Некорректная длительность: %d", duration)
+		writeError(w, http.StatusBadRequest, "Длительность песни не может быть отрицательной")
+		return false
+	}
+	if duration == 0 {
+		return true
+	}
+	lines, err := s.songs.GetSyncedLyrics(id)
+	if err != nil {
+		logrus.Errorf("Ошибка при получении синхронизированного текста песни: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return false
+	}
+	if end := lyrics.End(lines); end > duration*1000 {
+		logrus.Errorf("Длительность %d с меньше последней метки %s", duration, lyrics.FormatTime(end))
+		writeError(w, http.StatusBadRequest, "Длительность песни меньше последней метки синхронизированного текста "+lyrics.FormatTime(end))
+		return false
+	}
+	return true
+}
+
+// newSyncedLyricsResponse преобразует синхронизированный текст песни в формат ответа API.
+func newSyncedLyricsResponse(song models.Song, lines []models.LyricsLine) models.SyncedLyricsResponse {
+	response := models.SyncedLyricsResponse{SongID: song.ID, Duration: song.Duration, Lines: make([]models.SyncedLineResponse, 0, len(lines))}
+	for _, line := range lines {
+		item := models.SyncedLineResponse{Time: lyrics.FormatTime(line.StartMs), StartMs: line.StartMs, Text: line.Text}
+		for _, word := range line.Words {
+			item.Words = append(item.Words, models.SyncedWordResponse{
+				Time:    lyrics.FormatTime(word.StartMs),
+				StartMs: word.StartMs,
+				Text:    strings.TrimSpace(word.Text),
+			})
+		}
+		response.Lines = append(response.Lines, item)
+	}
+	return response
+}
@@ -0,0 +1,411 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ListPlaylists возвращает список плейлистов.
+// @Summary Получить список плейлистов
+// @Description Возвращает плейлисты с количеством песен и пагинацией.
+// @Tags Плейлисты
+// @Produce json
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество записей на странице" default(10)
+// @Success 200 {array} models.PlaylistResponse
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /playlists [get]
+func (s *Server) ListPlaylists(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение списка плейлистов")
+	limit, offset := parsePagination(r)
+
+	playlists, err := s.playlists.List(limit, offset)
+	if err != nil {
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	responses := make([]models.PlaylistResponse, 0, len(playlists))
+	for _, playlist := range playlists {
+		responses = append(responses, models.PlaylistResponse{
+			ID:          playlist.ID,
+			Name:        playlist.Name,
+			Description: playlist.Description,
+			SongCount:   playlist.SongCount,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, responses)
+	logrus.Info("Ответ успешно отправлен")
+}
+
+// CreatePlaylist создаёт пустой плейлист.
+// @Summary Создать плейлист
+// @Tags Плейлисты
+// @Accept json
+// @Produce json
+// @Param playlist body models.CreatePlaylistRequest true "Название и описание плейлиста"
+// @Success 201 {object} models.PlaylistDetailsResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /playlists [post]
+func (s *Server) CreatePlaylist(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на создание плейлиста")
+	var request models.CreatePlaylistRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || strings.TrimSpace(request.Name) == "" {
+		logrus.Errorf("Некорректные данные запроса: %v", err)
+		writeError(w, http.StatusBadRequest, "Некорректные данные запроса: укажите name")
+		return
+	}
+
+	playlist := models.Playlist{Name: strings.TrimSpace(request.Name), Description: request.Description}
+	if err := s.playlists.Create(&playlist); err != nil {
+		logrus.Errorf("Ошибка при сохранении плейлиста в базу данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Плейлист %s сохранён с ID %d", playlist.Name, playlist.ID)
+	writeJSON(w, http.StatusCreated, newPlaylistDetailsResponse(playlist, nil))
+}
+
+// GetPlaylist возвращает плейлист вместе с песнями.
+// @Summary Получить плейлист
+// @Description Возвращает плейлист по ID со всеми песнями в порядке позиций.
+// @Tags Плейлисты
+// @Produce json
+// @Param id path int true "ID плейлиста"
+// @Success 200 {object} models.PlaylistDetailsResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} models.ErrorResponse "Плейлист не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /playlists/{id} [get]
+func (s *Server) GetPlaylist(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение плейлиста")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	s.writePlaylistDetails(w, http.StatusOK, id)
+}
+
+// GetPlaylistSongs возвращает песни плейлиста по порядку.
+// @Summary Получить песни плейлиста
+// @Description Возвращает песни плейлиста в порядке позиций с пагинацией. Позиция песни равна её порядковому номеру в полном списке.
+// @Tags Плейлисты
+// @Produce json
+// @Param id path int true "ID плейлиста"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество записей на странице" default(10)
+// @Success 200 {array} models.SongResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} models.ErrorResponse "Плейлист не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /playlists/{id}/songs [get]
+func (s *Server) GetPlaylistSongs(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение песен плейлиста")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if _, ok := s.findPlaylist(w, id); !ok {
+		return
+	}
+
+	limit, offset := parsePagination(r)
+	songs, err := s.playlists.ListSongs(id, limit, offset)
+	if err != nil {
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newSongResponses(songs))
+	logrus.Info("Ответ успешно отправлен")
+}
+
+// UpdatePlaylist переименовывает плейлист или меняет его описание.
+// @Summary Изменить плейлист
+// @Description Обновляет название и описание плейлиста. Поля, которые не переданы, остаются без изменений.
+// @Tags Плейлисты
+// @Accept json
+// @Produce json
+// @Param id path int true "ID плейлиста"
+// @Param playlist body models.UpdatePlaylistRequest true "Изменяемые поля"
+// @Success 200 {object} models.MessageResponse "Плейлист обновлён"
+// @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса"
+// @Failure 404 {object} models.ErrorResponse "Плейлист не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /playlists/{id} [patch]
+func (s *Server) UpdatePlaylist(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на изменение плейлиста")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var request models.UpdatePlaylistRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logrus.Errorf("Ошибка при декодировании запроса: %v", err)
+		writeError(w, http.StatusBadRequest, "Некорректные данные запроса")
+		return
+	}
+
+	playlist, ok := s.findPlaylist(w, id)
+	if !ok {
+		return
+	}
+	if request.Name != nil {
+		if strings.TrimSpace(*request.Name) == "" {
+			logrus.Error("Пустое название плейлиста")
+			writeError(w, http.StatusBadRequest, "Название плейлиста не может быть пустым")
+			return
+		}
+		playlist.Name = strings.TrimSpace(*request.Name)
+	}
+	if request.Description != nil {
+		playlist.Description = *request.Description
+	}
+
+	if err := s.playlists.Update(playlist); err != nil {
+		logrus.Errorf("Ошибка при обновлении плейлиста в базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Плейлист с ID %d успешно обновлён", id)
+	writeJSON(w, http.StatusOK, models.MessageResponse{Message: "Плейлист успешно обновлён"})
+}
+
+// DeletePlaylist удаляет плейлист.
+// @Summary Удалить плейлист
+// @Description Удаляет плейлист по ID. Песни из библиотеки не удаляются.
+// @Tags Плейлисты
+// @Produce json
+// @Param id path int true "ID плейлиста"
+// @Success 200 {object} models.MessageResponse "Плейлист удалён"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} models.ErrorResponse "Плейлист не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /playlists/{id} [delete]
+func (s *Server) DeletePlaylist(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на удаление плейлиста")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	err := s.playlists.Delete(id)
+	if errors.Is(err, db.ErrNotFound) {
+		logrus.Warnf("Плейлист с ID %d не найден", id)
+		writeError(w, http.StatusNotFound, "Плейлист не найден")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при удалении плейлиста из базы данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Плейлист с ID %d успешно удалён", id)
+	writeJSON(w, http.StatusOK, models.MessageResponse{Message: "Плейлист успешно удалён"})
+}
+
+// AddPlaylistSong добавляет песню в плейлист.
+// @Summary Добавить песню в плейлист
+// @Description Вставляет песню на указанную позицию, сдвигая последующие, или в конец плейлиста, если позиция не указана. Одна песня может быть в плейлисте несколько раз.
+// @Tags Плейлисты
+// @Accept json
+// @Produce json
+// @Param id path int true "ID плейлиста"
+// @Param song body models.AddPlaylistSongRequest true "ID песни и позиция"
+// @Success 201 {object} models.PlaylistDetailsResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса или песня не найдена"
+// @Failure 404 {object} models.ErrorResponse "Плейлист не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /playlists/{id}/songs [post]
+func (s *Server) AddPlaylistSong(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на добавление песни в плейлист")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var request models.AddPlaylistSongRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.SongID < 1 || request.Position < 0 {
+		logrus.Errorf("Некорректные данные запроса: %v", err)
+		writeError(w, http.StatusBadRequest, "Некорректные данные запроса: укажите songId и неотрицательную position")
+		return
+	}
+
+	if _, ok := s.findPlaylist(w, id); !ok {
+		return
+	}
+	if _, err := s.songs.GetByID(request.SongID); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			logrus.Warnf("Песня с ID %d не найдена", request.SongID)
+			writeError(w, http.StatusBadRequest, "Песня не найдена")
+			return
+		}
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	err := s.playlists.AddSong(id, request.SongID, request.Position)
+	if errors.Is(err, db.ErrNotFound) {
+		logrus.Warnf("Плейлист с ID %d или песня с ID %d не найдены", id, request.SongID)
+		writeError(w, http.StatusNotFound, "Плейлист или песня не найдены")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при добавлении песни в плейлист: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Песня с ID %d добавлена в плейлист с ID %d", request.SongID, id)
+	s.writePlaylistDetails(w, http.StatusCreated, id)
+}
+
+// MovePlaylistSong переносит песню плейлиста на другую позицию.
+// @Summary Переместить песню в плейлисте
+// @Description Переносит песню с позиции position на новую позицию; остальные песни сдвигаются. Позиция больше числа песен означает конец плейлиста.
+// @Tags Плейлисты
+// @Accept json
+// @Produce json
+// @Param id path int true "ID плейлиста"
+// @Param position path int true "Текущая позиция песни"
+// @Param move body models.MovePlaylistSongRequest true "Новая позиция"
+// @Success 200 {object} models.PlaylistDetailsResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса"
+// @Failure 404 {object} models.ErrorResponse "Плейлист или позиция не найдены"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /playlists/{id}/songs/{position} [patch]
+func (s *Server) MovePlaylistSong(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на перемещение песни в плейлисте")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	position, ok := parseID(w, r, "position")
+	if !ok {
+		return
+	}
+
+	var request models.MovePlaylistSongRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Position < 1 {
+		logrus.Errorf("Некорректные данные запроса: %v", err)
+		writeError(w, http.StatusBadRequest, "Некорректные данные запроса: position должна быть не меньше 1")
+		return
+	}
+
+	err := s.playlists.MoveSong(id, position, request.Position)
+	if errors.Is(err, db.ErrNotFound) {
+		logrus.Warnf("В плейлисте с ID %d нет позиции %d", id, position)
+		writeError(w, http.StatusNotFound, "Плейлист или позиция не найдены")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при перемещении песни в плейлисте: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Песня плейлиста с ID %d перемещена с позиции %d на позицию %d", id, position, request.Position)
+	s.writePlaylistDetails(w, http.StatusOK, id)
+}
+
+// RemovePlaylistSong убирает песню из плейлиста.
+// @Summary Убрать песню из плейлиста
+// @Description Убирает песню с позиции position; последующие песни сдвигаются. Сама песня не удаляется.
+// @Tags Плейлисты
+// @Produce json
+// @Param id path int true "ID плейлиста"
+// @Param position path int true "Позиция песни"
+// @Success 200 {object} models.PlaylistDetailsResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса"
+// @Failure 404 {object} models.ErrorResponse "Плейлист или позиция не найдены"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /playlists/{id}/songs/{position} [delete]
+func (s *Server) RemovePlaylistSong(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на удаление песни из плейлиста")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	position, ok := parseID(w, r, "position")
+	if !ok {
+		return
+	}
+
+	err := s.playlists.RemoveSong(id, position)
+	if errors.Is(err, db.ErrNotFound) {
+		logrus.Warnf("В плейлисте с ID %d нет позиции %d", id, position)
+		writeError(w, http.StatusNotFound, "Плейлист или позиция не найдены")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при удалении песни из плейлиста: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Из плейлиста с ID %d убрана песня на позиции %d", id, position)
+	s.writePlaylistDetails(w, http.StatusOK, id)
+}
+
+// findPlaylist возвращает плейлист по ID. Если плейлист не найден или произошла ошибка,
+// отправляет соответствующий ответ и возвращает false.
+func (s *Server) findPlaylist(w http.ResponseWriter, id int) (*models.Playlist, bool) {
+	playlist, err := s.playlists.GetByID(id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			logrus.Warnf("Плейлист с ID %d не найден", id)
+			writeError(w, http.StatusNotFound, "Плейлист не найден")
+			return nil, false
+		}
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return nil, false
+	}
+	return playlist, true
+}
+
+// writePlaylistDetails отправляет плейлист со всеми песнями.
+func (s *Server) writePlaylistDetails(w http.ResponseWriter, status, id int) {
+	playlist, ok := s.findPlaylist(w, id)
+	if !ok {
+		return
+	}
+	songs, err := s.playlists.ListSongs(id, 0, 0)
+	if err != nil {
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	writeJSON(w, status, newPlaylistDetailsResponse(*playlist, songs))
+	logrus.Info("Ответ успешно отправлен")
+}
+
+// newPlaylistDetailsResponse преобразует плейлист и его песни в формат ответа API.
+func newPlaylistDetailsResponse(playlist models.Playlist, songs []models.Song) models.PlaylistDetailsResponse {
+	return models.PlaylistDetailsResponse{
+		ID:          playlist.ID,
+		Name:        playlist.Name,
+		Description: playlist.Description,
+		CreatedAt:   playlist.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:   playlist.UpdatedAt.UTC().Format(time.RFC3339),
+		Songs:       newSongResponses(songs),
+	}
+}
@@ -0,0 +1,288 @@
+package api
+
+import (
+	"errors"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// ListSongRevisions возвращает историю изменений песни.
+// @Summary Получить ревизии песни
+// @Description Возвращает ревизии песни от новых к старым с пагинацией: номер, автор (заголовок X-User запроса, «api» без него или «enrichment» для обогащения), время и изменённые поля со старыми и новыми значениями. Ревизия записывается при добавлении песни и при каждом изменении её полей song, groupId, albumId, discNumber, trackNumber, releaseDate, duration, text, link и language, жанров (genreIds), тегов (tagIds), синхронизированного текста в формате LRC (syncedLyrics) и переводов (translations — объект JSON с текстами по кодам языков), в том числе при объединении групп. У песни, добавленной до появления ревизий, первая ревизия хранит состояние до первого изменения и не содержит изменений.
+// @Tags Песни
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество записей на странице" default(10)
+// @Success 200 {array} models.SongRevisionResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/revisions [get]
+func (s *Server) ListSongRevisions(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение ревизий песни")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	revisions, ok := s.findRevisions(w, id)
+	if !ok {
+		return
+	}
+
+	limit, offset := parsePagination(r)
+	responses := make([]models.SongRevisionResponse, 0, limit)
+	for i := len(revisions) - 1 - offset; i >= 0 && len(responses) < limit; i-- {
+		responses = append(responses, newRevisionResponse(revisions[i], false))
+	}
+	logrus.Infof("Отправка %d ревизий песни с ID %d", len(responses), id)
+	writeJSON(w, http.StatusOK, responses)
+}
+
+// GetSongRevision возвращает ревизию песни вместе со значениями всех её полей.
+// @Summary Получить ревизию песни
+// @Description Возвращает ревизию песни с изменёнными полями и значениями всех отслеживаемых полей песни в fields.
+// @Tags Песни
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param rev path int true "Номер ревизии"
+// @Success 200 {object} models.SongRevisionResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
+// @Failure 404 {object} models.ErrorResponse "Песня или ревизия не найдены"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/revisions/{rev} [get]
+func (s *Server) GetSongRevision(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение ревизии песни")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	revision, ok := s.findRevision(w, r, id)
+	if !ok {
+		return
+	}
+	logrus.Infof("Отправка ревизии %d песни с ID %d", revision.Number, id)
+	writeJSON(w, http.StatusOK, newRevisionResponse(*revision, true))
+}
+
+// DiffSongRevisions сравнивает поля песни в двух ревизиях.
+// @Summary Сравнить ревизии песни
+// @Description Возвращает поля, значения которых различаются в ревизиях from и to, со значениями в from (old) и to (new). По умолчанию to — последняя ревизия, from — предыдущая перед to; from=0 — состояние до добавления песни.
+// @Tags Песни
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param from query int false "Номер ревизии, с которой сравнивать"
+// @Param to query int false "Номер ревизии, которую сравнивать"
+// @Success 200 {object} models.SongRevisionDiffResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
+// @Failure 404 {object} models.ErrorResponse "Песня или ревизия не найдены"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/revisions/diff [get]
+func (s *Server) DiffSongRevisions(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на сравнение ревизий песни")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	revisions, ok := s.findRevisions(w, id)
+	if !ok {
+		return
+	}
+
+	to, ok := parseRevisionNumber(w, r, "to", len(revisions))
+	if !ok {
+		return
+	}
+	from, ok := parseRevisionNumber(w, r, "from", max(to-1, 0))
+	if !ok {
+		return
+	}
+	if from > len(revisions) || to < 1 || to > len(revisions) {
+		logrus.Warnf("Ревизия %d или %d песни с ID %d не найдена", from, to, id)
+		writeError(w, http.StatusNotFound, "Ревизия не найдена")
+		return
+	}
+
+	fromFields := db.SongFields(models.Song{})
+	if from > 0 {
+		fromFields = revisions[from-1].Fields
+	}
+	response := models.SongRevisionDiffResponse{
+		SongID:  id,
+		From:    from,
+		To:      to,
+		Changes: newFieldChangeResponses(db.DiffFields(fromFields, revisions[to-1].Fields)),
+	}
+	logrus.Infof("Отправка различий ревизий %d и %d песни с ID %d", from, to, id)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// RestoreSongRevision возвращает песню к состоянию из ревизии.
+// @Summary Восстановить ревизию песни
+// @Description Возвращает полям песни значения из ревизии rev, проверяя их так же, как PATCH /songs/{id}, и записывает это изменение новой ревизией. Жанры, теги, переводы и синхронизированный текст не меняются. Ответ — последняя ревизия песни.
+// @Tags Песни
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param rev path int true "Номер ревизии"
+// @Param X-User header string false "Автор изменения"
+// @Success 200 {object} models.SongRevisionResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса или значения ревизии"
+// @Failure 404 {object} models.ErrorResponse "Песня или ревизия не найдены"
+// @Failure 409 {object} models.ErrorResponse "Группа из ревизии удалена или язык занят переводом"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/revisions/{rev}/restore [post]
+func (s *Server) RestoreSongRevision(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на восстановление ревизии песни")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	song, ok := s.findSong(w, id)
+	if !ok {
+		return
+	}
+	revision, ok := s.findRevision(w, r, id)
+	if !ok {
+		return
+	}
+
+	fields := revision.Fields
+	groupID, _ := strconv.Atoi(fields["groupId"])
+	group, err := s.groups.GetByID(groupID)
+	if errors.Is(err, db.ErrNotFound) {
+		logrus.Warnf("Группа с ID %d из ревизии %d удалена", groupID, revision.Number)
+		writeError(w, http.StatusConflict, "Группа из ревизии удалена")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+	song.GroupID = group.ID
+	song.Group = *group
+
+	albumID, _ := strconv.Atoi(fields["albumId"])
+	discNumber, _ := strconv.Atoi(fields["discNumber"])
+	trackNumber, _ := strconv.Atoi(fields["trackNumber"])
+	duration, _ := strconv.Atoi(fields["duration"])
+	request := models.UpdateSongRequest{
+		Song:        stringField(fields, "song"),
+		ReleaseDate: stringField(fields, "releaseDate"),
+		Text:        stringField(fields, "text"),
+		Link:        stringField(fields, "link"),
+		AlbumID:     &albumID,
+		DiscNumber:  &discNumber,
+		TrackNumber: &trackNumber,
+		Duration:    &duration,
+		Language:    stringField(fields, "language"),
+	}
+	if !s.applySongUpdate(w, song, request) {
+		return
+	}
+	if err := s.songs.Update(song, revisionAuthor(r)); err != nil {
+		logrus.Errorf("Ошибка при обновлении песни в базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	revisions, ok := s.findRevisions(w, id)
+	if !ok {
+		return
+	}
+	logrus.Infof("Песня с ID %d восстановлена из ревизии %d", id, revision.Number)
+	writeJSON(w, http.StatusOK, newRevisionResponse(revisions[len(revisions)-1], false))
+}
+
+// findRevisions загружает ревизии песни. Если песни нет или произошла ошибка, отправляет
+// ответ 404 или 500 и возвращает false.
+func (s *Server) findRevisions(w http.ResponseWriter, id int) ([]models.SongRevision, bool) {
+	revisions, err := s.songs.ListRevisions(id)
+	if errors.Is(err, db.ErrNotFound) {
+		logrus.Warnf("Песня с ID %d не найдена", id)
+		writeError(w, http.StatusNotFound, "Песня не найдена")
+		return nil, false
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при получении ревизий песни: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return nil, false
+	}
+	return revisions, true
+}
+
+// findRevision загружает ревизию песни по номеру из параметра пути rev. При ошибке
+// отправляет ответ 400, 404 или 500 и возвращает false.
+func (s *Server) findRevision(w http.ResponseWriter, r *http.Request, id int) (*models.SongRevision, bool) {
+	value := mux.Vars(r)["rev"]
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		logrus.Errorf("Некорректный номер ревизии: %s", value)
+		writeError(w, http.StatusBadRequest, "Некорректный номер ревизии")
+		return nil, false
+	}
+	revision, err := s.songs.GetRevision(id, number)
+	if errors.Is(err, db.ErrNotFound) {
+		logrus.Warnf("Ревизия %d песни с ID %d не найдена", number, id)
+		writeError(w, http.StatusNotFound, "Ревизия не найдена")
+		return nil, false
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при получении ревизии песни: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return nil, false
+	}
+	return revision, true
+}
+
+// parseRevisionNumber читает неотрицательный номер ревизии из параметра запроса name;
+// без параметра возвращает fallback. При ошибке отправляет ответ 400 и возвращает false.
+func parseRevisionNumber(w http.ResponseWriter, r *http.Request, name string, fallback int) (int, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, true
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		logrus.Errorf("Некорректный номер ревизии %s: %s", name, value)
+		writeError(w, http.StatusBadRequest, "Параметр "+name+" должен быть номером ревизии")
+		return 0, false
+	}
+	return number, true
+}
+
+// stringField возвращает указатель на значение поля ревизии.
+func stringField(fields map[string]string, name string) *string {
+	value := fields[name]
+	return &value
+}
+
+// newRevisionResponse преобразует ревизию песни в формат ответа API; withFields добавляет
+// значения всех полей песни.
+func newRevisionResponse(revision models.SongRevision, withFields bool) models.SongRevisionResponse {
+	response := models.SongRevisionResponse{
+		Number:    revision.Number,
+		Author:    revision.Author,
+		CreatedAt: revision.CreatedAt.UTC().Format(time.RFC3339),
+		Changes:   newFieldChangeResponses(revision.Changes),
+	}
+	if withFields {
+		response.Fields = revision.Fields
+	}
+	return response
+}
+
+// newFieldChangeResponses преобразует изменения полей песни в формат ответа API.
+func newFieldChangeResponses(changes []models.FieldChange) []models.FieldChangeResponse {
+	responses := make([]models.FieldChangeResponse, 0, len(changes))
+	for _, change := range changes {
+		responses = append(responses, models.FieldChangeResponse{Field: change.Field, Old: change.Old, New: change.New})
+	}
+	return responses
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// EnrichmentQueue принимает песни на фоновое обогащение данными из внешнего API.
+type EnrichmentQueue interface {
+	Enqueue(id int)
+	// ProcessNow сразу выполняет одну попытку обогащения песни и сохраняет её результат;
+	// песня, которая уже обрабатывается в фоновом режиме, пропускается.
+	ProcessNow(ctx context.Context, id int) error
+}
+
+// IdempotencyStore хранит ответы на запросы с заголовком Idempotency-Key.
+type IdempotencyStore interface {
+	// Reserve резервирует ключ для запроса request и возвращает true. Если ключ уже занят,
+	// возвращает сохранённый ответ или резервацию (см. models.IdempotentResponse.Pending) и false.
+	Reserve(key, request string) (*models.IdempotentResponse, bool, error)
+	// Save записывает ответ на зарезервированный ключ.
+	Save(response *models.IdempotentResponse) error
+	// Release снимает резервацию ключа без ответа.
+	Release(key string) error
+}
+
+// Server содержит зависимости HTTP-обработчиков API.
+type Server struct {
+	songs       db.SongRepository
+	groups      db.GroupRepository
+	albums      db.AlbumRepository
+	genres      db.GenreRepository
+	tags        db.TagRepository
+	playlists   db.PlaylistRepository
+	enrichment  EnrichmentQueue
+	idempotency IdempotencyStore
+}
+
+// NewServer создаёт сервер API поверх переданного хранилища, очереди обогащения и
+// хранилища ответов на запросы с ключом идемпотентности.
+func NewServer(storage *db.Storage, enrichment EnrichmentQueue, idempotency IdempotencyStore) *Server {
+	return &Server{
+		songs:       storage.Songs,
+		groups:      storage.Groups,
+		albums:      storage.Albums,
+		genres:      storage.Genres,
+		tags:        storage.Tags,
+		playlists:   storage.Playlists,
+		enrichment:  enrichment,
+		idempotency: idempotency,
+	}
+}
+
+// authorHeader — заголовок с именем автора изменений, которое записывается в ревизии песен.
+const authorHeader = "X-User"
+
+// revisionAuthor возвращает автора изменений из заголовка X-User, без него — «api».
+func revisionAuthor(r *http.Request) string {
+	if author := strings.TrimSpace(r.Header.Get(authorHeader)); author != "" {
+		return author
+	}
+	return "api"
+}
+
+// parseID читает положительный целочисленный параметр пути name.
+// При ошибке отправляет ответ 400 и возвращает false.
+func parseID(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	value := mux.Vars(r)[name]
+	id, err := strconv.Atoi(value)
+	if err != nil || id < 1 {
+		logrus.Errorf("Некорректный ID: %s", value)
+		writeError(w, http.StatusBadRequest, "Некорректный ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// writeJSON отправляет ответ с указанным статусом в формате JSON.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logrus.Errorf("Ошибка при кодировании ответа: %v", err)
+	}
+}
+
+// writeError отправляет сообщение об ошибке в формате models.ErrorResponse.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, models.ErrorResponse{Message: message})
+}
+
+// parsePagination читает параметры page и limit (по умолчанию 1 и 10)
+// и возвращает limit и смещение для запроса к хранилищу.
+func parsePagination(r *http.Request) (int, int) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	logrus.Debugf("Параметры пагинации - page: %d, limit: %d", page, limit)
+	return limit, (page - 1) * limit
+}
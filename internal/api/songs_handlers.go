@@ -1,14 +1,18 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
-	"gorm.io/gorm"
 	"music_storage/internal/db"
+	"music_storage/internal/lyrics"
 	"music_storage/internal/models"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -22,274 +26,616 @@ import (
 
 // GetFilteredSongs возвращает список песен с фильтрацией по полям и пагинацией.
 // @Summary Получить список песен с фильтрацией
-// @Description Возвращает список песен с поддержкой фильтрации по полям и пагинации.
+// @Description Возвращает список песен с поддержкой фильтрации по полям, сортировки и пагинации. Без sort песни упорядочены по id, а при поиске — по релевантности. При поиске по q у каждой песни есть релевантность rank и snippet — подходящий под запрос куплет, совпадения в котором выделены тегами <b></b>.
 // @Tags Песни
 // @Produce  json
 // @Param song query string false "Фильтр по названию песни"
 // @Param id query int false "Фильтр по id"
 // @Param group query string false "Фильтр по названию группы"
-// @Param text query string false "Фильтр по фрагменту текста песни"
+// @Param text query string false "Фильтр по фрагменту текста песни (с учётом регистра)"
+// @Param q query string false "Полнотекстовый поиск по названию и тексту песни с учётом словоформ (русский и английский). Результаты упорядочены по релевантности и содержат rank и snippet"
+// @Param fuzzy query bool false "Сравнивать song и group по сходству (с опечатками), а не на равенство; результаты упорядочены по сходству score"
+// @Param similarity query number false "Минимальное сходство названий при fuzzy=true, от 0 до 1" default(0.3)
 // @Param link query string false "Фильтр по ссылке"
+// @Param album query string false "Фильтр по названию альбома"
+// @Param releaseDate query string false "Фильтр по точной дате выпуска в формате ГГГГ-ММ-ДД" example(1975-10-31)
+// @Param releasedFrom query string false "Выпущены не раньше даты (включительно), ГГГГ-ММ-ДД" example(1970-01-01)
+// @Param releasedTo query string false "Выпущены не позже даты (включительно), ГГГГ-ММ-ДД" example(1979-12-31)
+// @Param year query int false "Год выпуска" example(1975)
+// @Param decade query int false "Десятилетие выпуска — первый год, кратный 10" example(1970)
+// @Param genre query []string false "Фильтр по жанрам (несколько через запятую или повтором параметра)" collectionFormat(multi)
+// @Param genreMatch query string false "any — есть хотя бы один из жанров, all — есть все" Enums(any, all) default(any)
+// @Param tag query []string false "Фильтр по тегам (несколько через запятую или повтором параметра)" collectionFormat(multi)
+// @Param tagMatch query string false "any — есть хотя бы один из тегов, all — есть все" Enums(any, all) default(any)
+// @Param sort query string false "Сортировка: поля id, song, group, releaseDate, album, discNumber, trackNumber через запятую, минус перед полем — по убыванию. При равенстве песни упорядочены по id" example(-releaseDate,group,song)
+// @Param cursor query string false "Курсор страницы из next или prev предыдущего ответа; пустое значение — первая страница. Ответ — объект SongPageResponse"
+// @Param total query bool false "Добавить в ответ общее количество песен total. Ответ — объект SongPageResponse"
 // @Param page query int false "Номер страницы" default(1)
 // @Param limit query int false "Количество записей на странице" default(10)
-// @Success 200 {object} models.SongResponse "Список песен с фильтрацией и пагинацией"
-// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Success 200 {object} models.SongPageResponse "Страница песен (с cursor или total)"
+// @Success 200 {array} models.SongResponse "Список песен (без cursor и total)"
+// @Failure 400 {object} models.ErrorResponse "Некорректные параметры фильтра"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /songs [get]
-func GetFilteredSongs(w http.ResponseWriter, r *http.Request) {
+func (s *Server) GetFilteredSongs(w http.ResponseWriter, r *http.Request) {
 	logrus.Info("Начало обработки запроса на получение отфильтрованного списка песен")
+	filter, ok := parseSongFilter(w, r)
+	if !ok {
+		return
+	}
+
+	s.writeSongList(w, r, filter)
+}
+
+// writeSongList отправляет песни, подходящие под фильтр. При пагинации page/limit ответ —
+// массив песен, а если переданы cursor или total — страница models.SongPageResponse.
+func (s *Server) writeSongList(w http.ResponseWriter, r *http.Request, filter db.SongFilter) {
+	query := r.URL.Query()
+	if !query.Has("cursor") && !query.Has("total") {
+		results, err := s.findSongs(filter)
+		if err != nil {
+			logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+			writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+			return
+		}
+		logrus.Info("Запрос к базе данных успешно выполнен")
+
+		writeJSON(w, http.StatusOK, newSongResultResponses(results))
+		logrus.Info("Ответ успешно отправлен")
+		return
+	}
+
+	withTotal := false
+	if value := query.Get("total"); value != "" {
+		var err error
+		if withTotal, err = strconv.ParseBool(value); err != nil {
+			logrus.Errorf("Некорректное значение total: %s", value)
+			writeError(w, http.StatusBadRequest, "Параметр total должен быть true или false")
+			return
+		}
+	}
+
+	// Курсор хранит ключ сортировки, поэтому при сортировке по релевантности курсоров нет.
+	relevance := (filter.Query != "" || filter.Fuzzy) && len(filter.Sort) == 0
+	sortSpec := formatSort(filter.Sort)
+	if value := query.Get("cursor"); value != "" {
+		if query.Has("page") {
+			logrus.Error("Переданы одновременно page и cursor")
+			writeError(w, http.StatusBadRequest, "Параметры page и cursor нельзя использовать вместе")
+			return
+		}
+		if relevance {
+			logrus.Error("Курсор передан при сортировке по релевантности")
+			writeError(w, http.StatusBadRequest, "Курсоры недоступны при сортировке по релевантности: укажите sort")
+			return
+		}
+		cursor, err := decodeSongCursor(value)
+		if err != nil {
+			logrus.Errorf("Некорректный курсор: %v", err)
+			writeError(w, http.StatusBadRequest, "Некорректный курсор")
+			return
+		}
+		if cursor.Sort != sortSpec {
+			logrus.Errorf("Курсор получен для сортировки %q, запрошена %q", cursor.Sort, sortSpec)
+			writeError(w, http.StatusBadRequest, "Курсор получен для другой сортировки")
+			return
+		}
+		filter.Cursor = &db.SongCursor{Key: cursor.Key, Backward: cursor.Backward}
+		filter.Offset = 0
+	}
+
+	// Лишняя песня показывает, есть ли ещё страница в направлении чтения.
+	limit := filter.Limit
+	filter.Limit = limit + 1
+	results, err := s.findSongs(filter)
+	if err != nil {
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+	backward := filter.Cursor != nil && filter.Cursor.Backward
+	more := len(results) > limit
+	if more && backward {
+		results = results[len(results)-limit:]
+	} else if more {
+		results = results[:limit]
+	}
+
+	page := models.SongPageResponse{Items: newSongResultResponses(results)}
+	if !relevance && len(results) > 0 {
+		if more || backward {
+			page.Next = encodeSongCursor(songCursor{Sort: sortSpec, Key: db.NewSongSortKey(results[len(results)-1].Song, filter.Sort)})
+		}
+		if (more && backward) || (!backward && (filter.Cursor != nil || filter.Offset > 0)) {
+			page.Prev = encodeSongCursor(songCursor{Sort: sortSpec, Backward: true, Key: db.NewSongSortKey(results[0].Song, filter.Sort)})
+		}
+	}
+	if withTotal {
+		total, err := s.songs.Count(filter)
+		if err != nil {
+			logrus.Errorf("Ошибка при подсчёте песен: %v", err)
+			writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+			return
+		}
+		page.Total = &total
+	}
+	logrus.Info("Запрос к базе данных успешно выполнен")
+
+	writeJSON(w, http.StatusOK, page)
+	logrus.Info("Ответ успешно отправлен")
+}
+
+// songCursor — содержимое курсора страницы списка песен: сортировка, для которой он получен,
+// направление и ключ сортировки крайней песни соседней страницы.
+type songCursor struct {
+	Sort     string         `json:"s"`
+	Backward bool           `json:"b,omitempty"`
+	Key      db.SongSortKey `json:"k"`
+}
+
+// encodeSongCursor кодирует курсор в непрозрачную для клиента строку.
+func encodeSongCursor(cursor songCursor) string {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		logrus.Errorf("Ошибка при кодировании курсора: %v", err)
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeSongCursor разбирает курсор, полученный от encodeSongCursor.
+func decodeSongCursor(value string) (songCursor, error) {
+	var cursor songCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, err
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, err
+	}
+	if cursor.Key.ID < 1 {
+		return cursor, errors.New("в курсоре нет ID песни")
+	}
+	return cursor, nil
+}
+
+// formatSort записывает поля сортировки в формате параметра sort.
+func formatSort(fields []db.SortField) string {
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field.Desc {
+			parts = append(parts, "-"+field.Field)
+		} else {
+			parts = append(parts, field.Field)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// findSongs возвращает песни, подходящие под фильтр. При поисковом запросе или нечётком
+// сравнении названий у песен заполнены релевантность, фрагмент текста и сходство.
+func (s *Server) findSongs(filter db.SongFilter) ([]db.SongSearchResult, error) {
+	if filter.Query != "" || filter.Fuzzy {
+		return s.songs.Search(filter)
+	}
+
+	songs, err := s.songs.GetFiltered(filter)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]db.SongSearchResult, 0, len(songs))
+	for _, song := range songs {
+		results = append(results, db.SongSearchResult{Song: song})
+	}
+	return results, nil
+}
+
+// newSongResultResponses преобразует найденные песни в формат ответа API.
+func newSongResultResponses(results []db.SongSearchResult) []models.SongResponse {
+	responses := make([]models.SongResponse, 0, len(results))
+	for _, result := range results {
+		response := newSongResponse(result.Song)
+		response.Rank = result.Rank
+		response.Snippet = result.Snippet
+		response.Score = result.Score
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+// parseSongFilter читает параметры фильтрации и пагинации списка песен из строки запроса.
+// При ошибке отправляет ответ 400 и возвращает false.
+func parseSongFilter(w http.ResponseWriter, r *http.Request) (db.SongFilter, bool) {
 	id := r.URL.Query().Get("id")
 	group := r.URL.Query().Get("group")
 	song := r.URL.Query().Get("song")
 	releaseDate := r.URL.Query().Get("releaseDate")
 	text := r.URL.Query().Get("text")
 	link := r.URL.Query().Get("link")
+	album := r.URL.Query().Get("album")
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
 
-	logrus.Debugf("Параметры запроса - id: %s, group: %s, song: %s, releaseDate: %s, text: %s, link: %s", id, group, song, releaseDate, text, link)
+	logrus.Debugf("Параметры запроса - id: %s, group: %s, song: %s, releaseDate: %s, text: %s, link: %s, album: %s, q: %s", id, group, song, releaseDate, text, link, album, query)
 
-	pageStr := r.URL.Query().Get("page")
-	limitStr := r.URL.Query().Get("limit")
+	limit, offset := parsePagination(r)
 
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
+	filter := db.SongFilter{
+		Group:  group,
+		Song:   song,
+		Text:   text,
+		Link:   link,
+		Album:  album,
+		Query:  query,
+		Limit:  limit,
+		Offset: offset,
 	}
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 {
-		limit = 10
+	if id != "" {
+		var err error
+		filter.ID, err = strconv.Atoi(id)
+		if err != nil || filter.ID < 1 {
+			logrus.Errorf("Некорректный ID: %s", id)
+			writeError(w, http.StatusBadRequest, "Некорректный ID")
+			return filter, false
+		}
 	}
-	offset := (page - 1) * limit
 
-	logrus.Debugf("Параметры пагинации - page: %d, limit: %d", page, limit)
+	var ok bool
+	filter.Genres = parseList(r, "genre")
+	if filter.AllGenres, ok = parseMatch(w, r, "genreMatch"); !ok {
+		return filter, false
+	}
+	filter.Tags = parseList(r, "tag")
+	if filter.AllTags, ok = parseMatch(w, r, "tagMatch"); !ok {
+		return filter, false
+	}
 
-	var songs []models.Song
-	query := db.DB.Model(&models.Song{}).Joins("Group")
-	if group != "" {
-		query = query.Joins("JOIN groups ON groups.id = songs.group_id").Where("groups.name = ?", group)
+	if !parseFuzzy(w, r, &filter) {
+		return filter, false
 	}
-	if song != "" {
-		query = query.Where("songs.song = ?", song)
+	if filter.Sort, ok = parseSort(w, r); !ok {
+		return filter, false
 	}
-	if releaseDate != "" {
-		parsedDate, err := time.Parse("2006-02-01", releaseDate)
-		if err == nil {
-			query = query.Where("songs.release_date = ?", parsedDate)
-		} else {
-			logrus.Warnf("Некорректный формат даты: %s", releaseDate)
+
+	if !parseReleaseDates(w, r, &filter) {
+		return filter, false
+	}
+
+	return filter, true
+}
+
+// parseReleaseDates читает фильтры по дате выпуска: точную дату releaseDate, границы
+// releasedFrom и releasedTo, год year и десятилетие decade. Год и десятилетие сужают
+// границы диапазона. При ошибке отправляет ответ 400 и возвращает false.
+func parseReleaseDates(w http.ResponseWriter, r *http.Request, filter *db.SongFilter) bool {
+	query := r.URL.Query()
+	for _, param := range []struct {
+		name   string
+		target **time.Time
+	}{
+		{"releaseDate", &filter.ReleaseDate},
+		{"releasedFrom", &filter.ReleasedFrom},
+		{"releasedTo", &filter.ReleasedTo},
+	} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			logrus.Errorf("Некорректная дата в параметре %s: %s", param.name, value)
+			writeError(w, http.StatusBadRequest, "Параметр "+param.name+" должен быть датой в формате ГГГГ-ММ-ДД")
+			return false
 		}
+		*param.target = &date
+	}
+	if filter.ReleasedFrom != nil && filter.ReleasedTo != nil && filter.ReleasedFrom.After(*filter.ReleasedTo) {
+		logrus.Errorf("releasedFrom %s позже releasedTo %s", query.Get("releasedFrom"), query.Get("releasedTo"))
+		writeError(w, http.StatusBadRequest, "Параметр releasedFrom не может быть позже releasedTo")
+		return false
 	}
-	if text != "" {
-		query = query.Where("songs.text LIKE ?", "%"+text+"%")
+
+	if value := query.Get("year"); value != "" {
+		year, err := strconv.Atoi(value)
+		if err != nil || year < 1 || year > 9999 {
+			logrus.Errorf("Некорректное значение year: %s", value)
+			writeError(w, http.StatusBadRequest, "Параметр year должен быть годом от 1 до 9999")
+			return false
+		}
+		narrowReleaseDates(filter, year, 1)
+	}
+	if value := query.Get("decade"); value != "" {
+		decade, err := strconv.Atoi(value)
+		if err != nil || decade < 10 || decade > 9990 || decade%10 != 0 {
+			logrus.Errorf("Некорректное значение decade: %s", value)
+			writeError(w, http.StatusBadRequest, "Параметр decade должен быть первым годом десятилетия, например 1970")
+			return false
+		}
+		narrowReleaseDates(filter, decade, 10)
 	}
-	if link != "" {
-		query = query.Where("songs.link = ?", link)
+	return true
+}
+
+// narrowReleaseDates сужает границы дат выпуска фильтра до years лет начиная с года from.
+func narrowReleaseDates(filter *db.SongFilter, from, years int) {
+	start := time.Date(from, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(years, 0, -1)
+	if filter.ReleasedFrom == nil || filter.ReleasedFrom.Before(start) {
+		filter.ReleasedFrom = &start
 	}
-	if id != "" {
-		query = query.Where("songs.id = ?", id)
+	if filter.ReleasedTo == nil || filter.ReleasedTo.After(end) {
+		filter.ReleasedTo = &end
 	}
+}
 
-	result := query.Limit(limit).Offset(offset).Find(&songs)
-	if result.Error != nil {
-		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", result.Error)
-		w.WriteHeader(http.StatusInternalServerError)
-		err := json.NewEncoder(w).Encode(models.ErrorResponse{
-			Message: "Внутренняя ошибка сервера",
-		})
+// parseFuzzy читает параметры нечёткого сравнения названий fuzzy и similarity.
+// При ошибке отправляет ответ 400 и возвращает false.
+func parseFuzzy(w http.ResponseWriter, r *http.Request, filter *db.SongFilter) bool {
+	if value := r.URL.Query().Get("fuzzy"); value != "" {
+		fuzzy, err := strconv.ParseBool(value)
 		if err != nil {
-			return
+			logrus.Errorf("Некорректное значение fuzzy: %s", value)
+			writeError(w, http.StatusBadRequest, "Параметр fuzzy должен быть true или false")
+			return false
 		}
-		return
+		filter.Fuzzy = fuzzy
 	}
-	logrus.Info("Запрос к базе данных успешно выполнен")
 
-	if len(songs) == 0 {
-		w.Header().Set("Content-Type", "application/json")
-		err := json.NewEncoder(w).Encode([]models.SongResponse{})
-		if err != nil {
-			logrus.Errorf("Ошибка при кодировании ответа: %v", err)
-			return
+	if value := r.URL.Query().Get("similarity"); value != "" {
+		similarity, err := strconv.ParseFloat(value, 64)
+		if err != nil || !(similarity > 0 && similarity <= 1) {
+			logrus.Errorf("Некорректное значение similarity: %s", value)
+			writeError(w, http.StatusBadRequest, "Параметр similarity должен быть числом больше 0 и не больше 1")
+			return false
 		}
-		logrus.Info("Ответ успешно отправлен: пустой массив")
-		return
+		if !filter.Fuzzy {
+			logrus.Error("Параметр similarity передан без fuzzy=true")
+			writeError(w, http.StatusBadRequest, "Параметр similarity используется только вместе с fuzzy=true")
+			return false
+		}
+		filter.Similarity = similarity
 	}
 
-	var responses []models.SongResponse
-	for _, song := range songs {
-		responses = append(responses, models.SongResponse{
-			Song:        song.Song,
-			ID:          song.ID,
-			Group:       song.Group.Name,
-			Link:        song.Link,
-			ReleaseDate: song.ReleaseDate.Format("2006-01-02"),
-			Text:        song.Text,
-		})
+	if filter.Fuzzy && filter.Song == "" && filter.Group == "" {
+		logrus.Error("Для нечёткого сравнения не указаны song и group")
+		writeError(w, http.StatusBadRequest, "Для нечёткого сравнения укажите song или group")
+		return false
 	}
+	return true
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(responses)
-	if err != nil {
-		logrus.Errorf("Ошибка при кодировании ответа: %v", err)
-		return
+// parseSort читает поля сортировки из параметра sort: список через запятую, минус перед
+// полем означает сортировку по убыванию. При ошибке отправляет ответ 400 и возвращает false.
+func parseSort(w http.ResponseWriter, r *http.Request) ([]db.SortField, bool) {
+	var fields []db.SortField
+	seen := make(map[string]bool)
+	for _, value := range parseList(r, "sort") {
+		field := db.SortField{Field: strings.TrimPrefix(value, "+")}
+		if strings.HasPrefix(value, "-") {
+			field = db.SortField{Field: value[1:], Desc: true}
+		}
+		if !db.IsSongSortField(field.Field) {
+			logrus.Errorf("Некорректное поле сортировки: %s", value)
+			writeError(w, http.StatusBadRequest, "Сортировка по полю "+field.Field+" не поддерживается")
+			return nil, false
+		}
+		if seen[field.Field] {
+			logrus.Errorf("Поле сортировки указано дважды: %s", field.Field)
+			writeError(w, http.StatusBadRequest, "Поле сортировки "+field.Field+" указано дважды")
+			return nil, false
+		}
+		seen[field.Field] = true
+		fields = append(fields, field)
 	}
-	logrus.Info("Ответ успешно отправлен")
+	return fields, true
 }
 
-// GetSongText возвращает текст песни или конкретный куплет.
+// parseList читает параметр запроса name, заданный повтором и/или списком через запятую.
+func parseList(r *http.Request, name string) []string {
+	var values []string
+	for _, value := range r.URL.Query()[name] {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
+
+// parseMatch читает режим совпадения any или all (по умолчанию any) и возвращает true для all.
+// При некорректном значении отправляет ответ 400 и возвращает false вторым значением.
+func parseMatch(w http.ResponseWriter, r *http.Request, name string) (bool, bool) {
+	switch value := r.URL.Query().Get(name); value {
+	case "", "any":
+		return false, true
+	case "all":
+		return true, true
+	default:
+		logrus.Errorf("Некорректное значение %s: %s", name, value)
+		writeError(w, http.StatusBadRequest, "Параметр "+name+" должен быть any или all")
+		return false, false
+	}
+}
+
+// GetSongText возвращает текст песни или его перевод, конкретный куплет, разделы одного типа
+// или строку, которая звучит в указанный момент.
 // @Summary Получить текст песни
-// @Description Возвращает текст песни с возможностью выбора конкретного куплета или всего текста.
+// @Description Возвращает текст песни с возможностью выбора конкретного куплета или всего текста. С параметром section возвращается текст разделов этого типа без повторов, например припева, через пустую строку. Язык выбирается параметром lang или заголовком Accept-Language (если подходящего перевода нет, возвращается оригинал) и указывается в поле language и заголовке Content-Language. Куплеты и разделы перевода нумеруются так же, как в оригинале.
 // @Tags Песни
 // @Accept json
 // @Produce json
 // @Param id path int true "ID песни"
 // @Param verse query int false "Номер куплета"
+// @Param section query string false "Тип раздела текста" Enums(verse, chorus, bridge, intro, outro)
+// @Param lang query string false "Код языка текста: оригинал или перевод" example(en)
+// @Param Accept-Language header string false "Предпочитаемые языки текста, если lang не указан" example(en-US,en;q=0.9)
+// @Param at query string false "Момент песни в формате мм:сс или мм:сс.хх: возвращается строка синхронизированного текста (LRC), которая звучит в этот момент, время её начала start и слово word, если слова размечены" example(01:23)
 // @Success 200 {string} string "Текст песни или куплет"
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse "Перевод не совпадает с оригиналом по числу куплетов"
 // @Failure 500 {object} models.ErrorResponse
 // @Router /songs/{id}/text [get]
-func GetSongText(w http.ResponseWriter, r *http.Request) {
+func (s *Server) GetSongText(w http.ResponseWriter, r *http.Request) {
 	logrus.Info("Начало обработки запроса на получение текста песни")
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil || id < 1 {
 		logrus.Errorf("Некорректный ID: %s", vars["id"])
-		w.WriteHeader(http.StatusBadRequest)
-		err := json.NewEncoder(w).Encode(models.ErrorResponse{Message: "Некорректный ID"})
-		if err != nil {
-			return
-		}
+		writeError(w, http.StatusBadRequest, "Некорректный ID")
 		return
 	}
 	logrus.Debugf("Получение песни с ID: %d", id)
-	var song models.Song
-	result := db.DB.First(&song, id)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
+	song, err := s.songs.GetByID(id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
 			logrus.Error("Песня не найдена")
-			w.WriteHeader(http.StatusNotFound)
-			err := json.NewEncoder(w).Encode(models.ErrorResponse{Message: "Песня не найдена"})
-			if err != nil {
-				return
-			}
+			writeError(w, http.StatusNotFound, "Песня не найдена")
 			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
-		err := json.NewEncoder(w).Encode(models.ErrorResponse{Message: "Внутренняя ошибка сервера при поиске песни"})
-		if err != nil {
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера при поиске песни")
+		return
+	}
+
+	if at := r.URL.Query().Get("at"); at != "" {
+		if r.URL.Query().Get("verse") != "" || r.URL.Query().Get("section") != "" || r.URL.Query().Get("lang") != "" {
+			logrus.Error("Параметр at передан вместе с verse, section или lang")
+			writeError(w, http.StatusBadRequest, "Параметр at нельзя использовать вместе с verse, section и lang")
 			return
 		}
+		s.writeLineAt(w, song, at)
 		return
 	}
 
-	if song.Text == "" {
+	variant, ok := s.findTextVariant(w, r, song)
+	if !ok {
+		return
+	}
+	if variant.text == "" {
 		logrus.Error("Текст песни не найден")
-		w.WriteHeader(http.StatusNotFound)
-		err := json.NewEncoder(w).Encode(models.ErrorResponse{Message: "Текст песни не найден"})
-		if err != nil {
+		writeError(w, http.StatusNotFound, "Текст песни не найден")
+		return
+	}
+	verses := lyrics.Split(variant.text)
+
+	section, ok := parseSection(w, r)
+	if !ok {
+		return
+	}
+	verseStr := r.URL.Query().Get("verse")
+	if variant.translation && (section != "" || verseStr != "") && len(verses) != len(lyrics.Split(song.Text)) {
+		logrus.Errorf("Перевод на язык %s не совпадает с оригиналом по числу куплетов", variant.language)
+		writeError(w, http.StatusConflict, "Перевод не совпадает с оригиналом по числу куплетов, обновите перевод")
+		return
+	}
+	if section != "" {
+		if verseStr != "" {
+			logrus.Error("Переданы одновременно verse и section")
+			writeError(w, http.StatusBadRequest, "Параметры verse и section нельзя использовать вместе")
 			return
 		}
+		sections, ok := s.findLyrics(w, id)
+		if !ok {
+			return
+		}
+		var texts []string
+		for _, item := range newLyricsResponse(id, sections, section).Sections {
+			if variant.translation && item.Position <= len(verses) {
+				item.Text = verses[item.Position-1]
+			}
+			texts = append(texts, item.Text)
+		}
+		if len(texts) == 0 {
+			logrus.Errorf("В тексте песни нет разделов типа %s", section)
+			writeError(w, http.StatusNotFound, "В тексте песни нет разделов этого типа")
+			return
+		}
+
+		logrus.Infof("Отправка разделов типа %s", section)
+		writeText(w, variant, strings.Join(texts, "\n\n"))
 		return
 	}
 
-	verses := strings.Split(song.Text, "\n\n")
-	verseStr := r.URL.Query().Get("verse")
 	if verseStr != "" {
 		verse, err := strconv.Atoi(verseStr)
 		if err != nil || verse < 1 || verse > len(verses) {
 			logrus.Errorf("Некорректный номер куплета: %s", verseStr)
-			w.WriteHeader(http.StatusBadRequest)
-			err := json.NewEncoder(w).Encode(models.ErrorResponse{Message: "Некорректный номер куплета"})
-			if err != nil {
-				return
-			}
+			writeError(w, http.StatusBadRequest, "Некорректный номер куплета")
 			return
 		}
 
 		logrus.Infof("Отправка куплета номер %d", verse)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		err = json.NewEncoder(w).Encode(map[string]string{"text": verses[verse-1]})
-		if err != nil {
-			logrus.Errorf("Ошибка при кодировании куплета: %v", err)
-			return
-		}
+		writeText(w, variant, verses[verse-1])
 		return
 	}
 
 	logrus.Info("Отправка полного текста песни")
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	err = json.NewEncoder(w).Encode(map[string]string{"text": song.Text})
-	if err != nil {
-		logrus.Errorf("Ошибка при кодировании текста: %v", err)
-		return
-	}
+	writeText(w, variant, variant.text)
 }
 
-// DeleteSong удаляет песню по её ID.
+// DeleteSong перемещает песню в корзину по её ID.
 // @Summary Удалить песню
-// @Description Удаляет песню по её ID.
+// @Description Перемещает песню в корзину по её ID. Песня убирается из всех плейлистов, позиции остальных песен в них сдвигаются; при восстановлении из корзины она возвращается на прежние места. Песни в корзине не видны в списках и счётчиках и окончательно удаляются по истечении срока хранения.
+// @Description С заголовком If-Match песня удаляется, только если её текущий ETag (поле etag и заголовок ETag ответов с песней) есть в списке, иначе возвращается 412; «*» требует только существования песни.
+// @Description С заголовком Idempotency-Key ответ сохраняется, и повтор запроса с тем же ключом получает тот же ответ с заголовком Idempotent-Replayed, не удаляя песню повторно. Пока запрос с ключом выполняется, повтор получает 409. Ключ нельзя использовать для другого запроса.
 // @Tags Песни
 // @Param id path int true "ID песни"
+// @Param If-Match header string false "ETag песни, которую можно удалить"
+// @Param Idempotency-Key header string false "Ключ идемпотентности запроса"
 // @Success 200 {object} models.MessageResponse "Успешное удаление песни"
 // @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса"
 // @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 409 {object} models.ErrorResponse "Запрос с этим ключом идемпотентности ещё выполняется"
+// @Failure 412 {object} models.ErrorResponse "Песня изменилась после получения ETag"
+// @Failure 422 {object} models.ErrorResponse "Ключ идемпотентности использован для другого запроса"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /songs/{id} [delete]
-func DeleteSong(w http.ResponseWriter, r *http.Request) {
+func (s *Server) DeleteSong(w http.ResponseWriter, r *http.Request) {
 	logrus.Info("Начало обработки запроса на удаление песни")
-	vars := mux.Vars(r)
-	idStr := vars["id"]
+	s.idempotent(w, r, s.deleteSong)
+}
 
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		logrus.Errorf("Некорректный ID: %s", idStr)
-		w.WriteHeader(http.StatusBadRequest)
-		err := json.NewEncoder(w).Encode(models.ErrorResponse{
-			Message: "Некорректный ID",
-		})
-		if err != nil {
-			return
-		}
+func (s *Server) deleteSong(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r, "id")
+	if !ok {
 		return
 	}
 
 	logrus.Debugf("ID песни для удаления: %d", id)
 
-	result := db.DB.Delete(&models.Song{}, id)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			logrus.Warnf("Песня с ID %d не найдена", id)
-			w.WriteHeader(http.StatusNotFound)
-			err := json.NewEncoder(w).Encode(models.ErrorResponse{
-				Message: "Песня не найдена",
-			})
-			if err != nil {
-				return
-			}
+	var err error
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		// ETag сравнивается в транзакции удаления, чтобы песню нельзя было изменить между
+		// проверкой и удалением.
+		var etag string
+		err = s.songs.DeleteIf(id, func(song models.Song) bool {
+			etag = songETag(song)
+			return etagMatches(ifMatch, etag)
+		})
+		if errors.Is(err, db.ErrPreconditionFailed) {
+			logrus.Warnf("Песня с ID %d изменилась: ETag %s не совпадает с If-Match %s", id, etag, ifMatch)
+			w.Header().Set("ETag", etag)
+			writeError(w, http.StatusPreconditionFailed, "Песня изменилась, получите её заново и повторите запрос")
 			return
 		}
-		logrus.Errorf("Ошибка при удалении песни из базы данных: %v", result.Error)
-		w.WriteHeader(http.StatusInternalServerError)
-		err := json.NewEncoder(w).Encode(models.ErrorResponse{
-			Message: "Внутренняя ошибка сервера",
-		})
-		if err != nil {
+	} else {
+		err = s.songs.Delete(id)
+	}
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			logrus.Warnf("Песня с ID %d не найдена", id)
+			writeError(w, http.StatusNotFound, "Песня не найдена")
 			return
 		}
+		logrus.Errorf("Ошибка при удалении песни из базы данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
 		return
 	}
 
 	logrus.Infof("Песня с ID %d успешно удалена", id)
 
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(models.MessageResponse{
-		Message: "Песня успешно удалена",
-	})
-	if err != nil {
-		logrus.Errorf("Ошибка при кодировании ответа: %v", err)
-		return
-	}
+	writeJSON(w, http.StatusOK, models.MessageResponse{Message: "Песня успешно удалена"})
 	logrus.Info("Ответ успешно отправлен")
 }
 
@@ -301,12 +647,13 @@ func DeleteSong(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Param        id      path      int     true   "ID песни"
 // @Param        song    body      models.UpdateSongRequest true "Поля, которые могут быть изменены (отправьте только те поля, которые требуют изменений)"
+// @Param        X-User  header    string  false  "Автор изменения для истории ревизий"
 // @Success      200     {object}  models.MessageResponse "Успешное обновление песни"
 // @Failure      400     {object}  models.ErrorResponse "Некорректные данные запроса"
 // @Failure      404     {object}  models.ErrorResponse "Песня не найдена"
 // @Failure      500     {object}  models.ErrorResponse "Внутренняя ошибка сервера"
 // @Router       /songs/{id} [patch]
-func UpdateSong(w http.ResponseWriter, r *http.Request) {
+func (s *Server) UpdateSong(w http.ResponseWriter, r *http.Request) {
 	logrus.Info("Начало обработки запроса на изменение данных песни")
 	vars := mux.Vars(r)
 	idStr := vars["id"]
@@ -314,13 +661,7 @@ func UpdateSong(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		logrus.Errorf("Некорректный ID: %s", idStr)
-		w.WriteHeader(http.StatusBadRequest)
-		err := json.NewEncoder(w).Encode(models.ErrorResponse{
-			Message: "Некорректный ID",
-		})
-		if err != nil {
-			return
-		}
+		writeError(w, http.StatusBadRequest, "Некорректный ID")
 		return
 	}
 
@@ -330,244 +671,261 @@ func UpdateSong(w http.ResponseWriter, r *http.Request) {
 	err = json.NewDecoder(r.Body).Decode(&updateData)
 	if err != nil {
 		logrus.Errorf("Ошибка при декодировании запроса: %v", err)
-		w.WriteHeader(http.StatusBadRequest)
-		err := json.NewEncoder(w).Encode(models.ErrorResponse{
-			Message: "Некорректные данные запроса",
-		})
-		if err != nil {
-			return
-		}
+		writeError(w, http.StatusBadRequest, "Некорректные данные запроса")
 		return
 	}
 
-	var song models.Song
-	result := db.DB.First(&song, id)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
+	song, err := s.songs.GetByID(id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
 			logrus.Warnf("Песня с ID %d не найдена", id)
-			w.WriteHeader(http.StatusNotFound)
-			err := json.NewEncoder(w).Encode(models.ErrorResponse{
-				Message: "Песня не найдена",
-			})
-			if err != nil {
-				return
-			}
-			return
-		}
-		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", result.Error)
-		w.WriteHeader(http.StatusInternalServerError)
-		err := json.NewEncoder(w).Encode(models.ErrorResponse{
-			Message: "Внутренняя ошибка сервера",
-		})
-		if err != nil {
+			writeError(w, http.StatusNotFound, "Песня не найдена")
 			return
 		}
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
 		return
 	}
 
-	if updateData.Group != nil && *updateData.Group != "string" {
-		groupName := *updateData.Group
-		var group models.Group
-		if err := db.DB.Where("name = ?", groupName).First(&group).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				group = models.Group{Name: groupName}
-				if err := db.DB.Create(&group).Error; err != nil {
-					logrus.Errorf("Ошибка при создании группы: %v", err)
-					w.WriteHeader(http.StatusInternalServerError)
-					err := json.NewEncoder(w).Encode(models.ErrorResponse{
-						Message: "Внутренняя ошибка сервера",
-					})
-					if err != nil {
-						return
-					}
-					return
-				}
-			} else {
-				logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
-				w.WriteHeader(http.StatusInternalServerError)
-				err := json.NewEncoder(w).Encode(models.ErrorResponse{
-					Message: "Внутренняя ошибка сервера",
-				})
-				if err != nil {
-					return
-				}
-				return
-			}
+	if !s.applySongUpdate(w, song, updateData) {
+		return
+	}
+
+	if err := s.songs.Update(song, revisionAuthor(r)); err != nil {
+		logrus.Errorf("Ошибка при обновлении песни в базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Данные песни с ID %d успешно обновлены", id)
+
+	w.Header().Set("ETag", songETag(*song))
+	writeJSON(w, http.StatusOK, models.MessageResponse{Message: "Данные успешно обновлены"})
+	logrus.Info("Ответ успешно отправлен")
+}
+
+// applySongUpdate переносит в песню поля из запроса на изменение, которые не равны nil.
+// При некорректных данных отправляет ответ и возвращает false.
+func (s *Server) applySongUpdate(w http.ResponseWriter, song *models.Song, request models.UpdateSongRequest) bool {
+	if request.Group != nil && *request.Group != "string" {
+		group, err := s.groups.FindOrCreate(*request.Group)
+		if err != nil {
+			logrus.Errorf("Ошибка при поиске или создании группы: %v", err)
+			writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+			return false
 		}
 		song.GroupID = group.ID
+		song.Group = *group
 	}
-	if updateData.Song != nil && *updateData.Song != "string" {
-		song.Song = *updateData.Song
+	if request.AlbumID != nil {
+		if !s.setSongAlbum(w, song, *request.AlbumID) {
+			return false
+		}
+		if song.AlbumID == nil {
+			song.DiscNumber = 0
+			song.TrackNumber = 0
+		}
+	} else if song.Album != nil && song.Album.GroupID != song.GroupID {
+		logrus.Warnf("Альбом с ID %d принадлежит другой группе", song.Album.ID)
+		writeError(w, http.StatusBadRequest, "Альбом принадлежит другой группе, укажите albumId")
+		return false
 	}
-	if updateData.ReleaseDate != nil {
-		if *updateData.ReleaseDate != "" && *updateData.ReleaseDate != "string" {
-			parsedDate, err := time.Parse("2006-01-02", *updateData.ReleaseDate)
+	if request.DiscNumber != nil {
+		song.DiscNumber = *request.DiscNumber
+	}
+	if request.TrackNumber != nil {
+		song.TrackNumber = *request.TrackNumber
+	}
+	if !validTrackPosition(w, song.DiscNumber, song.TrackNumber) {
+		return false
+	}
+	if request.Song != nil && *request.Song != "string" {
+		song.Song = *request.Song
+	}
+	if request.ReleaseDate != nil {
+		if *request.ReleaseDate != "" && *request.ReleaseDate != "string" {
+			parsedDate, err := time.Parse("2006-01-02", *request.ReleaseDate)
 			if err != nil {
 				logrus.Errorf("Некорректный формат даты: %v", err)
-				w.WriteHeader(http.StatusBadRequest)
-				err = json.NewEncoder(w).Encode(models.ErrorResponse{
-					Message: "Некорректный формат даты, ожидается формат YYYY-MM-DD",
-				})
-				if err != nil {
-					return
-				}
-				return
+				writeError(w, http.StatusBadRequest, "Некорректный формат даты, ожидается формат YYYY-MM-DD")
+				return false
 			}
 			song.ReleaseDate = parsedDate
-		} else if *updateData.ReleaseDate == "" {
+		} else if *request.ReleaseDate == "" {
 			song.ReleaseDate = time.Time{}
 		}
 	}
-	if updateData.Text != nil && *updateData.Text != "string" {
-		song.Text = *updateData.Text
+	if request.Duration != nil {
+		if !s.validDuration(w, song.ID, *request.Duration) {
+			return false
+		}
+		song.Duration = *request.Duration
 	}
-	if updateData.Link != nil && *updateData.Link != "string" {
-		song.Link = *updateData.Link
+	if request.Text != nil && *request.Text != "string" {
+		song.Text = *request.Text
 	}
-
-	result = db.DB.Save(&song)
-	if result.Error != nil {
-		logrus.Errorf("Ошибка при обновлении песни в базе данных: %v", result.Error)
-		w.WriteHeader(http.StatusInternalServerError)
-		err := json.NewEncoder(w).Encode(models.ErrorResponse{
-			Message: "Внутренняя ошибка сервера",
-		})
-		if err != nil {
-			return
-		}
-		return
+	if request.Link != nil && *request.Link != "string" {
+		song.Link = *request.Link
 	}
-
-	logrus.Infof("Данные песни с ID %d успешно обновлены", id)
-
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(models.MessageResponse{Message: "Данные успешно обновлены"})
-	if err != nil {
-		logrus.Errorf("Ошибка при кодировании ответа: %v", err)
-		return
+	if request.Language != nil && *request.Language != "string" {
+		if !s.setSongLanguage(w, song, *request.Language) {
+			return false
+		}
 	}
-	logrus.Info("Ответ успешно отправлен")
+	return true
 }
 
 // CreateSong добавляет новую песню в библиотеку.
 // @Summary Добавить новую песню
-// @Description Добавление новой песни в базу данных. Данные о песне обогащаются информацией с внешнего API.
+// @Description Добавление новой песни в базу данных. Песню можно сразу привязать к альбому той же группы (albumId, discNumber, trackNumber). Песня сохраняется сразу со статусом обогащения pending, данные из внешнего API (текст, ссылка, дата выпуска) добавляются в фоновом режиме.
 // @Tags Песни
 // @Accept  json
 // @Produce  json
 // @Param song body models.CreateSongRequest true "Данные песни (группа, название)"
-// @Success 200 {object} models.SongResponse "Успешное добавление песни с внешними данными"
+// @Param X-User header string false "Автор изменения для истории ревизий"
+// @Success 200 {object} models.SongResponse "Успешное добавление песни"
 // @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера при сохранении песни"
 // @Router /songs [post]
-func CreateSong(w http.ResponseWriter, r *http.Request) {
+func (s *Server) CreateSong(w http.ResponseWriter, r *http.Request) {
 	logrus.Info("Начало обработки запроса на создание новой песни")
 	var newSong models.CreateSongRequest
 
 	err := json.NewDecoder(r.Body).Decode(&newSong)
 	if err != nil {
 		logrus.Errorf("Ошибка при декодировании запроса: %v", err)
-		w.WriteHeader(http.StatusBadRequest)
-		err := json.NewEncoder(w).Encode(models.ErrorResponse{
-			Message: "Некорректные данные запроса",
-		})
-		if err != nil {
-			return
-		}
+		writeError(w, http.StatusBadRequest, "Некорректные данные запроса")
 		return
 	}
 
 	logrus.Debugf("Данные новой песни - group: %s, song: %s", newSong.Group, newSong.Song)
 
-	var group models.Group
-	result := db.DB.Where("name = ?", newSong.Group).First(&group)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			group = models.Group{Name: newSong.Group}
-			result = db.DB.Create(&group)
-			if result.Error != nil {
-				logrus.Errorf("Ошибка при создании группы: %v", result.Error)
-				w.WriteHeader(http.StatusInternalServerError)
-				err := json.NewEncoder(w).Encode(models.ErrorResponse{
-					Message: "Ошибка при создании группы",
-				})
-				if err != nil {
-					return
-				}
-				return
-			}
-			logrus.Info("Группа успешно создана")
-		} else {
-			logrus.Errorf("Ошибка при поиске группы: %v", result.Error)
-			w.WriteHeader(http.StatusInternalServerError)
-			err := json.NewEncoder(w).Encode(models.ErrorResponse{
-				Message: "Внутренняя ошибка сервера",
-			})
-			if err != nil {
-				return
-			}
-			return
-		}
+	group, err := s.groups.FindOrCreate(newSong.Group)
+	if err != nil {
+		logrus.Errorf("Ошибка при поиске или создании группы: %v", err)
+		writeError(w, http.StatusInternalServerError, "Ошибка при создании группы")
+		return
 	}
 
 	song := models.Song{
-		GroupID: group.ID,
-		Song:    newSong.Song,
+		GroupID:          group.ID,
+		Group:            *group,
+		Song:             newSong.Song,
+		DiscNumber:       newSong.DiscNumber,
+		TrackNumber:      newSong.TrackNumber,
+		EnrichmentStatus: models.EnrichmentPending,
+	}
+	if newSong.AlbumID != nil && !s.setSongAlbum(w, &song, *newSong.AlbumID) {
+		return
 	}
-
-	externalData := FetchExternalSongData(song.Group.Name, song.Song)
-	if externalData != nil {
-		logrus.Info("Получены данные из внешнего API")
-		if releaseDate, err := time.Parse("2006-01-02", externalData.ReleaseDate); err == nil {
-			song.ReleaseDate = releaseDate
-		}
-		if err != nil {
-			logrus.Errorf("Ошибка при парсинге даты: %v", err)
-			w.WriteHeader(http.StatusInternalServerError)
-			err := json.NewEncoder(w).Encode(models.ErrorResponse{
-				Message: "Внутренняя ошибка сервера",
-			})
-			if err != nil {
-				return
-			}
-			return
-		}
-		song.Text = externalData.Text
-		song.Link = externalData.Link
-	} else {
-		logrus.Warn("Данные из внешнего API не получены")
+	if !validTrackPosition(w, song.DiscNumber, song.TrackNumber) {
+		return
 	}
 
-	result = db.DB.Create(&song)
-	if result.Error != nil {
-		logrus.Errorf("Ошибка при сохранении песни в базу данных: %v", result.Error)
-		w.WriteHeader(http.StatusInternalServerError)
-		err := json.NewEncoder(w).Encode(models.ErrorResponse{
-			Message: "Внутренняя ошибка сервера",
-		})
-		if err != nil {
-			return
-		}
+	if err := s.songs.Create(&song, revisionAuthor(r)); err != nil {
+		logrus.Errorf("Ошибка при сохранении песни в базу данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
 		return
 	}
 
 	logrus.Info("Песня успешно сохранена в базе данных")
+	s.enrichment.Enqueue(song.ID)
+
+	writeSong(w, http.StatusOK, song)
+	logrus.Info("Ответ успешно отправлен")
+}
+
+// findSong возвращает песню по ID. Если песня не найдена или произошла ошибка,
+// отправляет соответствующий ответ и возвращает false.
+func (s *Server) findSong(w http.ResponseWriter, id int) (*models.Song, bool) {
+	song, err := s.songs.GetByID(id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			logrus.Warnf("Песня с ID %d не найдена", id)
+			writeError(w, http.StatusNotFound, "Песня не найдена")
+			return nil, false
+		}
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return nil, false
+	}
+	return song, true
+}
 
+// newSongResponse преобразует песню в формат ответа API.
+func newSongResponse(song models.Song) models.SongResponse {
 	response := models.SongResponse{
-		ID:          song.ID,
-		Song:        song.Song,
-		Group:       newSong.Group,
-		Link:        song.Link,
-		ReleaseDate: song.ReleaseDate.Format("2006-01-02"),
-		Text:        song.Text,
+		ID:               song.ID,
+		Song:             song.Song,
+		Group:            song.Group.Name,
+		Link:             song.Link,
+		ReleaseDate:      song.ReleaseDate.Format("2006-01-02"),
+		Duration:         song.Duration,
+		Text:             song.Text,
+		Language:         song.Language,
+		DiscNumber:       song.DiscNumber,
+		TrackNumber:      song.TrackNumber,
+		EnrichmentStatus: song.EnrichmentStatus,
+	}
+	if song.Album != nil {
+		response.AlbumID = song.Album.ID
+		response.Album = song.Album.Title
+	}
+	for _, genre := range song.Genres {
+		response.Genres = append(response.Genres, genre.Name)
+	}
+	for _, tag := range song.Tags {
+		response.Tags = append(response.Tags, tag.Name)
+	}
+	if song.DeletedAt.Valid {
+		response.DeletedAt = song.DeletedAt.Time.UTC().Format(time.RFC3339)
+	}
+	response.ETag = songETag(song)
+	return response
+}
+
+// writeSong отправляет песню в формате ответа API с её ETag в заголовке.
+func writeSong(w http.ResponseWriter, status int, song models.Song) {
+	w.Header().Set("ETag", songETag(song))
+	writeJSON(w, status, newSongResponse(song))
+}
+
+// songETag возвращает ETag песни — хэш полей, которые записываются в ревизии, жанров и тегов.
+// Он меняется при каждом изменении песни, кроме изменения статуса обогащения.
+func songETag(song models.Song) string {
+	genres := make([]string, 0, len(song.Genres))
+	for _, genre := range song.Genres {
+		genres = append(genres, genre.Name)
+	}
+	tags := make([]string, 0, len(song.Tags))
+	for _, tag := range song.Tags {
+		tags = append(tags, tag.Name)
 	}
+	sort.Strings(genres)
+	sort.Strings(tags)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	err = json.NewEncoder(w).Encode(response)
-	if err != nil {
-		logrus.Errorf("Ошибка при кодировании ответа: %v", err)
-		return
+	data, _ := json.Marshal([]any{db.SongFields(song), genres, tags})
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches проверяет условие заголовка If-Match: «*» или ETag через запятую. Слабые
+// ETag (W/"…") не совпадают ни с чем, так как If-Match требует строгого сравнения.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
 	}
-	logrus.Info("Ответ успешно отправлен")
+	return false
+}
+
+// newSongResponses преобразует список песен в формат ответа API.
+func newSongResponses(songs []models.Song) []models.SongResponse {
+	responses := make([]models.SongResponse, 0, len(songs))
+	for _, song := range songs {
+		responses = append(responses, newSongResponse(song))
+	}
+	return responses
 }
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []db.SortField
+		// status — код ответа, если параметр отклоняется.
+		status int
+	}{
+		{query: "", want: nil},
+		{query: "sort=song", want: []db.SortField{{Field: db.SortSong}}},
+		{query: "sort=%2Bsong", want: []db.SortField{{Field: db.SortSong}}},
+		{query: "sort=-releaseDate,group", want: []db.SortField{{Field: db.SortReleaseDate, Desc: true}, {Field: db.SortGroup}}},
+		{query: "sort=album&sort=-id", want: []db.SortField{{Field: db.SortAlbum}, {Field: db.SortID, Desc: true}}},
+		{query: "sort=text", status: http.StatusBadRequest},
+		{query: "sort=release_date", status: http.StatusBadRequest},
+		{query: "sort=songs.id", status: http.StatusBadRequest},
+		{query: "sort=--song", status: http.StatusBadRequest},
+		{query: "sort=id%3B%20DROP%20TABLE%20songs", status: http.StatusBadRequest},
+		{query: "sort=song,-song", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			fields, ok := parseSort(w, httptest.NewRequest(http.MethodGet, "/songs?"+tt.query, nil))
+			if tt.status != 0 {
+				if ok || w.Code != tt.status {
+					t.Fatalf("parseSort() = %v, %v, код %d, ожидался код %d", fields, ok, w.Code, tt.status)
+				}
+				return
+			}
+			if !ok || !slices.Equal(fields, tt.want) {
+				t.Errorf("parseSort() = %v, %v, ожидалось %v", fields, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestSongCursorRoundTrip(t *testing.T) {
+	releaseDate := time.Date(1975, 11, 21, 0, 0, 0, 0, time.UTC)
+	album := "a night at the opera"
+	cursor := songCursor{
+		Sort:     "-releaseDate,album",
+		Backward: true,
+		Key:      db.SongSortKey{ID: 7, ReleaseDate: &releaseDate, Album: &album},
+	}
+
+	value := encodeSongCursor(cursor)
+	got, err := decodeSongCursor(value)
+	if err != nil {
+		t.Fatalf("decodeSongCursor(%q): %v", value, err)
+	}
+	if !reflect.DeepEqual(got, cursor) {
+		t.Errorf("decodeSongCursor() = %+v, ожидалось %+v", got, cursor)
+	}
+}
+
+func TestDecodeSongCursorRejectsMalformed(t *testing.T) {
+	valid := encodeSongCursor(songCursor{Sort: "song", Key: db.SongSortKey{ID: 3, Song: "Innuendo"}})
+	encode := func(data string) string { return base64.RawURLEncoding.EncodeToString([]byte(data)) }
+
+	for name, value := range map[string]string{
+		"не base64":          "курсор!",
+		"стандартный base64": base64.StdEncoding.EncodeToString([]byte(`{"s":"song","k":{"id":3}}`)),
+		"не JSON":            encode("not json"),
+		"обрезанный":         valid[:len(valid)-4],
+		"другой тип ключа":   encode(`{"s":"song","k":{"id":"3"}}`),
+		"без ID":             encode(`{"s":"song","k":{"song":"Innuendo"}}`),
+		"отрицательный ID":   encode(`{"s":"song","k":{"id":-1}}`),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if cursor, err := decodeSongCursor(value); err == nil {
+				t.Errorf("decodeSongCursor(%q) = %+v без ошибки", value, cursor)
+			}
+		})
+	}
+}
+
+// getSongPage выполняет GET /songs?query и разбирает страницу ответа со статусом 200.
+func getSongPage(t *testing.T, s *Server, query string) (models.SongPageResponse, int) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	s.GetFilteredSongs(w, httptest.NewRequest(http.MethodGet, "/songs?"+query, nil))
+	var page models.SongPageResponse
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return page, w.Code
+}
+
+func TestSongListCursor(t *testing.T) {
+	storage := db.NewMemoryStorage()
+	s := NewServer(storage, nil, nil)
+	group, err := storage.Groups.FindOrCreate("Queen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// У всех песен одна дата выпуска: порядок страниц задаёт только ID.
+	releaseDate := time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	var want []int
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		song := models.Song{GroupID: group.ID, Song: name, ReleaseDate: releaseDate}
+		if err := storage.Songs.Create(&song, "test"); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, song.ID)
+	}
+
+	var got []int
+	var last models.SongPageResponse
+	query := "sort=-releaseDate&limit=2&total=true"
+	for cursor := ""; ; {
+		page, code := getSongPage(t, s, query+"&cursor="+cursor)
+		if code != http.StatusOK {
+			t.Fatalf("страница после курсора %q: статус %d", cursor, code)
+		}
+		for _, item := range page.Items {
+			got = append(got, item.ID)
+		}
+		last = page
+		if page.Next == "" {
+			break
+		}
+		cursor = page.Next
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("страницы вперёд: %v, ожидалось %v", got, want)
+	}
+
+	previous, code := getSongPage(t, s, query+"&cursor="+last.Prev)
+	if code != http.StatusOK || len(previous.Items) != 2 || previous.Items[0].ID != want[2] || previous.Items[1].ID != want[3] {
+		t.Fatalf("предыдущая страница: статус %d, %+v", code, previous.Items)
+	}
+
+	for name, tc := range map[string]struct {
+		query string
+		want  int
+	}{
+		"мусор":               {"sort=-releaseDate&cursor=garbage", http.StatusBadRequest},
+		"изменённый":          {"sort=-releaseDate&cursor=" + last.Prev[:len(last.Prev)-3] + "xyz", http.StatusBadRequest},
+		"другая сортировка":   {"sort=releaseDate&cursor=" + last.Prev, http.StatusBadRequest},
+		"без сортировки":      {"cursor=" + last.Prev, http.StatusBadRequest},
+		"вместе со страницей": {"sort=-releaseDate&page=2&cursor=" + last.Prev, http.StatusBadRequest},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, code := getSongPage(t, s, tc.query); code != tc.want {
+				t.Errorf("статус %d, ожидался %d", code, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseGenreAndTagFilter(t *testing.T) {
+	tests := []struct {
+		query     string
+		genres    []string
+		allGenres bool
+		tags      []string
+		allTags   bool
+		status    int
+	}{
+		{query: ""},
+		{query: "genre=Rock", genres: []string{"Rock"}},
+		{query: "genre=Rock,%20Pop,&genre=Jazz&genreMatch=all", genres: []string{"Rock", "Pop", "Jazz"}, allGenres: true},
+		{query: "tag=live&tag=remaster&tagMatch=any", tags: []string{"live", "remaster"}},
+		{query: "tag=live&tagMatch=all&genreMatch=any", tags: []string{"live"}, allTags: true},
+		{query: "genre=Rock&genreMatch=ALL", status: http.StatusBadRequest},
+		{query: "tag=live&tagMatch=some", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			filter, ok := parseSongFilter(w, httptest.NewRequest(http.MethodGet, "/songs?"+tt.query, nil))
+			if tt.status != 0 {
+				if ok || w.Code != tt.status {
+					t.Fatalf("parseSongFilter() = %v, код %d, ожидался код %d", ok, w.Code, tt.status)
+				}
+				return
+			}
+			if !ok || !slices.Equal(filter.Genres, tt.genres) || filter.AllGenres != tt.allGenres ||
+				!slices.Equal(filter.Tags, tt.tags) || filter.AllTags != tt.allTags {
+				t.Errorf("parseSongFilter() = жанры %v (все: %v), теги %v (все: %v)", filter.Genres, filter.AllGenres, filter.Tags, filter.AllTags)
+			}
+		})
+	}
+}
+
+func TestParseReleaseDates(t *testing.T) {
+	tests := []struct {
+		query string
+		// from, to и exact — ожидаемые границы и точная дата в формате ГГГГ-ММ-ДД, пустые — не заданы.
+		from, to, exact string
+		status          int
+	}{
+		{query: ""},
+		{query: "releaseDate=1991-02-04", exact: "1991-02-04"},
+		{query: "releasedFrom=1991-01-01&releasedTo=1991-12-31", from: "1991-01-01", to: "1991-12-31"},
+		{query: "releasedFrom=1991-06-15&releasedTo=1991-06-15", from: "1991-06-15", to: "1991-06-15"},
+		{query: "releasedTo=1975-11-21", to: "1975-11-21"},
+		{query: "year=1991", from: "1991-01-01", to: "1991-12-31"},
+		{query: "decade=1970", from: "1970-01-01", to: "1979-12-31"},
+		{query: "year=1991&releasedFrom=1991-06-01", from: "1991-06-01", to: "1991-12-31"},
+		{query: "year=1991&releasedFrom=1980-01-01&releasedTo=2000-01-01", from: "1991-01-01", to: "1991-12-31"},
+		{query: "decade=1990&year=1991", from: "1991-01-01", to: "1991-12-31"},
+		{query: "releasedFrom=1991-12-31&releasedTo=1991-01-01", status: http.StatusBadRequest},
+		{query: "releasedFrom=04.02.1991", status: http.StatusBadRequest},
+		{query: "releaseDate=1991-02-30", status: http.StatusBadRequest},
+		{query: "year=0", status: http.StatusBadRequest},
+		{query: "year=nineteen", status: http.StatusBadRequest},
+		{query: "decade=1975", status: http.StatusBadRequest},
+		{query: "decade=5", status: http.StatusBadRequest},
+	}
+	format := func(date *time.Time) string {
+		if date == nil {
+			return ""
+		}
+		return date.Format("2006-01-02")
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			var filter db.SongFilter
+			ok := parseReleaseDates(w, httptest.NewRequest(http.MethodGet, "/songs?"+tt.query, nil), &filter)
+			if tt.status != 0 {
+				if ok || w.Code != tt.status {
+					t.Fatalf("parseReleaseDates() = %v, код %d, ожидался код %d", ok, w.Code, tt.status)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("parseReleaseDates() отклонил запрос: %s", w.Body)
+			}
+			if from, to, exact := format(filter.ReleasedFrom), format(filter.ReleasedTo), format(filter.ReleaseDate); from != tt.from || to != tt.to || exact != tt.exact {
+				t.Errorf("parseReleaseDates() = %q..%q, дата %q; ожидалось %q..%q, %q", from, to, exact, tt.from, tt.to, tt.exact)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ListTags возвращает теги с количеством песен.
+// @Summary Получить список тегов
+// @Description Возвращает теги по убыванию количества песен и затем по названию, с пагинацией.
+// @Tags Жанры и теги
+// @Produce json
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество записей на странице" default(10)
+// @Success 200 {array} models.TagResponse
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /tags [get]
+func (s *Server) ListTags(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение списка тегов")
+	limit, offset := parsePagination(r)
+
+	tags, err := s.tags.List(limit, offset)
+	if err != nil {
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	responses := make([]models.TagResponse, 0, len(tags))
+	for _, tag := range tags {
+		responses = append(responses, models.TagResponse{ID: tag.ID, Name: tag.Name, SongCount: tag.SongCount})
+	}
+
+	writeJSON(w, http.StatusOK, responses)
+	logrus.Info("Ответ успешно отправлен")
+}
+
+// DeleteTag удаляет тег.
+// @Summary Удалить тег
+// @Description Удаляет тег и снимает его со всех песен.
+// @Tags Жанры и теги
+// @Produce json
+// @Param id path int true "ID тега"
+// @Success 200 {object} models.MessageResponse "Тег удалён"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} models.ErrorResponse "Тег не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /tags/{id} [delete]
+func (s *Server) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на удаление тега")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	err := s.tags.Delete(id)
+	if errors.Is(err, db.ErrNotFound) {
+		logrus.Warnf("Тег с ID %d не найден", id)
+		writeError(w, http.StatusNotFound, "Тег не найден")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при удалении тега из базы данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Тег с ID %d успешно удалён", id)
+	writeJSON(w, http.StatusOK, models.MessageResponse{Message: "Тег успешно удалён"})
+}
+
+// SetSongTags заменяет теги песни.
+// @Summary Задать теги песни
+// @Description Заменяет теги песни переданным списком. Теги, которых ещё нет, создаются.
+// @Tags Жанры и теги
+// @Accept json
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param tags body models.SetSongTagsRequest true "Названия тегов"
+// @Param X-User header string false "Автор изменения для истории ревизий"
+// @Success 200 {object} models.SongResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/tags [put]
+func (s *Server) SetSongTags(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на изменение тегов песни")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var request models.SetSongTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logrus.Errorf("Ошибка при декодировании запроса: %v", err)
+		writeError(w, http.StatusBadRequest, "Некорректные данные запроса")
+		return
+	}
+
+	if _, ok := s.findSong(w, id); !ok {
+		return
+	}
+
+	tags, err := s.tags.FindOrCreate(request.Tags)
+	if err != nil {
+		logrus.Errorf("Ошибка при сохранении тегов: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+	ids := make([]int, 0, len(tags))
+	for _, tag := range tags {
+		ids = append(ids, tag.ID)
+	}
+	if err := s.songs.SetTags(id, ids, revisionAuthor(r)); err != nil {
+		logrus.Errorf("Ошибка при сохранении тегов песни: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+	logrus.Infof("Теги песни с ID %d обновлены", id)
+
+	song, ok := s.findSong(w, id)
+	if !ok {
+		return
+	}
+	writeSong(w, http.StatusOK, *song)
+}
+
+// GetSongFacets возвращает распределение песен по жанрам и тегам.
+// @Summary Получить фасеты песен
+// @Description Считает, сколько песен, подходящих под фильтры (те же, что у GET /songs, без пагинации), относится к каждому жанру и тегу.
+// @Tags Жанры и теги
+// @Produce json
+// @Param song query string false "Фильтр по названию песни"
+// @Param group query string false "Фильтр по названию группы"
+// @Param text query string false "Фильтр по фрагменту текста песни"
+// @Param q query string false "Полнотекстовый поиск по названию и тексту песни"
+// @Param fuzzy query bool false "Сравнивать song и group по сходству (с опечатками), а не на равенство"
+// @Param similarity query number false "Минимальное сходство названий при fuzzy=true, от 0 до 1" default(0.3)
+// @Param link query string false "Фильтр по ссылке"
+// @Param album query string false "Фильтр по названию альбома"
+// @Param releaseDate query string false "Фильтр по точной дате выпуска, ГГГГ-ММ-ДД"
+// @Param releasedFrom query string false "Выпущены не раньше даты, ГГГГ-ММ-ДД"
+// @Param releasedTo query string false "Выпущены не позже даты, ГГГГ-ММ-ДД"
+// @Param year query int false "Год выпуска"
+// @Param decade query int false "Десятилетие выпуска, например 1970"
+// @Param genre query []string false "Фильтр по жанрам" collectionFormat(multi)
+// @Param genreMatch query string false "any или all" Enums(any, all) default(any)
+// @Param tag query []string false "Фильтр по тегам" collectionFormat(multi)
+// @Param tagMatch query string false "any или all" Enums(any, all) default(any)
+// @Success 200 {object} models.SongFacetsResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректные параметры фильтра"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/facets [get]
+func (s *Server) GetSongFacets(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение фасетов песен")
+	filter, ok := parseSongFilter(w, r)
+	if !ok {
+		return
+	}
+
+	facets, err := s.songs.Facets(filter)
+	if err != nil {
+		logrus.Errorf("Ошибка при выполнении запроса к базе данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SongFacetsResponse{
+		Total:  facets.Total,
+		Genres: newFacetValues(facets.Genres),
+		Tags:   newFacetValues(facets.Tags),
+	})
+	logrus.Info("Ответ успешно отправлен")
+}
+
+// newFacetValues преобразует счётчики жанров или тегов в формат ответа API.
+func newFacetValues(terms []db.TermCount) []models.FacetValueResponse {
+	values := make([]models.FacetValueResponse, 0, len(terms))
+	for _, term := range terms {
+		values = append(values, models.FacetValueResponse{ID: term.ID, Name: term.Name, Count: term.SongCount})
+	}
+	return values
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"net/http"
+	"testing"
+)
+
+func TestGetSongFacets(t *testing.T) {
+	storage := db.NewMemoryStorage()
+	s := NewServer(storage, noopEnrichment{}, nil)
+	group, err := storage.Groups.FindOrCreate("Queen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rock, err := storage.Genres.Create("Rock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, err := storage.Tags.FindOrCreate([]string{"live", "remaster"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tagIDs := range [][]int{{tags[0].ID, tags[1].ID}, {tags[0].ID}, nil} {
+		song := models.Song{GroupID: group.ID, Song: "Innuendo"}
+		if err := storage.Songs.Create(&song, "test"); err != nil {
+			t.Fatal(err)
+		}
+		if err := storage.Songs.SetGenres(song.ID, []int{rock.ID}, "test"); err != nil {
+			t.Fatal(err)
+		}
+		if err := storage.Songs.SetTags(song.ID, tagIDs, "test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var facets models.SongFacetsResponse
+	decodeJSON(t, serveJSON(s.GetSongFacets, http.MethodGet, "/songs/facets?tag=live,remaster&tagMatch=all&limit=1", "", ""), http.StatusOK, &facets)
+	if facets.Total != 1 || len(facets.Genres) != 1 || facets.Genres[0].Name != "Rock" || facets.Genres[0].Count != 1 || len(facets.Tags) != 2 {
+		t.Errorf("фасеты песен со всеми тегами: %+v", facets)
+	}
+	decodeJSON(t, serveJSON(s.GetSongFacets, http.MethodGet, "/songs/facets?genre=Rock&page=2", "", ""), http.StatusOK, &facets)
+	if facets.Total != 3 || facets.Genres[0].Count != 3 || facets.Tags[0].Name != "live" || facets.Tags[0].Count != 2 {
+		t.Errorf("фасеты песен жанра: %+v", facets)
+	}
+	if w := serveJSON(s.GetSongFacets, http.MethodGet, "/songs/facets?tagMatch=both", "", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("некорректный tagMatch: статус %d", w.Code)
+	}
+}
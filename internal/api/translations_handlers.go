@@ -0,0 +1,284 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"music_storage/internal/db"
+	"music_storage/internal/lyrics"
+	"music_storage/internal/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// lyricsVariant — текст песни на одном языке: оригинал или перевод.
+type lyricsVariant struct {
+	language    string
+	text        string
+	translation bool
+}
+
+// ListSongTranslations возвращает оригинальный текст песни и его переводы.
+// @Summary Получить переводы текста песни
+// @Description Возвращает оригинальный текст песни первым, затем переводы в порядке кодов языков. Для каждого варианта указано число куплетов и совпадает ли оно с оригиналом.
+// @Tags Песни
+// @Produce json
+// @Param id path int true "ID песни"
+// @Success 200 {object} models.LyricsVariantsResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/translations [get]
+func (s *Server) ListSongTranslations(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение переводов текста песни")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	song, ok := s.findSong(w, id)
+	if !ok {
+		return
+	}
+	translations, ok := s.findTranslations(w, id)
+	if !ok {
+		return
+	}
+
+	verses := len(lyrics.Split(song.Text))
+	response := models.LyricsVariantsResponse{SongID: id, Variants: []models.LyricsVariantResponse{{
+		Language: song.Language,
+		Original: true,
+		Verses:   verses,
+		Aligned:  true,
+		Text:     song.Text,
+	}}}
+	for _, translation := range translations {
+		count := len(lyrics.Split(translation.Text))
+		response.Variants = append(response.Variants, models.LyricsVariantResponse{
+			Language: translation.Language,
+			Verses:   count,
+			Aligned:  count == verses,
+			Text:     translation.Text,
+		})
+	}
+	logrus.Infof("Отправка %d переводов текста песни с ID %d", len(translations), id)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// SetSongTranslation добавляет или заменяет перевод текста песни.
+// @Summary Сохранить перевод текста песни
+// @Description Добавляет или заменяет перевод текста песни на язык lang. Если у песни есть текст, число куплетов (блоков через пустую строку) в переводе должно совпадать с оригиналом: тогда номер куплета и разделы оригинала указывают на соответствующие места перевода.
+// @Tags Песни
+// @Accept json
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param lang path string true "Код языка, например en или pt-br"
+// @Param translation body models.SetTranslationRequest true "Текст перевода"
+// @Param X-User header string false "Автор изменения для истории ревизий"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректные данные запроса"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/translations/{lang} [put]
+func (s *Server) SetSongTranslation(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на сохранение перевода текста песни")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	language, ok := parseLanguage(w, mux.Vars(r)["lang"], "lang")
+	if !ok {
+		return
+	}
+	var request models.SetTranslationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logrus.Errorf("Ошибка при декодировании запроса: %v", err)
+		writeError(w, http.StatusBadRequest, "Некорректные данные запроса")
+		return
+	}
+	translation := lyrics.Split(request.Text)
+	if len(translation) == 0 {
+		logrus.Error("Пустой текст перевода")
+		writeError(w, http.StatusBadRequest, "Текст перевода не может быть пустым")
+		return
+	}
+
+	song, ok := s.findSong(w, id)
+	if !ok {
+		return
+	}
+	if language == song.Language {
+		logrus.Errorf("Язык перевода %s совпадает с языком оригинала", language)
+		writeError(w, http.StatusBadRequest, "Язык перевода совпадает с языком оригинала, измените текст песни")
+		return
+	}
+	if original := lyrics.Split(song.Text); len(original) > 0 && len(original) != len(translation) {
+		logrus.Errorf("В переводе %d куплетов, в оригинале %d", len(translation), len(original))
+		writeError(w, http.StatusBadRequest, "В переводе "+strconv.Itoa(len(translation))+
+			" куплетов, а в оригинале "+strconv.Itoa(len(original))+": куплеты разделяются пустой строкой")
+		return
+	}
+
+	if err := s.songs.SetTranslation(id, language, request.Text, revisionAuthor(r)); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			logrus.Warnf("Песня с ID %d не найдена", id)
+			writeError(w, http.StatusNotFound, "Песня не найдена")
+			return
+		}
+		logrus.Errorf("Ошибка при сохранении перевода текста песни: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+	logrus.Infof("Перевод текста песни с ID %d на язык %s сохранён", id, language)
+	writeJSON(w, http.StatusOK, models.MessageResponse{Message: "Перевод сохранён"})
+}
+
+// DeleteSongTranslation удаляет перевод текста песни.
+// @Summary Удалить перевод текста песни
+// @Description Удаляет перевод текста песни на язык lang.
+// @Tags Песни
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param lang path string true "Код языка"
+// @Param X-User header string false "Автор изменения для истории ревизий"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
+// @Failure 404 {object} models.ErrorResponse "Песня или перевод не найдены"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/translations/{lang} [delete]
+func (s *Server) DeleteSongTranslation(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на удаление перевода текста песни")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+	language, ok := parseLanguage(w, mux.Vars(r)["lang"], "lang")
+	if !ok {
+		return
+	}
+	if err := s.songs.DeleteTranslation(id, language, revisionAuthor(r)); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			logrus.Warnf("Перевод песни с ID %d на язык %s не найден", id, language)
+			writeError(w, http.StatusNotFound, "Перевод не найден")
+			return
+		}
+		logrus.Errorf("Ошибка при удалении перевода текста песни: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+	logrus.Infof("Перевод текста песни с ID %d на язык %s удалён", id, language)
+	writeJSON(w, http.StatusOK, models.MessageResponse{Message: "Перевод удалён"})
+}
+
+// parseLanguage проверяет код языка из параметра name и приводит его к нижнему регистру.
+// При некорректном коде отправляет ответ 400 и возвращает false.
+func parseLanguage(w http.ResponseWriter, value, name string) (string, bool) {
+	language, ok := lyrics.NormalizeLanguage(value)
+	if !ok {
+		logrus.Errorf("Некорректный код языка: %s", value)
+		writeError(w, http.StatusBadRequest, "Параметр "+name+" должен быть кодом языка, например en или pt-br")
+		return "", false
+	}
+	return language, true
+}
+
+// findTranslations загружает переводы текста песни. При ошибке отправляет ответ 404 или 500
+// и возвращает false.
+func (s *Server) findTranslations(w http.ResponseWriter, id int) ([]models.SongTranslation, bool) {
+	translations, err := s.songs.ListTranslations(id)
+	if errors.Is(err, db.ErrNotFound) {
+		logrus.Warnf("Песня с ID %d не найдена", id)
+		writeError(w, http.StatusNotFound, "Песня не найдена")
+		return nil, false
+	}
+	if err != nil {
+		logrus.Errorf("Ошибка при получении переводов текста песни: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return nil, false
+	}
+	return translations, true
+}
+
+// findTextVariant выбирает язык текста песни: по параметру lang, а без него — по заголовку
+// Accept-Language. Язык оригинала выбирает оригинальный текст. Если в заголовке нет
+// подходящего языка, возвращается оригинал; если нет перевода на язык из lang, отправляет
+// ответ 404 и возвращает false.
+func (s *Server) findTextVariant(w http.ResponseWriter, r *http.Request, song *models.Song) (lyricsVariant, bool) {
+	w.Header().Add("Vary", "Accept-Language")
+	original := lyricsVariant{language: song.Language, text: song.Text}
+	header := r.Header.Get("Accept-Language")
+	value := r.URL.Query().Get("lang")
+	if value != "" {
+		language, ok := parseLanguage(w, value, "lang")
+		if !ok {
+			return lyricsVariant{}, false
+		}
+		header = language
+	}
+	if header == "" {
+		return original, true
+	}
+
+	translations, ok := s.findTranslations(w, song.ID)
+	if !ok {
+		return lyricsVariant{}, false
+	}
+	var available []string
+	if song.Language != "" {
+		available = append(available, song.Language)
+	}
+	for _, translation := range translations {
+		available = append(available, translation.Language)
+	}
+	language, found := lyrics.Negotiate(header, available)
+	if !found && value != "" {
+		logrus.Warnf("Текст песни с ID %d на языке %s не найден", song.ID, value)
+		writeError(w, http.StatusNotFound, "Текст песни на этом языке не найден")
+		return lyricsVariant{}, false
+	}
+	for _, translation := range translations {
+		if found && translation.Language == language {
+			return lyricsVariant{language: language, text: translation.Text, translation: true}, true
+		}
+	}
+	return original, true
+}
+
+// writeText отправляет текст песни вместе с его языком в теле и заголовке Content-Language.
+func writeText(w http.ResponseWriter, variant lyricsVariant, text string) {
+	response := map[string]string{"text": text}
+	if variant.language != "" {
+		w.Header().Set("Content-Language", variant.language)
+		response["language"] = variant.language
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// setSongLanguage задаёт язык оригинального текста песни; пустая строка сбрасывает его.
+// Язык не может совпадать с языком одного из переводов. При ошибке отправляет ответ
+// и возвращает false.
+func (s *Server) setSongLanguage(w http.ResponseWriter, song *models.Song, value string) bool {
+	if value == "" {
+		song.Language = ""
+		return true
+	}
+	language, ok := parseLanguage(w, value, "language")
+	if !ok {
+		return false
+	}
+	translations, ok := s.findTranslations(w, song.ID)
+	if !ok {
+		return false
+	}
+	for _, translation := range translations {
+		if translation.Language == language {
+			logrus.Errorf("У песни с ID %d уже есть перевод на язык %s", song.ID, language)
+			writeError(w, http.StatusConflict, "У песни уже есть перевод на этот язык, удалите его, чтобы сделать язык оригиналом")
+			return false
+		}
+	}
+	song.Language = language
+	return true
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ListTrash возвращает песни в корзине.
+// @Summary Получить корзину
+// @Description Возвращает песни, удалённые в корзину, от недавно удалённых к давно удалённым, с временем удаления deletedAt и пагинацией. Песни окончательно удаляются из корзины по истечении срока хранения (TRASH_RETENTION).
+// @Tags Корзина
+// @Produce json
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество записей на странице" default(10)
+// @Success 200 {array} models.SongResponse
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /trash [get]
+func (s *Server) ListTrash(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на получение корзины")
+	limit, offset := parsePagination(r)
+
+	songs, err := s.songs.ListTrash(limit, offset)
+	if err != nil {
+		logrus.Errorf("Ошибка при получении корзины из базы данных: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+	logrus.Infof("Отправка %d песен из корзины", len(songs))
+	writeJSON(w, http.StatusOK, newSongResponses(songs))
+}
+
+// RestoreSong возвращает песню из корзины.
+// @Summary Восстановить песню из корзины
+// @Description Возвращает песню из корзины вместе с текстом, жанрами, тегами и ревизиями. Песня возвращается в плейлисты, которые ещё существуют, на прежние позиции (или в конец, если плейлист стал короче). Альбом, удалённый после удаления песни, не восстанавливается.
+// @Tags Корзина
+// @Produce json
+// @Param id path int true "ID песни"
+// @Success 200 {object} models.SongResponse
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена в корзине"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/restore [post]
+func (s *Server) RestoreSong(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на восстановление песни из корзины")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.songs.Restore(id); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			logrus.Warnf("Песня с ID %d не найдена в корзине", id)
+			writeError(w, http.StatusNotFound, "Песня не найдена в корзине")
+			return
+		}
+		logrus.Errorf("Ошибка при восстановлении песни из корзины: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+	song, ok := s.findSong(w, id)
+	if !ok {
+		return
+	}
+
+	logrus.Infof("Песня с ID %d восстановлена из корзины", id)
+	writeSong(w, http.StatusOK, *song)
+}
+
+// PurgeSong окончательно удаляет песню из корзины.
+// @Summary Удалить песню из корзины
+// @Description Окончательно удаляет песню из корзины вместе с текстом, переводами и ревизиями. Восстановить её после этого нельзя.
+// @Tags Корзина
+// @Produce json
+// @Param id path int true "ID песни"
+// @Success 200 {object} models.MessageResponse "Песня удалена из корзины"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена в корзине"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /trash/{id} [delete]
+func (s *Server) PurgeSong(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на удаление песни из корзины")
+	id, ok := parseID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.songs.Purge(id); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			logrus.Warnf("Песня с ID %d не найдена в корзине", id)
+			writeError(w, http.StatusNotFound, "Песня не найдена в корзине")
+			return
+		}
+		logrus.Errorf("Ошибка при удалении песни из корзины: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Песня с ID %d окончательно удалена", id)
+	writeJSON(w, http.StatusOK, models.MessageResponse{Message: "Песня удалена из корзины"})
+}
+
+// EmptyTrash окончательно удаляет все песни из корзины.
+// @Summary Очистить корзину
+// @Description Окончательно удаляет все песни из корзины, не дожидаясь истечения срока хранения.
+// @Tags Корзина
+// @Produce json
+// @Success 200 {object} models.MessageResponse "Корзина очищена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /trash [delete]
+func (s *Server) EmptyTrash(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Начало обработки запроса на очистку корзины")
+
+	purged, err := s.songs.PurgeTrash(time.Now().UTC())
+	if err != nil {
+		logrus.Errorf("Ошибка при очистке корзины: %v", err)
+		writeError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return
+	}
+
+	logrus.Infof("Из корзины окончательно удалено песен: %d", purged)
+	writeJSON(w, http.StatusOK, models.MessageResponse{Message: fmt.Sprintf("Корзина очищена, удалено песен: %d", purged)})
+}
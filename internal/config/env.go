@@ -0,0 +1,55 @@
+// Package config читает параметры приложения из переменных окружения.
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Duration возвращает значение переменной окружения key в формате time.ParseDuration
+// или fallback, если переменная не задана или задана некорректно.
+func Duration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		logrus.Warnf("Некорректное значение %s=%s, используется %s", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// Int возвращает неотрицательное целое значение переменной окружения key
+// или fallback, если переменная не задана или задана некорректно.
+func Int(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		logrus.Warnf("Некорректное значение %s=%s, используется %d", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// Bool возвращает логическое значение переменной окружения key в формате strconv.ParseBool
+// или fallback, если переменная не задана или задана некорректно.
+func Bool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		logrus.Warnf("Некорректное значение %s=%s, используется %t", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
@@ -0,0 +1,160 @@
+package db_test
+
+import (
+	"errors"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+// createAlbum добавляет альбом группы group и возвращает его.
+func createAlbum(t *testing.T, storage *db.Storage, group, title string) models.Album {
+	t.Helper()
+	g, err := storage.Groups.FindOrCreate(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	album := models.Album{GroupID: g.ID, Title: title, ReleaseDate: time.Date(1991, 2, 4, 0, 0, 0, 0, time.UTC)}
+	if err := storage.Albums.Create(&album); err != nil {
+		t.Fatal(err)
+	}
+	return album
+}
+
+// createTrack добавляет песню альбома album на диск disc под номером track.
+func createTrack(t *testing.T, storage *db.Storage, album models.Album, name string, disc, track int) models.Song {
+	t.Helper()
+	song := models.Song{GroupID: album.GroupID, Song: name, AlbumID: &album.ID, DiscNumber: disc, TrackNumber: track}
+	if err := storage.Songs.Create(&song, "test"); err != nil {
+		t.Fatal(err)
+	}
+	return song
+}
+
+func TestAlbumList(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			innuendo := createAlbum(t, storage, "Queen", "Innuendo")
+			heaven := createAlbum(t, storage, "Queen", "Made in Heaven")
+			arrival := createAlbum(t, storage, "ABBA", "Arrival")
+			createTrack(t, storage, innuendo, "Innuendo", 1, 1)
+			createTrack(t, storage, innuendo, "Bijou", 1, 11)
+			trashed := createTrack(t, storage, innuendo, "Delilah", 1, 7)
+			if err := storage.Songs.Delete(trashed.ID); err != nil {
+				t.Fatal(err)
+			}
+			createTrack(t, storage, arrival, "Dancing Queen", 1, 2)
+
+			albums, err := storage.Albums.List(db.AlbumFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(albums) != 3 {
+				t.Fatalf("альбомов %d, ожидалось 3", len(albums))
+			}
+			first := albums[0]
+			if first.ID != innuendo.ID || first.Title != "Innuendo" || first.GroupName != "Queen" ||
+				first.TrackCount != 2 || !first.ReleaseDate.Equal(innuendo.ReleaseDate) {
+				t.Errorf("первый альбом: %+v", first)
+			}
+			if albums[1].TrackCount != 0 || albums[2].TrackCount != 1 {
+				t.Errorf("количество треков: %d и %d, ожидалось 0 и 1", albums[1].TrackCount, albums[2].TrackCount)
+			}
+
+			for _, tc := range []struct {
+				name   string
+				filter db.AlbumFilter
+				want   []int
+			}{
+				{"по группе", db.AlbumFilter{Group: " QUEEN "}, []int{innuendo.ID, heaven.ID}},
+				{"по ID группы", db.AlbumFilter{GroupID: arrival.GroupID}, []int{arrival.ID}},
+				{"по названию", db.AlbumFilter{Title: "made  in heaven"}, []int{heaven.ID}},
+				{"страница", db.AlbumFilter{Limit: 1, Offset: 1}, []int{heaven.ID}},
+				{"неизвестная группа", db.AlbumFilter{Group: "Muse"}, nil},
+			} {
+				albums, err := storage.Albums.List(tc.filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var ids []int
+				for _, album := range albums {
+					ids = append(ids, album.ID)
+				}
+				if !slices.Equal(ids, tc.want) {
+					t.Errorf("%s: %v, ожидалось %v", tc.name, ids, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAlbumTracks(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			album := createAlbum(t, storage, "Queen", "Innuendo")
+			unnumbered := createTrack(t, storage, album, "Ride the Wild Wind", 0, 0)
+			second := createTrack(t, storage, album, "Headlong", 1, 2)
+			bonus := createTrack(t, storage, album, "Lost Opportunity", 2, 1)
+			first := createTrack(t, storage, album, "Innuendo", 1, 1)
+			sameNumber := createTrack(t, storage, album, "I'm Going Slightly Mad", 1, 2)
+			createSong(t, storage, "Queen", models.Song{Song: "Mustapha"})
+
+			tracks, err := storage.Albums.ListTracks(album.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []int{unnumbered.ID, first.ID, second.ID, sameNumber.ID, bonus.ID}
+			if ids := songIDs(tracks); !slices.Equal(ids, want) {
+				t.Errorf("ListTracks() = %v, ожидалось %v", ids, want)
+			}
+			if tracks[1].DiscNumber != 1 || tracks[1].TrackNumber != 1 || tracks[1].Album == nil || tracks[1].Album.Title != "Innuendo" {
+				t.Errorf("трек %+v", tracks[1])
+			}
+		})
+	}
+}
+
+func TestDeleteAlbumDetachesSongs(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			album := createAlbum(t, storage, "Queen", "Innuendo")
+			other := createAlbum(t, storage, "Queen", "Made in Heaven")
+			kept := createTrack(t, storage, album, "Innuendo", 1, 1)
+			trashed := createTrack(t, storage, album, "Delilah", 1, 7)
+			if err := storage.Songs.Delete(trashed.ID); err != nil {
+				t.Fatal(err)
+			}
+			untouched := createTrack(t, storage, other, "Mother Love", 1, 10)
+
+			if err := storage.Albums.Delete(album.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := storage.Albums.GetByID(album.ID); !errors.Is(err, db.ErrNotFound) {
+				t.Errorf("GetByID() удалённого альбома: %v", err)
+			}
+			if err := storage.Albums.Delete(album.ID); !errors.Is(err, db.ErrNotFound) {
+				t.Errorf("повторное удаление: %v, ожидалась ErrNotFound", err)
+			}
+
+			// Песни альбома, в том числе из корзины, сохраняются без альбома и номеров.
+			if err := storage.Songs.Restore(trashed.ID); err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range []int{kept.ID, trashed.ID} {
+				song, err := storage.Songs.GetByID(id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if song.AlbumID != nil || song.DiscNumber != 0 || song.TrackNumber != 0 {
+					t.Errorf("песня %d после удаления альбома: альбом %v, диск %d, трек %d", id, song.AlbumID, song.DiscNumber, song.TrackNumber)
+				}
+			}
+			song, err := storage.Songs.GetByID(untouched.ID)
+			if err != nil || song.AlbumID == nil || *song.AlbumID != other.ID || song.TrackNumber != 10 {
+				t.Errorf("песня другого альбома: %+v, %v", song, err)
+			}
+		})
+	}
+}
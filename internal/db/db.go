@@ -2,18 +2,51 @@ package db
 
 import (
 	"fmt"
-	"music_storage/internal/models"
 	"os"
 
+	"github.com/glebarez/sqlite"
 	"github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-var DB *gorm.DB
+// Поддерживаемые значения переменной окружения DB_DRIVER.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// defaultSQLitePath используется, если не задана переменная окружения DB_PATH.
+const defaultSQLitePath = "music_library.db"
 
-func Connect() {
-	dsn := fmt.Sprintf(
+// Connect подключается к базе данных, выбранной переменной окружения DB_DRIVER
+// (по умолчанию PostgreSQL). Схема создаётся миграциями из пакета migrations.
+func Connect() *gorm.DB {
+	driver := os.Getenv("DB_DRIVER")
+	if driver == "" {
+		driver = DriverPostgres
+	}
+
+	var dialector gorm.Dialector
+	switch driver {
+	case DriverPostgres:
+		dialector = postgres.Open(postgresDSN())
+	case DriverSQLite:
+		dialector = sqlite.Open(sqliteDSN())
+	default:
+		logrus.Fatalf("Неизвестный драйвер базы данных: %s", driver)
+	}
+
+	conn, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		logrus.Fatalf("Не удалось подключиться к базе данных: %v", err)
+	}
+	logrus.Infof("Успешное подключение к базе данных (%s)", driver)
+	return conn
+}
+
+func postgresDSN() string {
+	return fmt.Sprintf(
 		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
 		os.Getenv("DB_HOST"),
 		os.Getenv("DB_USER"),
@@ -21,17 +54,16 @@ func Connect() {
 		os.Getenv("DB_NAME"),
 		os.Getenv("DB_PORT"),
 	)
+}
 
-	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	if err != nil {
-		logrus.Fatalf("Не удалось подключиться к базе данных: %v", err)
-	}
-	logrus.Info("Успешное подключение к базе данных")
-
-	err = DB.AutoMigrate(&models.Group{}, &models.Song{})
-	if err != nil {
-		logrus.Fatalf("Ошибка автоматической миграции: %v", err)
+// sqliteDSN возвращает путь к файлу SQLite с прагмами, которые приближают
+// поведение SQLite к PostgreSQL: LIKE чувствителен к регистру, внешние ключи проверяются.
+// Транзакции сразу берут блокировку на запись и ждут друг друга вместо SELECT ... FOR UPDATE,
+// который SQLite не поддерживает.
+func sqliteDSN() string {
+	path := os.Getenv("DB_PATH")
+	if path == "" {
+		path = defaultSQLitePath
 	}
-	logrus.Info("Автоматическая миграция завершена успешно")
+	return path + "?_pragma=case_sensitive_like(1)&_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_txlock=immediate"
 }
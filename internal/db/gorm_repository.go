@@ -0,0 +1,1763 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"music_storage/internal/lyrics"
+	"music_storage/internal/models"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NewGormStorage создаёт хранилище поверх подключения GORM.
+func NewGormStorage(conn *gorm.DB) *Storage {
+	return &Storage{
+		Songs:           &gormSongRepository{db: conn, nativeSearch: conn.Dialector.Name() == DriverPostgres},
+		Groups:          &gormGroupRepository{db: conn},
+		Albums:          &gormAlbumRepository{db: conn},
+		Genres:          &gormGenreRepository{db: conn},
+		Tags:            &gormTagRepository{db: conn},
+		Playlists:       &gormPlaylistRepository{db: conn},
+		MetadataCache:   &gormMetadataCacheRepository{db: conn},
+		IdempotencyKeys: &gormIdempotencyRepository{db: conn},
+	}
+}
+
+type gormSongRepository struct {
+	db *gorm.DB
+	// nativeSearch — база данных поддерживает полнотекстовый поиск и pg_trgm (PostgreSQL);
+	// иначе поиск по filter.Query и нечёткое сравнение названий выполняются в приложении.
+	nativeSearch bool
+}
+
+func (r *gormSongRepository) Create(song *models.Song, author string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return createSong(tx, song, author)
+	})
+}
+
+func (r *gormSongRepository) CreateMany(songs []models.Song, author string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range songs {
+			if songs[i].GroupID == 0 {
+				group, err := findOrCreateGroup(tx, songs[i].Group.Name)
+				if err != nil {
+					return err
+				}
+				songs[i].GroupID = group.ID
+				songs[i].Group = *group
+			}
+			if err := createSong(tx, &songs[i], author); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// createSong сохраняет песню с разделами текста и первой ревизией.
+func createSong(tx *gorm.DB, song *models.Song, author string) error {
+	if err := tx.Omit(clause.Associations).Create(song).Error; err != nil {
+		return err
+	}
+	if err := replaceSections(tx, song.ID, lyrics.Detect(song.Text)); err != nil {
+		return err
+	}
+	return recordRevision(tx, nil, *song, author)
+}
+
+func (r *gormSongRepository) GetByID(id int) (*models.Song, error) {
+	var song models.Song
+	err := withSongAssociations(r.db).First(&song, "songs.id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &song, nil
+}
+
+func (r *gormSongRepository) GetFiltered(filter SongFilter) ([]models.Song, error) {
+	if filter.Query != "" || filter.Fuzzy {
+		results, err := r.Search(filter)
+		return searchResultSongs(results), err
+	}
+
+	var songs []models.Song
+	query := applyOrder(r.applyFilter(withSongAssociations(r.db.Model(&models.Song{})), filter), filter)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if err := query.Offset(filter.Offset).Find(&songs).Error; err != nil {
+		return nil, err
+	}
+	if filter.Cursor != nil && filter.Cursor.Backward {
+		slices.Reverse(songs)
+	}
+	return songs, nil
+}
+
+func (r *gormSongRepository) Count(filter SongFilter) (int, error) {
+	var total int64
+	err := r.withSimilarity(filter, func(tx *gorm.DB) error {
+		return r.applyFilter(tx.Model(&models.Song{}), filter).Count(&total).Error
+	})
+	return int(total), err
+}
+
+// withSongAssociations добавляет к запросу песен загрузку группы, альбома, жанров и тегов.
+func withSongAssociations(query *gorm.DB) *gorm.DB {
+	return query.Joins("Group").Joins("Album").
+		Preload("Genres", func(db *gorm.DB) *gorm.DB { return db.Order("genres.name") }).
+		Preload("Tags", func(db *gorm.DB) *gorm.DB { return db.Order("tags.name") })
+}
+
+// applyFilter добавляет к запросу по таблице songs условия фильтра без пагинации.
+func (r *gormSongRepository) applyFilter(query *gorm.DB, filter SongFilter) *gorm.DB {
+	if filter.Group != "" {
+		query = query.Joins("JOIN groups ON groups.id = songs.group_id")
+		if !filter.Fuzzy {
+			query = query.Where("groups.normalized_name = ?", NormalizeName(filter.Group))
+		}
+	}
+	if filter.GroupID != 0 {
+		query = query.Where("songs.group_id = ?", filter.GroupID)
+	}
+	if filter.Song != "" && !filter.Fuzzy {
+		query = query.Where("songs.song = ?", filter.Song)
+	}
+	if filter.ReleaseDate != nil {
+		query = query.Where("songs.release_date = ?", *filter.ReleaseDate)
+	}
+	if filter.ReleasedFrom != nil {
+		query = query.Where("songs.release_date >= ?", *filter.ReleasedFrom)
+	} else if filter.ReleasedTo != nil {
+		query = query.Where("songs.release_date > ?", time.Time{})
+	}
+	if filter.ReleasedTo != nil {
+		query = query.Where("songs.release_date <= ?", *filter.ReleasedTo)
+	}
+	if filter.Text != "" {
+		query = query.Where("songs.text LIKE ?", "%"+filter.Text+"%")
+	}
+	if filter.Link != "" {
+		query = query.Where("songs.link = ?", filter.Link)
+	}
+	if filter.Query != "" || filter.Fuzzy {
+		query = r.applySearch(query, filter)
+	}
+	if filter.Album != "" {
+		query = query.Joins("JOIN albums ON albums.id = songs.album_id").Where("albums.normalized_title = ?", NormalizeName(filter.Album))
+	}
+	if names := normalizeNames(filter.Genres); len(names) > 0 {
+		query = query.Where("songs.id IN (?)", r.termSongIDs("song_genres", "genres", "genre_id", names, filter.AllGenres))
+	}
+	if names := normalizeNames(filter.Tags); len(names) > 0 {
+		query = query.Where("songs.id IN (?)", r.termSongIDs("song_tags", "tags", "tag_id", names, filter.AllTags))
+	}
+	if filter.ID != 0 {
+		query = query.Where("songs.id = ?", filter.ID)
+	}
+	return query
+}
+
+// songSearchQuery — tsquery поискового запроса @q: слова запроса приводятся к основе
+// по правилам и русского, и английского языка (см. миграцию 0009_song_search).
+const songSearchQuery = "(websearch_to_tsquery('russian', @q) || websearch_to_tsquery('english', @q))"
+
+// songSearchSnippet выбирает куплет песни, лучше всего подходящий под запрос, и выделяет
+// в нём совпадения. Подсветка выполняется по правилам языка, по которому совпал куплет.
+const songSearchSnippet = `COALESCE((
+	SELECT ts_headline(
+		CASE WHEN to_tsvector('russian', verse) @@ ` + songSearchQuery + ` THEN 'russian' ELSE 'english' END::regconfig,
+		verse, ` + songSearchQuery + `, 'StartSel=<b>, StopSel=</b>, HighlightAll=true')
+	FROM regexp_split_to_table(songs.text, E'\n\n') AS verse
+	WHERE (to_tsvector('russian', verse) || to_tsvector('english', verse)) @@ ` + songSearchQuery + `
+	ORDER BY ts_rank(to_tsvector('russian', verse) || to_tsvector('english', verse), ` + songSearchQuery + `) DESC
+	LIMIT 1
+), '')`
+
+// applySearch добавляет к запросу условия поиска по filter.Query и нечёткого сравнения
+// названий. Оператор % из pg_trgm использует порог сходства, установленный withSimilarity.
+// Без поддержки в базе данных песни, подходящие под остальные условия, проверяются в приложении.
+func (r *gormSongRepository) applySearch(query *gorm.DB, filter SongFilter) *gorm.DB {
+	if r.nativeSearch {
+		if filter.Query != "" {
+			query = query.Where("songs.search_vector @@ "+songSearchQuery, sql.Named("q", filter.Query))
+		}
+		if filter.Fuzzy && filter.Song != "" {
+			query = query.Where("songs.song % ?", filter.Song)
+		}
+		if filter.Fuzzy && filter.Group != "" {
+			query = query.Where("groups.normalized_name % ?", NormalizeName(filter.Group))
+		}
+		return query
+	}
+
+	results, err := r.searchInProcess(filter)
+	if err != nil {
+		query.AddError(err)
+		return query
+	}
+	ids := make([]int, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.Song.ID)
+	}
+	return query.Where("songs.id IN ?", ids)
+}
+
+func (r *gormSongRepository) Search(filter SongFilter) ([]SongSearchResult, error) {
+	var results []SongSearchResult
+	if r.nativeSearch {
+		err := r.withSimilarity(filter, func(tx *gorm.DB) error {
+			selects, args := searchSelect(filter)
+			var rows []struct {
+				ID      int
+				Rank    float64
+				Snippet string
+				Score   float64
+			}
+			query := r.applyFilter(tx.Model(&models.Song{}), filter).Select(selects, args...)
+			if len(filter.Sort) > 0 {
+				query = applyOrder(query, filter)
+			} else {
+				query = query.Order("rank DESC, score DESC, songs.id")
+			}
+			if filter.Limit > 0 {
+				query = query.Limit(filter.Limit)
+			}
+			if err := query.Offset(filter.Offset).Scan(&rows).Error; err != nil {
+				return err
+			}
+			for _, row := range rows {
+				results = append(results, SongSearchResult{Song: models.Song{ID: row.ID}, Rank: row.Rank, Snippet: row.Snippet, Score: row.Score})
+			}
+			if len(filter.Sort) > 0 && filter.Cursor != nil && filter.Cursor.Backward {
+				slices.Reverse(results)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if results, err = r.searchInProcess(filter); err != nil {
+			return nil, err
+		}
+		results = rankSearchResults(results, filter)
+	}
+
+	ids := make([]int, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.Song.ID)
+	}
+	songs, err := findSongs(r.db, ids)
+	if err != nil {
+		return nil, err
+	}
+	found := results[:0]
+	for _, result := range results {
+		if song, ok := songs[result.Song.ID]; ok {
+			result.Song = song
+			found = append(found, result)
+		}
+	}
+	return found, nil
+}
+
+// searchSelect возвращает список столбцов id, rank, snippet и score для поиска в PostgreSQL
+// и именованные параметры к нему.
+func searchSelect(filter SongFilter) (string, []any) {
+	var args []any
+	selects := "songs.id, 0 AS rank, '' AS snippet"
+	if filter.Query != "" {
+		selects = "songs.id, ts_rank(songs.search_vector, " + songSearchQuery + ", 32) AS rank, " + songSearchSnippet + " AS snippet"
+		args = append(args, sql.Named("q", filter.Query))
+	}
+
+	var scores []string
+	if filter.Fuzzy && filter.Song != "" {
+		scores = append(scores, "similarity(songs.song, @song)")
+		args = append(args, sql.Named("song", filter.Song))
+	}
+	if filter.Fuzzy && filter.Group != "" {
+		scores = append(scores, "similarity(groups.normalized_name, @group)")
+		args = append(args, sql.Named("group", NormalizeName(filter.Group)))
+	}
+	if len(scores) == 0 {
+		return selects + ", 0 AS score", args
+	}
+	return selects + fmt.Sprintf(", (%s) / %d AS score", strings.Join(scores, " + "), len(scores)), args
+}
+
+// withSimilarity выполняет fn в транзакции, в которой оператор % из pg_trgm сравнивает
+// названия с порогом сходства фильтра. Без нечёткого сравнения транзакция не нужна.
+func (r *gormSongRepository) withSimilarity(filter SongFilter, fn func(tx *gorm.DB) error) error {
+	if !r.nativeSearch || !filter.Fuzzy {
+		return fn(r.db)
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		threshold := strconv.FormatFloat(similarityThreshold(filter), 'f', -1, 64)
+		if err := tx.Exec("SELECT set_config('pg_trgm.similarity_threshold', ?, true)", threshold).Error; err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// searchInProcess ищет песни по filter.Query и нечётким названиям в приложении среди песен,
+// подходящих под остальные условия фильтра (см. songMatcher). Результаты не упорядочены и не разбиты на страницы.
+func (r *gormSongRepository) searchInProcess(filter SongFilter) ([]SongSearchResult, error) {
+	matcher := newSongMatcher(filter)
+	filter.Query = ""
+	if filter.Fuzzy {
+		filter.Fuzzy, filter.Song, filter.Group = false, "", ""
+	}
+
+	var candidates []models.Song
+	query := r.applyFilter(r.db.Model(&models.Song{}).Joins("Group").Joins("Album"), filter)
+	if err := query.Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	var results []SongSearchResult
+	for _, song := range candidates {
+		if result, ok := matcher.match(song); ok {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// findSongs загружает песни по ID вместе с группой, альбомом, жанрами и тегами.
+func findSongs(db *gorm.DB, ids []int) (map[int]models.Song, error) {
+	songs := make(map[int]models.Song, len(ids))
+	if len(ids) == 0 {
+		return songs, nil
+	}
+	var found []models.Song
+	if err := withSongAssociations(db).Where("songs.id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, err
+	}
+	for _, song := range found {
+		songs[song.ID] = song
+	}
+	return songs, nil
+}
+
+// termSongIDs возвращает подзапрос ID песен, у которых есть хотя бы один (или при all — каждый)
+// из жанров или тегов names. joinTable, termTable и column задают таблицу связей, справочник
+// и столбец связи со справочником.
+func (r *gormSongRepository) termSongIDs(joinTable, termTable, column string, names []string, all bool) *gorm.DB {
+	query := r.db.Table(joinTable).
+		Select(joinTable+".song_id").
+		Joins(fmt.Sprintf("JOIN %s ON %s.id = %s.%s", termTable, termTable, joinTable, column)).
+		Where(termTable+".normalized_name IN ?", names)
+	if all {
+		query = query.Group(joinTable+".song_id").Having("COUNT(*) = ?", len(names))
+	}
+	return query
+}
+
+func (r *gormSongRepository) Update(song *models.Song, author string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var old models.Song
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&old, song.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Save(song).Error; err != nil {
+			return err
+		}
+		if old.Text != song.Text {
+			if err := replaceSections(tx, song.ID, lyrics.Detect(song.Text)); err != nil {
+				return err
+			}
+		}
+		return recordRevision(tx, &old, *song, author)
+	})
+}
+
+func (r *gormSongRepository) SaveEnrichment(id int, result EnrichmentResult, author string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var old models.Song
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&old, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		song := old
+		result.apply(&song)
+
+		updates := map[string]any{
+			"enrichment_status":   song.EnrichmentStatus,
+			"enrichment_attempts": song.EnrichmentAttempts,
+			"enrichment_error":    song.EnrichmentError,
+			"enrichment_next_at":  song.EnrichmentNextAt,
+		}
+		if !song.ReleaseDate.Equal(old.ReleaseDate) {
+			updates["release_date"] = song.ReleaseDate
+		}
+		if song.Text != old.Text {
+			updates["text"] = song.Text
+		}
+		if song.Link != old.Link {
+			updates["link"] = song.Link
+		}
+		if err := tx.Model(&models.Song{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+		if song.Text != old.Text {
+			if err := replaceSections(tx, id, lyrics.Detect(song.Text)); err != nil {
+				return err
+			}
+		}
+		return recordRevision(tx, &old, song, author)
+	})
+}
+
+func (r *gormSongRepository) ResetEnrichment(id int) (*models.Song, error) {
+	var song models.Song
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Song{}).Where("id = ?", id).Updates(map[string]any{
+			"enrichment_status":   models.EnrichmentPending,
+			"enrichment_attempts": 0,
+			"enrichment_error":    "",
+			"enrichment_next_at":  nil,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return tx.First(&song, id).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &song, nil
+}
+
+func (r *gormSongRepository) Delete(id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return trashSong(tx, id)
+	})
+}
+
+func (r *gormSongRepository) DeleteIf(id int, match func(song models.Song) bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Блокировка строки не даёт изменить песню между проверкой и удалением.
+		if err := lockSong(tx, id); err != nil {
+			return err
+		}
+		var song models.Song
+		if err := withSongAssociations(tx).First(&song, "songs.id = ?", id).Error; err != nil {
+			return err
+		}
+		if !match(song) {
+			return ErrPreconditionFailed
+		}
+		return trashSong(tx, id)
+	})
+}
+
+// lockSong блокирует строку песни до конца транзакции (в PostgreSQL — SELECT ... FOR UPDATE;
+// SQLite блокирует всю базу на запись). Возвращает ErrNotFound, если песни нет.
+func lockSong(tx *gorm.DB, id int) error {
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Song{}, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// trashSong перемещает песню в корзину и убирает её из плейлистов, запоминая её места в них.
+func trashSong(tx *gorm.DB, id int) error {
+	// Время удаления записывается в UTC, как и остальные моменты времени, чтобы
+	// PurgeTrash одинаково сравнивал его в PostgreSQL и SQLite.
+	result := tx.Model(&models.Song{}).Where("id = ?", id).Update("deleted_at", time.Now().UTC())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	var items []models.PlaylistItem
+	if err := tx.Where("song_id = ?", id).Order("playlist_id, position").Find(&items).Error; err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	trashed := make([]models.TrashedPlaylistItem, len(items))
+	var playlistIDs []int
+	for i, item := range items {
+		trashed[i] = models.TrashedPlaylistItem{SongID: id, PlaylistID: item.PlaylistID, Position: item.Position}
+		if !slices.Contains(playlistIDs, item.PlaylistID) {
+			playlistIDs = append(playlistIDs, item.PlaylistID)
+		}
+	}
+	if err := tx.Create(&trashed).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("song_id = ?", id).Delete(&models.PlaylistItem{}).Error; err != nil {
+		return err
+	}
+	for _, playlistID := range playlistIDs {
+		items, err := loadPlaylistItems(tx, playlistID)
+		if err != nil {
+			return err
+		}
+		if err := savePlaylistPositions(tx, items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *gormSongRepository) ListTrash(limit, offset int) ([]models.Song, error) {
+	var songs []models.Song
+	query := withSongAssociations(r.db.Unscoped()).
+		Where("songs.deleted_at IS NOT NULL").
+		Order("songs.deleted_at DESC, songs.id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Offset(offset).Find(&songs).Error
+	return songs, err
+}
+
+func (r *gormSongRepository) Restore(id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&models.Song{}).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		// Места восстанавливаются по возрастанию позиций, чтобы песня, встречавшаяся
+		// в плейлисте несколько раз, заняла те же позиции, что и до удаления.
+		var trashed []models.TrashedPlaylistItem
+		if err := tx.Where("song_id = ?", id).Order("position, id").Find(&trashed).Error; err != nil {
+			return err
+		}
+		for _, place := range trashed {
+			items, err := loadPlaylistItems(tx, place.PlaylistID)
+			if err != nil {
+				return err
+			}
+			item := models.PlaylistItem{PlaylistID: place.PlaylistID, SongID: id, Position: len(items) + 1}
+			if err := tx.Create(&item).Error; err != nil {
+				return err
+			}
+			if err := savePlaylistPositions(tx, insertItem(items, item, place.Position)); err != nil {
+				return err
+			}
+		}
+		return tx.Where("song_id = ?", id).Delete(&models.TrashedPlaylistItem{}).Error
+	})
+}
+
+func (r *gormSongRepository) Purge(id int) error {
+	result := r.db.Unscoped().Where("deleted_at IS NOT NULL").Delete(&models.Song{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormSongRepository) PurgeTrash(before time.Time) (int, error) {
+	result := r.db.Unscoped().Where("deleted_at < ?", before).Delete(&models.Song{})
+	return int(result.RowsAffected), result.Error
+}
+
+func (r *gormSongRepository) ListDueForEnrichment(now time.Time, limit int) ([]int, error) {
+	var ids []int
+	err := r.db.Model(&models.Song{}).
+		Where("enrichment_status = ?", models.EnrichmentPending).
+		Where("enrichment_next_at IS NULL OR enrichment_next_at <= ?", now).
+		Order("id").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *gormSongRepository) GetLyrics(songID int) ([]models.LyricsSection, error) {
+	var song models.Song
+	if err := r.db.Select("id", "text").First(&song, songID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	var sections []models.LyricsSection
+	if err := r.db.Where("song_id = ?", songID).Order("position").Find(&sections).Error; err != nil {
+		return nil, err
+	}
+	// Песни, добавленные до появления разделов, разбираются при чтении.
+	if len(sections) == 0 {
+		sections = songSections(songID, lyrics.Detect(song.Text))
+	}
+	return sections, nil
+}
+
+func (r *gormSongRepository) SetLyrics(songID int, sections []models.LyricsSection, author string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var old models.Song
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&old, songID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		sections = songSections(songID, sections)
+		song := old
+		song.Text = lyrics.Render(sections)
+		if err := tx.Model(&song).Update("text", song.Text).Error; err != nil {
+			return err
+		}
+		if err := replaceSections(tx, songID, sections); err != nil {
+			return err
+		}
+		return recordRevision(tx, &old, song, author)
+	})
+}
+
+func (r *gormSongRepository) ListRevisions(songID int) ([]models.SongRevision, error) {
+	if err := r.db.Select("id").First(&models.Song{}, songID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	var revisions []models.SongRevision
+	err := r.db.Where("song_id = ?", songID).Order("number").Find(&revisions).Error
+	return revisions, err
+}
+
+func (r *gormSongRepository) GetRevision(songID, number int) (*models.SongRevision, error) {
+	var revision models.SongRevision
+	err := r.db.Where("song_id = ? AND number = ?", songID, number).First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+func (r *gormSongRepository) GetSyncedLyrics(songID int) ([]models.LyricsLine, error) {
+	if err := r.db.Select("id").First(&models.Song{}, songID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return loadSyncedLyrics(r.db, songID)
+}
+
+// loadSyncedLyrics загружает строки синхронизированного текста песни со словами.
+func loadSyncedLyrics(tx *gorm.DB, songID int) ([]models.LyricsLine, error) {
+	var lines []models.LyricsLine
+	if err := tx.Where("song_id = ?", songID).Order("position").Find(&lines).Error; err != nil {
+		return nil, err
+	}
+	var words []models.LyricsWord
+	if err := tx.Where("song_id = ?", songID).Order("line, position").Find(&words).Error; err != nil {
+		return nil, err
+	}
+	for _, word := range words {
+		if word.Line >= 1 && word.Line <= len(lines) {
+			lines[word.Line-1].Words = append(lines[word.Line-1].Words, word)
+		}
+	}
+	return lines, nil
+}
+
+func (r *gormSongRepository) SetSyncedLyrics(songID int, lines []models.LyricsLine, author string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return changeAssociations(tx, songID, author, func() error {
+			if err := tx.Where("song_id = ?", songID).Delete(&models.LyricsWord{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("song_id = ?", songID).Delete(&models.LyricsLine{}).Error; err != nil {
+				return err
+			}
+
+			lines = songLines(songID, lines)
+			if len(lines) == 0 {
+				return nil
+			}
+			if err := tx.Create(&lines).Error; err != nil {
+				return err
+			}
+			var words []models.LyricsWord
+			for _, line := range lines {
+				words = append(words, line.Words...)
+			}
+			if len(words) == 0 {
+				return nil
+			}
+			return tx.Create(&words).Error
+		})
+	})
+}
+
+func (r *gormSongRepository) ListTranslations(songID int) ([]models.SongTranslation, error) {
+	if err := r.db.Select("id").First(&models.Song{}, songID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	var translations []models.SongTranslation
+	err := r.db.Where("song_id = ?", songID).Order("language").Find(&translations).Error
+	return translations, err
+}
+
+func (r *gormSongRepository) SetTranslation(songID int, language, text, author string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return changeAssociations(tx, songID, author, func() error {
+			result := tx.Model(&models.SongTranslation{}).
+				Where("song_id = ? AND language = ?", songID, language).
+				Update("text", text)
+			if result.Error != nil || result.RowsAffected > 0 {
+				return result.Error
+			}
+			return tx.Create(&models.SongTranslation{SongID: songID, Language: language, Text: text}).Error
+		})
+	})
+}
+
+func (r *gormSongRepository) DeleteTranslation(songID int, language, author string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return changeAssociations(tx, songID, author, func() error {
+			result := tx.Where("song_id = ? AND language = ?", songID, language).Delete(&models.SongTranslation{})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrNotFound
+			}
+			return nil
+		})
+	})
+}
+
+// recordRevision записывает ревизию песни после изменения её полей. old — состояние до
+// изменения, nil для новой песни. Строка песни должна быть заблокирована в tx (новая песня
+// блокируется вставкой): иначе параллельные изменения получат один и тот же номер ревизии.
+func recordRevision(tx *gorm.DB, old *models.Song, song models.Song, author string) error {
+	associations, err := loadAssociations(tx, song.ID)
+	if err != nil {
+		return err
+	}
+	var from map[string]string
+	if old != nil {
+		from = revisionFields(*old, associations)
+	}
+	return saveRevision(tx, song.ID, from, revisionFields(song, associations), author)
+}
+
+// changeAssociations блокирует строку песни songID, выполняет change и записывает ревизию,
+// если изменились жанры, теги, синхронизированный текст или переводы песни. Возвращает
+// ErrNotFound, если песни нет.
+func changeAssociations(tx *gorm.DB, songID int, author string, change func() error) error {
+	var song models.Song
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&song, songID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	before, err := loadAssociations(tx, songID)
+	if err != nil {
+		return err
+	}
+	if err := change(); err != nil {
+		return err
+	}
+	after, err := loadAssociations(tx, songID)
+	if err != nil {
+		return err
+	}
+	return saveRevision(tx, songID, revisionFields(song, before), revisionFields(song, after), author)
+}
+
+// loadAssociations загружает данные песни, которые записываются в ревизии вне её строки.
+func loadAssociations(tx *gorm.DB, songID int) (songAssociations, error) {
+	var associations songAssociations
+	if err := tx.Model(&models.SongGenre{}).Where("song_id = ?", songID).Pluck("genre_id", &associations.GenreIDs).Error; err != nil {
+		return associations, err
+	}
+	if err := tx.Model(&models.SongTag{}).Where("song_id = ?", songID).Pluck("tag_id", &associations.TagIDs).Error; err != nil {
+		return associations, err
+	}
+	lines, err := loadSyncedLyrics(tx, songID)
+	if err != nil {
+		return associations, err
+	}
+	associations.SyncedLyrics = lines
+
+	var translations []models.SongTranslation
+	if err := tx.Where("song_id = ?", songID).Find(&translations).Error; err != nil {
+		return associations, err
+	}
+	if len(translations) > 0 {
+		associations.Translations = make(map[string]string, len(translations))
+		for _, translation := range translations {
+			associations.Translations[translation.Language] = translation.Text
+		}
+	}
+	return associations, nil
+}
+
+// saveRevision записывает ревизию песни songID со значениями полей to. from — значения до
+// изменения, nil для новой песни. Если поля не изменились, ревизия не записывается. Для
+// песни, добавленной до появления ревизий, сначала записывается исходное состояние.
+func saveRevision(tx *gorm.DB, songID int, from, to map[string]string, author string) error {
+	var last models.SongRevision
+	if err := tx.Where("song_id = ?", songID).Order("number DESC").Limit(1).Find(&last).Error; err != nil {
+		return err
+	}
+	created := from == nil
+	if created {
+		from = revisionFields(models.Song{}, songAssociations{})
+	}
+	changes := DiffFields(from, to)
+	if !created && len(changes) == 0 {
+		return nil
+	}
+
+	number := last.Number
+	if number == 0 && !created {
+		number++
+		baseline := models.SongRevision{SongID: songID, Number: number, Fields: from, Changes: []models.FieldChange{}, CreatedAt: time.Now().UTC()}
+		if err := tx.Create(&baseline).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Create(&models.SongRevision{
+		SongID:    songID,
+		Number:    number + 1,
+		Author:    author,
+		CreatedAt: time.Now().UTC(),
+		Fields:    to,
+		Changes:   changes,
+	}).Error
+}
+
+// replaceSections заменяет разделы текста песни songID.
+func replaceSections(tx *gorm.DB, songID int, sections []models.LyricsSection) error {
+	if err := tx.Where("song_id = ?", songID).Delete(&models.LyricsSection{}).Error; err != nil {
+		return err
+	}
+	sections = songSections(songID, sections)
+	if len(sections) == 0 {
+		return nil
+	}
+	return tx.Create(&sections).Error
+}
+
+func (r *gormSongRepository) SetGenres(songID int, genreIDs []int, author string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return changeAssociations(tx, songID, author, func() error {
+			if err := tx.Where("song_id = ?", songID).Delete(&models.SongGenre{}).Error; err != nil {
+				return err
+			}
+
+			links := make([]models.SongGenre, 0, len(genreIDs))
+			for _, id := range uniqueIDs(genreIDs) {
+				links = append(links, models.SongGenre{SongID: songID, GenreID: id})
+			}
+			if len(links) == 0 {
+				return nil
+			}
+			return tx.Create(&links).Error
+		})
+	})
+}
+
+func (r *gormSongRepository) SetTags(songID int, tagIDs []int, author string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return changeAssociations(tx, songID, author, func() error {
+			if err := tx.Where("song_id = ?", songID).Delete(&models.SongTag{}).Error; err != nil {
+				return err
+			}
+
+			links := make([]models.SongTag, 0, len(tagIDs))
+			for _, id := range uniqueIDs(tagIDs) {
+				links = append(links, models.SongTag{SongID: songID, TagID: id})
+			}
+			if len(links) == 0 {
+				return nil
+			}
+			return tx.Create(&links).Error
+		})
+	})
+}
+
+func (r *gormSongRepository) Facets(filter SongFilter) (*SongFacets, error) {
+	var facets *SongFacets
+	err := r.withSimilarity(filter, func(tx *gorm.DB) error {
+		var err error
+		facets, err = r.facets(tx, filter)
+		return err
+	})
+	return facets, err
+}
+
+func (r *gormSongRepository) facets(tx *gorm.DB, filter SongFilter) (*SongFacets, error) {
+	var total int64
+	if err := r.applyFilter(tx.Model(&models.Song{}), filter).Count(&total).Error; err != nil {
+		return nil, err
+	}
+	facets := &SongFacets{Total: int(total)}
+
+	songIDs := r.applyFilter(tx.Model(&models.Song{}), filter).Select("songs.id")
+	err := tx.Table("song_genres").
+		Select("genres.id, genres.name, COUNT(*) AS song_count").
+		Joins("JOIN genres ON genres.id = song_genres.genre_id").
+		Where("song_genres.song_id IN (?)", songIDs).
+		Group("genres.id, genres.name").
+		Order("song_count DESC, genres.name").
+		Scan(&facets.Genres).Error
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Table("song_tags").
+		Select("tags.id, tags.name, COUNT(*) AS song_count").
+		Joins("JOIN tags ON tags.id = song_tags.tag_id").
+		Where("song_tags.song_id IN (?)", songIDs).
+		Group("tags.id, tags.name").
+		Order("song_count DESC, tags.name").
+		Scan(&facets.Tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return facets, nil
+}
+
+// uniqueIDs возвращает ID без повторов в исходном порядке.
+func uniqueIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	var unique []int
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+type gormGroupRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormGroupRepository) FindOrCreate(name string) (*models.Group, error) {
+	return findOrCreateGroup(r.db, name)
+}
+
+// findOrCreateGroup возвращает группу с таким названием или псевдонимом либо создаёт её в tx.
+func findOrCreateGroup(tx *gorm.DB, name string) (*models.Group, error) {
+	group, err := findGroup(tx, name)
+	if !errors.Is(err, ErrNotFound) {
+		return group, err
+	}
+
+	// Группу с тем же названием мог создать параллельный запрос: уникальный индекс по
+	// normalized_name не даёт создать вторую, и возвращается уже созданная.
+	group = &models.Group{Name: strings.TrimSpace(name), NormalizedName: NormalizeName(name)}
+	result := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "normalized_name"}}, DoNothing: true}).Create(group)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return findGroup(tx, name)
+	}
+	return group, nil
+}
+
+func (r *gormGroupRepository) GetByID(id int) (*models.Group, error) {
+	var group models.Group
+	err := r.db.First(&group, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *gormGroupRepository) FindByName(name string) (*models.Group, error) {
+	return findGroup(r.db, name)
+}
+
+// findGroup ищет группу с наименьшим ID по нормализованному названию, затем по псевдонимам.
+func findGroup(tx *gorm.DB, name string) (*models.Group, error) {
+	normalized := NormalizeName(name)
+
+	var group models.Group
+	err := tx.Where("normalized_name = ?", normalized).Order("id").First(&group).Error
+	if err == nil {
+		return &group, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	err = tx.Joins("JOIN group_aliases ON group_aliases.group_id = groups.id").
+		Where("group_aliases.normalized_name = ?", normalized).
+		First(&group).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *gormGroupRepository) List(limit, offset int) ([]GroupWithSongCount, error) {
+	var groups []GroupWithSongCount
+	query := r.db.Model(&models.Group{}).
+		Select("groups.id, groups.name, COUNT(songs.id) AS song_count").
+		Joins("LEFT JOIN songs ON songs.group_id = groups.id AND songs.deleted_at IS NULL").
+		Group("groups.id, groups.name").
+		Order("groups.id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Offset(offset).Scan(&groups).Error
+	return groups, err
+}
+
+func (r *gormGroupRepository) Update(group *models.Group) error {
+	group.NormalizedName = NormalizeName(group.Name)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var taken int64
+		err := tx.Model(&models.Group{}).
+			Where("normalized_name = ? AND id <> ?", group.NormalizedName, group.ID).
+			Count(&taken).Error
+		if err != nil {
+			return err
+		}
+		if taken == 0 {
+			err = tx.Model(&models.GroupAlias{}).
+				Where("normalized_name = ? AND group_id <> ?", group.NormalizedName, group.ID).
+				Count(&taken).Error
+			if err != nil {
+				return err
+			}
+		}
+		if taken > 0 {
+			return ErrNameConflict
+		}
+
+		result := tx.Model(group).Updates(map[string]any{
+			"name":            group.Name,
+			"normalized_name": group.NormalizedName,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *gormGroupRepository) Delete(id int, deleteSongs bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var group models.Group
+		if err := tx.First(&group, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		// Песни из корзины тоже считаются: без группы их нельзя восстановить, поэтому
+		// удалить их молча нельзя.
+		var songCount int64
+		if err := tx.Unscoped().Model(&models.Song{}).Where("group_id = ?", id).Count(&songCount).Error; err != nil {
+			return err
+		}
+		if songCount > 0 && !deleteSongs {
+			return ErrGroupHasSongs
+		}
+		// Песни удаляются окончательно, а не в корзину: songs.group_id ссылается на группу,
+		// и песню удалённой группы нельзя ни хранить, ни восстановить.
+		if err := tx.Unscoped().Where("group_id = ?", id).Delete(&models.Song{}).Error; err != nil {
+			return err
+		}
+
+		albums := tx.Model(&models.Album{}).Select("id").Where("group_id = ?", id)
+		if err := detachAlbumSongs(tx, albums); err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&models.Album{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&group).Error
+	})
+}
+
+func (r *gormGroupRepository) ListAliases(groupID int) ([]models.GroupAlias, error) {
+	var aliases []models.GroupAlias
+	err := r.db.Where("group_id = ?", groupID).Order("id").Find(&aliases).Error
+	return aliases, err
+}
+
+func (r *gormGroupRepository) AddAlias(groupID int, name string) (*models.GroupAlias, error) {
+	alias := &models.GroupAlias{
+		GroupID:        groupID,
+		Name:           strings.TrimSpace(name),
+		NormalizedName: NormalizeName(name),
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&models.Group{}, groupID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		var taken int64
+		err := tx.Model(&models.Group{}).Where("normalized_name = ?", alias.NormalizedName).Count(&taken).Error
+		if err != nil {
+			return err
+		}
+		if taken == 0 {
+			err = tx.Model(&models.GroupAlias{}).Where("normalized_name = ?", alias.NormalizedName).Count(&taken).Error
+			if err != nil {
+				return err
+			}
+		}
+		if taken > 0 {
+			return ErrNameConflict
+		}
+		return tx.Create(alias).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return alias, nil
+}
+
+func (r *gormGroupRepository) DeleteAlias(groupID, aliasID int) error {
+	result := r.db.Where("group_id = ?", groupID).Delete(&models.GroupAlias{}, aliasID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormGroupRepository) Merge(targetID int, sourceIDs []int, author string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var target models.Group
+		if err := tx.First(&target, targetID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		var sources []models.Group
+		if err := tx.Where("id IN ?", sourceIDs).Find(&sources).Error; err != nil {
+			return err
+		}
+		if len(sources) != len(sourceIDs) {
+			return ErrNotFound
+		}
+
+		// Перенос затрагивает и песни в корзине, чтобы их можно было восстановить.
+		var moved []models.Song
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("group_id IN ?", sourceIDs).Order("id").Find(&moved).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Song{}).Where("group_id IN ?", sourceIDs).Update("group_id", targetID).Error; err != nil {
+			return err
+		}
+		for i := range moved {
+			song := moved[i]
+			song.GroupID = targetID
+			if err := recordRevision(tx, &moved[i], song, author); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.Album{}).Where("group_id IN ?", sourceIDs).Update("group_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.GroupAlias{}).Where("group_id IN ?", sourceIDs).Update("group_id", targetID).Error; err != nil {
+			return err
+		}
+
+		for _, source := range sources {
+			normalized := NormalizeName(source.Name)
+			if normalized == target.NormalizedName {
+				continue
+			}
+			alias := models.GroupAlias{GroupID: targetID, Name: strings.TrimSpace(source.Name), NormalizedName: normalized}
+			err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "normalized_name"}}, DoNothing: true}).
+				Create(&alias).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Where("id IN ?", sourceIDs).Delete(&models.Group{}).Error
+	})
+}
+
+type gormAlbumRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormAlbumRepository) Create(album *models.Album) error {
+	album.NormalizedTitle = NormalizeName(album.Title)
+	return r.db.Omit(clause.Associations).Create(album).Error
+}
+
+func (r *gormAlbumRepository) GetByID(id int) (*models.Album, error) {
+	var album models.Album
+	err := r.db.Joins("Group").First(&album, "albums.id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &album, nil
+}
+
+func (r *gormAlbumRepository) List(filter AlbumFilter) ([]AlbumWithTrackCount, error) {
+	var albums []AlbumWithTrackCount
+	query := r.db.Model(&models.Album{}).
+		Select("albums.id, albums.group_id, groups.name AS group_name, albums.title, albums.release_date, albums.cover_link, COUNT(songs.id) AS track_count").
+		Joins("JOIN groups ON groups.id = albums.group_id").
+		Joins("LEFT JOIN songs ON songs.album_id = albums.id AND songs.deleted_at IS NULL").
+		Group("albums.id, albums.group_id, groups.name, albums.title, albums.release_date, albums.cover_link").
+		Order("albums.id")
+	if filter.GroupID != 0 {
+		query = query.Where("albums.group_id = ?", filter.GroupID)
+	}
+	if filter.Group != "" {
+		query = query.Where("groups.normalized_name = ?", NormalizeName(filter.Group))
+	}
+	if filter.Title != "" {
+		query = query.Where("albums.normalized_title = ?", NormalizeName(filter.Title))
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	err := query.Offset(filter.Offset).Scan(&albums).Error
+	return albums, err
+}
+
+func (r *gormAlbumRepository) Update(album *models.Album) error {
+	album.NormalizedTitle = NormalizeName(album.Title)
+	result := r.db.Model(album).Omit(clause.Associations).Updates(map[string]any{
+		"title":            album.Title,
+		"normalized_title": album.NormalizedTitle,
+		"release_date":     album.ReleaseDate,
+		"cover_link":       album.CoverLink,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormAlbumRepository) Delete(id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := detachAlbumSongs(tx, []int{id}); err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Album{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *gormAlbumRepository) ListTracks(albumID int) ([]models.Song, error) {
+	var songs []models.Song
+	err := withSongAssociations(r.db).
+		Where("songs.album_id = ?", albumID).
+		Order("songs.disc_number, songs.track_number, songs.id").
+		Find(&songs).Error
+	return songs, err
+}
+
+// detachAlbumSongs отвязывает от альбомов albumIDs (список ID или подзапрос) их песни.
+func detachAlbumSongs(tx *gorm.DB, albumIDs any) error {
+	return tx.Unscoped().Model(&models.Song{}).Where("album_id IN (?)", albumIDs).Updates(map[string]any{
+		"album_id":     nil,
+		"disc_number":  0,
+		"track_number": 0,
+	}).Error
+}
+
+type gormGenreRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormGenreRepository) Create(name string) (*models.Genre, error) {
+	genre := &models.Genre{Name: strings.TrimSpace(name), NormalizedName: NormalizeName(name)}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var taken int64
+		if err := tx.Model(&models.Genre{}).Where("normalized_name = ?", genre.NormalizedName).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return ErrNameConflict
+		}
+		return tx.Create(genre).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return genre, nil
+}
+
+func (r *gormGenreRepository) GetByID(id int) (*models.Genre, error) {
+	var genre models.Genre
+	err := r.db.First(&genre, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &genre, nil
+}
+
+func (r *gormGenreRepository) FindByNames(names []string) ([]models.Genre, error) {
+	var genres []models.Genre
+	normalized := normalizeNames(names)
+	if len(normalized) == 0 {
+		return genres, nil
+	}
+	err := r.db.Where("normalized_name IN ?", normalized).Order("name").Find(&genres).Error
+	return genres, err
+}
+
+func (r *gormGenreRepository) List() ([]TermCount, error) {
+	var genres []TermCount
+	err := r.db.Model(&models.Genre{}).
+		Select("genres.id, genres.name, COUNT(songs.id) AS song_count").
+		Joins("LEFT JOIN song_genres ON song_genres.genre_id = genres.id").
+		Joins("LEFT JOIN songs ON songs.id = song_genres.song_id AND songs.deleted_at IS NULL").
+		Group("genres.id, genres.name").
+		Order("genres.name").
+		Scan(&genres).Error
+	return genres, err
+}
+
+func (r *gormGenreRepository) Update(genre *models.Genre) error {
+	genre.Name = strings.TrimSpace(genre.Name)
+	genre.NormalizedName = NormalizeName(genre.Name)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var taken int64
+		err := tx.Model(&models.Genre{}).
+			Where("normalized_name = ? AND id <> ?", genre.NormalizedName, genre.ID).
+			Count(&taken).Error
+		if err != nil {
+			return err
+		}
+		if taken > 0 {
+			return ErrNameConflict
+		}
+
+		result := tx.Model(genre).Updates(map[string]any{
+			"name":            genre.Name,
+			"normalized_name": genre.NormalizedName,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *gormGenreRepository) Delete(id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("genre_id = ?", id).Delete(&models.SongGenre{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Genre{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+type gormTagRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormTagRepository) FindOrCreate(names []string) ([]models.Tag, error) {
+	var tags []models.Tag
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		seen := make(map[string]bool, len(names))
+		for _, name := range names {
+			normalized := NormalizeName(name)
+			if normalized == "" || seen[normalized] {
+				continue
+			}
+			seen[normalized] = true
+
+			tag := models.Tag{Name: strings.TrimSpace(name), NormalizedName: normalized}
+			err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "normalized_name"}}, DoNothing: true}).
+				Create(&tag).Error
+			if err != nil {
+				return err
+			}
+			if err := tx.Where("normalized_name = ?", normalized).First(&tag).Error; err != nil {
+				return err
+			}
+			tags = append(tags, tag)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *gormTagRepository) List(limit, offset int) ([]TermCount, error) {
+	var tags []TermCount
+	query := r.db.Model(&models.Tag{}).
+		Select("tags.id, tags.name, COUNT(songs.id) AS song_count").
+		Joins("LEFT JOIN song_tags ON song_tags.tag_id = tags.id").
+		Joins("LEFT JOIN songs ON songs.id = song_tags.song_id AND songs.deleted_at IS NULL").
+		Group("tags.id, tags.name").
+		Order("song_count DESC, tags.name")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Offset(offset).Scan(&tags).Error
+	return tags, err
+}
+
+func (r *gormTagRepository) Delete(id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", id).Delete(&models.SongTag{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Tag{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+type gormPlaylistRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormPlaylistRepository) Create(playlist *models.Playlist) error {
+	return r.db.Create(playlist).Error
+}
+
+func (r *gormPlaylistRepository) GetByID(id int) (*models.Playlist, error) {
+	var playlist models.Playlist
+	err := r.db.First(&playlist, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &playlist, nil
+}
+
+func (r *gormPlaylistRepository) List(limit, offset int) ([]PlaylistWithSongCount, error) {
+	var playlists []PlaylistWithSongCount
+	query := r.db.Model(&models.Playlist{}).
+		Select("playlists.id, playlists.name, playlists.description, COUNT(playlist_items.id) AS song_count").
+		Joins("LEFT JOIN playlist_items ON playlist_items.playlist_id = playlists.id").
+		Group("playlists.id, playlists.name, playlists.description").
+		Order("playlists.id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Offset(offset).Scan(&playlists).Error
+	return playlists, err
+}
+
+func (r *gormPlaylistRepository) Update(playlist *models.Playlist) error {
+	result := r.db.Model(playlist).Updates(map[string]any{
+		"name":        playlist.Name,
+		"description": playlist.Description,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormPlaylistRepository) Delete(id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("playlist_id = ?", id).Delete(&models.PlaylistItem{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Playlist{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *gormPlaylistRepository) ListSongs(playlistID, limit, offset int) ([]models.Song, error) {
+	var items []models.PlaylistItem
+	query := r.db.Where("playlist_id = ?", playlistID).Order("position, id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Offset(offset).Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.SongID)
+	}
+	byID, err := findSongs(r.db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	songs := make([]models.Song, 0, len(items))
+	for _, item := range items {
+		if song, ok := byID[item.SongID]; ok {
+			songs = append(songs, song)
+		}
+	}
+	return songs, nil
+}
+
+func (r *gormPlaylistRepository) AddSong(playlistID, songID, position int) error {
+	return r.modifyItems(playlistID, func(tx *gorm.DB, items []models.PlaylistItem) ([]models.PlaylistItem, error) {
+		if err := tx.Select("id").First(&models.Song{}, songID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrNotFound
+			}
+			return nil, err
+		}
+
+		item := models.PlaylistItem{PlaylistID: playlistID, SongID: songID, Position: len(items) + 1}
+		if err := tx.Create(&item).Error; err != nil {
+			return nil, err
+		}
+		return insertItem(items, item, position), nil
+	})
+}
+
+func (r *gormPlaylistRepository) RemoveSong(playlistID, position int) error {
+	return r.modifyItems(playlistID, func(tx *gorm.DB, items []models.PlaylistItem) ([]models.PlaylistItem, error) {
+		if position < 1 || position > len(items) {
+			return nil, ErrNotFound
+		}
+		if err := tx.Delete(&items[position-1]).Error; err != nil {
+			return nil, err
+		}
+		return append(items[:position-1], items[position:]...), nil
+	})
+}
+
+func (r *gormPlaylistRepository) MoveSong(playlistID, from, to int) error {
+	return r.modifyItems(playlistID, func(tx *gorm.DB, items []models.PlaylistItem) ([]models.PlaylistItem, error) {
+		if from < 1 || from > len(items) {
+			return nil, ErrNotFound
+		}
+		item := items[from-1]
+		return insertItem(append(items[:from-1], items[from:]...), item, to), nil
+	})
+}
+
+// modifyItems в транзакции загружает песни плейлиста по порядку, применяет к ним change
+// и сохраняет новые позиции. Строка плейлиста блокируется, чтобы параллельные изменения
+// одного плейлиста не перемешали позиции.
+func (r *gormPlaylistRepository) modifyItems(playlistID int, change func(tx *gorm.DB, items []models.PlaylistItem) ([]models.PlaylistItem, error)) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var playlist models.Playlist
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&playlist, playlistID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		items, err := loadPlaylistItems(tx, playlistID)
+		if err != nil {
+			return err
+		}
+		items, err = change(tx, items)
+		if err != nil {
+			return err
+		}
+		if err := savePlaylistPositions(tx, items); err != nil {
+			return err
+		}
+		return tx.Model(&playlist).Update("updated_at", time.Now()).Error
+	})
+}
+
+// loadPlaylistItems возвращает песни плейлиста в порядке позиций.
+func loadPlaylistItems(tx *gorm.DB, playlistID int) ([]models.PlaylistItem, error) {
+	var items []models.PlaylistItem
+	err := tx.Where("playlist_id = ?", playlistID).Order("position, id").Find(&items).Error
+	return items, err
+}
+
+// savePlaylistPositions нумерует items с 1 по порядку и сохраняет изменившиеся позиции.
+func savePlaylistPositions(tx *gorm.DB, items []models.PlaylistItem) error {
+	for i := range items {
+		if items[i].Position == i+1 {
+			continue
+		}
+		items[i].Position = i + 1
+		if err := tx.Model(&items[i]).Update("position", items[i].Position).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertItem вставляет item на позицию position (с 1); позиция вне диапазона означает конец списка.
+func insertItem(items []models.PlaylistItem, item models.PlaylistItem, position int) []models.PlaylistItem {
+	if position < 1 || position > len(items) {
+		return append(items, item)
+	}
+	items = append(items, models.PlaylistItem{})
+	copy(items[position:], items[position-1:])
+	items[position-1] = item
+	return items
+}
+
+type gormMetadataCacheRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormMetadataCacheRepository) Get(key string) (*models.MetadataCacheEntry, error) {
+	var entry models.MetadataCacheEntry
+	err := r.db.First(&entry, "key = ?", key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *gormMetadataCacheRepository) Put(entry *models.MetadataCacheEntry) error {
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(entry).Error
+}
+
+func (r *gormMetadataCacheRepository) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at <= ?", now).Delete(&models.MetadataCacheEntry{})
+	return result.RowsAffected, result.Error
+}
+
+type gormIdempotencyRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormIdempotencyRepository) Get(key string) (*models.IdempotentResponse, error) {
+	var response models.IdempotentResponse
+	err := r.db.First(&response, "key = ?", key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (r *gormIdempotencyRepository) Reserve(response *models.IdempotentResponse, now time.Time) (bool, error) {
+	// Просроченный ответ, который ещё не удалила очистка, заменяется новой резервацией.
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"request", "status", "content_type", "body", "expires_at"}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "idempotency_keys.expires_at <= ?", Vars: []any{now}}}},
+	}).Create(response)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *gormIdempotencyRepository) Complete(response *models.IdempotentResponse) error {
+	return r.db.Model(&models.IdempotentResponse{}).Where("key = ?", response.Key).Updates(map[string]any{
+		"status":       response.Status,
+		"content_type": response.ContentType,
+		"body":         response.Body,
+		"expires_at":   response.ExpiresAt,
+	}).Error
+}
+
+func (r *gormIdempotencyRepository) Release(key string) error {
+	return r.db.Where("key = ? AND status = 0", key).Delete(&models.IdempotentResponse{}).Error
+}
+
+func (r *gormIdempotencyRepository) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at <= ?", now).Delete(&models.IdempotentResponse{})
+	return result.RowsAffected, result.Error
+}
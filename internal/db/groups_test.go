@@ -0,0 +1,166 @@
+package db_test
+
+import (
+	"errors"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"sync"
+	"testing"
+)
+
+func TestDeleteGroupWithTrashedSongs(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			song := createSong(t, storage, "Queen", models.Song{Song: "Bohemian Rhapsody"})
+			if err := storage.Songs.Delete(song.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := storage.Groups.Delete(song.GroupID, false); !errors.Is(err, db.ErrGroupHasSongs) {
+				t.Fatalf("Delete без удаления песен: %v, ожидалась ErrGroupHasSongs", err)
+			}
+			if err := storage.Songs.Restore(song.ID); err != nil {
+				t.Fatalf("песня из корзины не восстанавливается: %v", err)
+			}
+			if err := storage.Songs.Delete(song.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := storage.Groups.Delete(song.GroupID, true); err != nil {
+				t.Fatal(err)
+			}
+			trash, err := storage.Songs.ListTrash(10, 0)
+			if err != nil || len(trash) != 0 {
+				t.Fatalf("корзина после удаления группы: %v, %v", trash, err)
+			}
+		})
+	}
+}
+
+func TestRenameGroupConflicts(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			queen, err := storage.Groups.FindOrCreate("Queen")
+			if err != nil {
+				t.Fatal(err)
+			}
+			kino, err := storage.Groups.FindOrCreate("Кино")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := storage.Groups.AddAlias(kino.ID, "Kino"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := storage.Groups.AddAlias(queen.ID, "Queen II"); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, tc := range []struct {
+				name string
+				want error
+			}{
+				{"  КИНО ", db.ErrNameConflict},
+				{"kino", db.ErrNameConflict},
+				{"queen  ii", nil},
+				{"QUEEN", nil},
+			} {
+				group := *queen
+				group.Name = tc.name
+				if err := storage.Groups.Update(&group); !errors.Is(err, tc.want) {
+					t.Errorf("переименование в %q: %v, ожидалось %v", tc.name, err, tc.want)
+				}
+			}
+
+			group, err := storage.Groups.GetByID(queen.ID)
+			if err != nil || group.Name != "QUEEN" {
+				t.Fatalf("группа после переименований: %+v, %v", group, err)
+			}
+			missing := models.Group{ID: 1000, Name: "Nautilus"}
+			if err := storage.Groups.Update(&missing); !errors.Is(err, db.ErrNotFound) {
+				t.Errorf("переименование несуществующей группы: %v", err)
+			}
+		})
+	}
+}
+
+func TestConcurrentFindOrCreateGroup(t *testing.T) {
+	const requests = 8
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			ids := make(chan int, requests)
+			for i := 0; i < requests; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					names := []string{"Кино", " КИНО", "кино  "}
+					group, err := storage.Groups.FindOrCreate(names[i%len(names)])
+					if err != nil {
+						t.Errorf("FindOrCreate: %v", err)
+						return
+					}
+					ids <- group.ID
+				}(i)
+			}
+			wg.Wait()
+			close(ids)
+
+			first := 0
+			for id := range ids {
+				if first == 0 {
+					first = id
+				}
+				if id != first {
+					t.Errorf("создано несколько групп: %d и %d", first, id)
+				}
+			}
+		})
+	}
+}
+
+// TestDeleteGroupWithSongsPurgesThem проверяет, что при удалении группы вместе с песнями
+// они удаляются окончательно, а не в корзину: песню без группы нельзя восстановить.
+func TestDeleteGroupWithSongsPurgesThem(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			song := createSong(t, storage, "Queen", models.Song{Song: "Innuendo", Text: "Куплет"})
+			other := createSong(t, storage, "ABBA", models.Song{Song: "Waterloo"})
+			playlist := models.Playlist{Name: "Избранное"}
+			if err := storage.Playlists.Create(&playlist); err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range []int{song.ID, other.ID} {
+				if err := storage.Playlists.AddSong(playlist.ID, id, 0); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := storage.Songs.SetTranslation(song.ID, "en", "Verse", "test"); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := storage.Groups.Delete(song.GroupID, true); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := storage.Songs.GetByID(song.ID); !errors.Is(err, db.ErrNotFound) {
+				t.Errorf("песня удалённой группы: %v, ожидалась ErrNotFound", err)
+			}
+			trash, err := storage.Songs.ListTrash(10, 0)
+			if err != nil || len(trash) != 0 {
+				t.Errorf("корзина после удаления группы: %+v, %v", trash, err)
+			}
+			if err := storage.Songs.Restore(song.ID); !errors.Is(err, db.ErrNotFound) {
+				t.Errorf("восстановление песни удалённой группы: %v, ожидалась ErrNotFound", err)
+			}
+			if _, err := storage.Songs.ListRevisions(song.ID); !errors.Is(err, db.ErrNotFound) {
+				t.Errorf("ревизии песни удалённой группы: %v, ожидалась ErrNotFound", err)
+			}
+			songs, err := storage.Playlists.ListSongs(playlist.ID, 0, 0)
+			if err != nil || len(songs) != 1 || songs[0].ID != other.ID {
+				t.Errorf("плейлист после удаления группы: %+v, %v", songs, err)
+			}
+			if _, err := storage.Songs.GetByID(other.ID); err != nil {
+				t.Errorf("песня другой группы: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"music_storage/internal/models"
+)
+
+// songSections возвращает копию разделов текста песни songID с позициями по порядку с 1.
+func songSections(songID int, sections []models.LyricsSection) []models.LyricsSection {
+	result := make([]models.LyricsSection, 0, len(sections))
+	for i, section := range sections {
+		section.ID = 0
+		section.SongID = songID
+		section.Position = i + 1
+		result = append(result, section)
+	}
+	return result
+}
+
+// songLines возвращает копию строк синхронизированного текста песни songID с позициями
+// строк и слов по порядку с 1.
+func songLines(songID int, lines []models.LyricsLine) []models.LyricsLine {
+	result := make([]models.LyricsLine, 0, len(lines))
+	for i, line := range lines {
+		line.ID = 0
+		line.SongID = songID
+		line.Position = i + 1
+		words := make([]models.LyricsWord, 0, len(line.Words))
+		for j, word := range line.Words {
+			word.ID = 0
+			word.SongID = songID
+			word.Line = line.Position
+			word.Position = j + 1
+			words = append(words, word)
+		}
+		line.Words = words
+		result = append(result, line)
+	}
+	return result
+}
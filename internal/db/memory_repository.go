@@ -0,0 +1,1591 @@
+package db
+
+import (
+	"maps"
+	"music_storage/internal/lyrics"
+	"music_storage/internal/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// memoryData хранит записи in-memory хранилища, общие для всех его репозиториев.
+type memoryData struct {
+	mu         sync.RWMutex
+	songs      map[int]models.Song
+	groups     map[int]models.Group
+	aliases    map[int]models.GroupAlias
+	albums     map[int]models.Album
+	genres     map[int]models.Genre
+	tags       map[int]models.Tag
+	songGenres map[int]map[int]bool
+	songTags   map[int]map[int]bool
+	playlists  map[int]models.Playlist
+	// playlistSongs — ID песен каждого плейлиста в порядке позиций.
+	playlistSongs map[int][]int
+	// lyrics — разделы текста каждой песни по порядку позиций.
+	lyrics map[int][]models.LyricsSection
+	// syncedLyrics — строки синхронизированного текста каждой песни по порядку позиций.
+	syncedLyrics   map[int][]models.LyricsLine
+	cache          map[string]models.MetadataCacheEntry
+	nextSongID     int
+	nextGroupID    int
+	nextAliasID    int
+	nextAlbumID    int
+	nextGenreID    int
+	nextTagID      int
+	nextPlaylistID int
+	// translations — переводы текста каждой песни по кодам языков.
+	translations map[int]map[string]string
+	// revisions — ревизии каждой песни по возрастанию номера.
+	revisions map[int][]models.SongRevision
+	// trash — песни в корзине; в songs их нет.
+	trash map[int]models.Song
+	// trashedPlaylists — места каждой песни из корзины в плейлистах на момент удаления.
+	trashedPlaylists map[int][]models.TrashedPlaylistItem
+	idempotency      map[string]models.IdempotentResponse
+}
+
+// NewMemoryStorage создаёт хранилище, которое держит все данные в памяти процесса.
+// Используется в тестах и для запуска без базы данных.
+func NewMemoryStorage() *Storage {
+	data := &memoryData{
+		songs:            make(map[int]models.Song),
+		groups:           make(map[int]models.Group),
+		aliases:          make(map[int]models.GroupAlias),
+		albums:           make(map[int]models.Album),
+		genres:           make(map[int]models.Genre),
+		tags:             make(map[int]models.Tag),
+		songGenres:       make(map[int]map[int]bool),
+		songTags:         make(map[int]map[int]bool),
+		playlists:        make(map[int]models.Playlist),
+		playlistSongs:    make(map[int][]int),
+		lyrics:           make(map[int][]models.LyricsSection),
+		syncedLyrics:     make(map[int][]models.LyricsLine),
+		translations:     make(map[int]map[string]string),
+		revisions:        make(map[int][]models.SongRevision),
+		cache:            make(map[string]models.MetadataCacheEntry),
+		trash:            make(map[int]models.Song),
+		trashedPlaylists: make(map[int][]models.TrashedPlaylistItem),
+		idempotency:      make(map[string]models.IdempotentResponse),
+	}
+	return &Storage{
+		Songs:           &memorySongRepository{data: data},
+		Groups:          &memoryGroupRepository{data: data},
+		Albums:          &memoryAlbumRepository{data: data},
+		Genres:          &memoryGenreRepository{data: data},
+		Tags:            &memoryTagRepository{data: data},
+		Playlists:       &memoryPlaylistRepository{data: data},
+		MetadataCache:   &memoryMetadataCacheRepository{data: data},
+		IdempotencyKeys: &memoryIdempotencyRepository{data: data},
+	}
+}
+
+// withGroup возвращает копию песни с заполненными группой, альбомом, жанрами и тегами.
+// Вызывается под блокировкой.
+func (d *memoryData) withGroup(song models.Song) models.Song {
+	song.Group = d.groups[song.GroupID]
+	song.Album = nil
+	if song.AlbumID != nil {
+		if album, ok := d.albums[*song.AlbumID]; ok {
+			song.Album = &album
+		}
+	}
+
+	song.Genres = nil
+	for id := range d.songGenres[song.ID] {
+		song.Genres = append(song.Genres, d.genres[id])
+	}
+	sort.Slice(song.Genres, func(i, j int) bool { return song.Genres[i].Name < song.Genres[j].Name })
+	song.Tags = nil
+	for id := range d.songTags[song.ID] {
+		song.Tags = append(song.Tags, d.tags[id])
+	}
+	sort.Slice(song.Tags, func(i, j int) bool { return song.Tags[i].Name < song.Tags[j].Name })
+	return song
+}
+
+// deleteSong окончательно удаляет песню, в том числе из корзины, вместе с её связями
+// и убирает её из плейлистов. Вызывается под блокировкой.
+func (d *memoryData) deleteSong(id int) {
+	delete(d.songs, id)
+	delete(d.trash, id)
+	delete(d.trashedPlaylists, id)
+	delete(d.songGenres, id)
+	delete(d.songTags, id)
+	delete(d.lyrics, id)
+	delete(d.syncedLyrics, id)
+	delete(d.translations, id)
+	delete(d.revisions, id)
+	for playlistID, songIDs := range d.playlistSongs {
+		kept := songIDs[:0]
+		for _, songID := range songIDs {
+			if songID != id {
+				kept = append(kept, songID)
+			}
+		}
+		d.playlistSongs[playlistID] = kept
+	}
+}
+
+// detachAlbumSongs отвязывает от альбома его песни, в том числе из корзины.
+// Вызывается под блокировкой.
+func (d *memoryData) detachAlbumSongs(albumID int) {
+	for _, songs := range []map[int]models.Song{d.songs, d.trash} {
+		for id, song := range songs {
+			if song.AlbumID != nil && *song.AlbumID == albumID {
+				song.AlbumID = nil
+				song.DiscNumber = 0
+				song.TrackNumber = 0
+				songs[id] = song
+			}
+		}
+	}
+}
+
+type memorySongRepository struct {
+	data *memoryData
+}
+
+func (r *memorySongRepository) Create(song *models.Song, author string) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	r.data.createSong(song, author)
+	return nil
+}
+
+func (r *memorySongRepository) CreateMany(songs []models.Song, author string) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	for i := range songs {
+		if songs[i].GroupID == 0 {
+			songs[i].Group = r.data.findOrCreateGroup(songs[i].Group.Name)
+			songs[i].GroupID = songs[i].Group.ID
+		}
+		r.data.createSong(&songs[i], author)
+	}
+	return nil
+}
+
+// createSong сохраняет песню с разделами текста и первой ревизией. Вызывается под блокировкой.
+func (d *memoryData) createSong(song *models.Song, author string) {
+	d.nextSongID++
+	song.ID = d.nextSongID
+	d.songs[song.ID] = *song
+	d.lyrics[song.ID] = songSections(song.ID, lyrics.Detect(song.Text))
+	d.recordRevision(nil, *song, author)
+}
+
+func (r *memorySongRepository) GetByID(id int) (*models.Song, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	song, ok := r.data.songs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	song = r.data.withGroup(song)
+	return &song, nil
+}
+
+func (r *memorySongRepository) GetFiltered(filter SongFilter) ([]models.Song, error) {
+	if filter.Query != "" || filter.Fuzzy {
+		results, err := r.Search(filter)
+		return searchResultSongs(results), err
+	}
+
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	songs := r.data.filterSongs(filter)
+	sort.Slice(songs, func(i, j int) bool { return compareSongs(songs[i], songs[j], filter.Sort) < 0 })
+	return paginate(songs, filter, func(song models.Song) models.Song { return song }), nil
+}
+
+func (r *memorySongRepository) Count(filter SongFilter) (int, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	return len(r.data.filterSongs(filter)), nil
+}
+
+func (r *memorySongRepository) Search(filter SongFilter) ([]SongSearchResult, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	matcher := newSongMatcher(filter)
+	var results []SongSearchResult
+	for _, song := range r.data.filterSongs(filter) {
+		if result, ok := matcher.match(song); ok {
+			results = append(results, result)
+		}
+	}
+	return rankSearchResults(results, filter), nil
+}
+
+// filterSongs возвращает песни, подходящие под фильтр, без учёта пагинации.
+// Вызывается под блокировкой.
+func (d *memoryData) filterSongs(filter SongFilter) []models.Song {
+	genres := normalizeNames(filter.Genres)
+	tags := normalizeNames(filter.Tags)
+	matcher := newSongMatcher(filter)
+
+	var songs []models.Song
+	for _, song := range d.songs {
+		song = d.withGroup(song)
+		if filter.ID != 0 && song.ID != filter.ID {
+			continue
+		}
+		if filter.GroupID != 0 && song.GroupID != filter.GroupID {
+			continue
+		}
+		if !filter.Fuzzy && filter.Group != "" && song.Group.NormalizedName != NormalizeName(filter.Group) {
+			continue
+		}
+		if !filter.Fuzzy && filter.Song != "" && song.Song != filter.Song {
+			continue
+		}
+		if filter.ReleaseDate != nil && !song.ReleaseDate.Equal(*filter.ReleaseDate) {
+			continue
+		}
+		if !releasedBetween(song, filter.ReleasedFrom, filter.ReleasedTo) {
+			continue
+		}
+		if filter.Text != "" && !strings.Contains(song.Text, filter.Text) {
+			continue
+		}
+		if filter.Link != "" && song.Link != filter.Link {
+			continue
+		}
+		if filter.Query != "" || filter.Fuzzy {
+			if _, ok := matcher.match(song); !ok {
+				continue
+			}
+		}
+		if filter.Album != "" && (song.Album == nil || song.Album.NormalizedTitle != NormalizeName(filter.Album)) {
+			continue
+		}
+		if len(genres) > 0 {
+			names := make(map[string]bool, len(song.Genres))
+			for _, genre := range song.Genres {
+				names[genre.NormalizedName] = true
+			}
+			if !matchTerms(names, genres, filter.AllGenres) {
+				continue
+			}
+		}
+		if len(tags) > 0 {
+			names := make(map[string]bool, len(song.Tags))
+			for _, tag := range song.Tags {
+				names[tag.NormalizedName] = true
+			}
+			if !matchTerms(names, tags, filter.AllTags) {
+				continue
+			}
+		}
+		songs = append(songs, song)
+	}
+	return songs
+}
+
+// releasedBetween проверяет, что дата выпуска песни попадает в границы from и to включительно.
+// Песня без даты выпуска подходит, только если границ нет.
+func releasedBetween(song models.Song, from, to *time.Time) bool {
+	if from == nil && to == nil {
+		return true
+	}
+	if song.ReleaseDate.IsZero() {
+		return false
+	}
+	return (from == nil || !song.ReleaseDate.Before(*from)) && (to == nil || !song.ReleaseDate.After(*to))
+}
+
+// matchTerms проверяет, что среди названий песни есть хотя бы одно (или при all — каждое) из wanted.
+func matchTerms(names map[string]bool, wanted []string, all bool) bool {
+	for _, name := range wanted {
+		if names[name] && !all {
+			return true
+		}
+		if !names[name] && all {
+			return false
+		}
+	}
+	return all
+}
+
+func (r *memorySongRepository) Update(song *models.Song, author string) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	stored, ok := r.data.songs[song.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if song.Text != stored.Text {
+		r.data.lyrics[song.ID] = songSections(song.ID, lyrics.Detect(song.Text))
+	}
+	r.data.songs[song.ID] = *song
+	r.data.recordRevision(&stored, *song, author)
+	return nil
+}
+
+func (r *memorySongRepository) SaveEnrichment(id int, result EnrichmentResult, author string) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	stored, ok := r.data.songs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	song := stored
+	result.apply(&song)
+	if song.Text != stored.Text {
+		r.data.lyrics[id] = songSections(id, lyrics.Detect(song.Text))
+	}
+	r.data.songs[id] = song
+	r.data.recordRevision(&stored, song, author)
+	return nil
+}
+
+func (r *memorySongRepository) ResetEnrichment(id int) (*models.Song, error) {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	song, ok := r.data.songs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	song.EnrichmentStatus = models.EnrichmentPending
+	song.EnrichmentAttempts = 0
+	song.EnrichmentError = ""
+	song.EnrichmentNextAt = nil
+	r.data.songs[id] = song
+	song = r.data.withGroup(song)
+	return &song, nil
+}
+
+// recordRevision записывает ревизию песни после изменения её полей так же, как одноимённая
+// функция GORM-хранилища. Вызывается под блокировкой.
+func (d *memoryData) recordRevision(old *models.Song, song models.Song, author string) {
+	associations := d.associations(song.ID)
+	var from map[string]string
+	if old != nil {
+		from = revisionFields(*old, associations)
+	}
+	d.saveRevision(song.ID, from, revisionFields(song, associations), author)
+}
+
+// changeAssociations выполняет change и записывает ревизию, если изменились жанры, теги,
+// синхронизированный текст или переводы песни songID. Возвращает ErrNotFound, если песни
+// нет. Вызывается под блокировкой.
+func (d *memoryData) changeAssociations(songID int, author string, change func() error) error {
+	song, ok := d.songs[songID]
+	if !ok {
+		return ErrNotFound
+	}
+	before := d.associations(songID)
+	if err := change(); err != nil {
+		return err
+	}
+	d.saveRevision(songID, revisionFields(song, before), revisionFields(song, d.associations(songID)), author)
+	return nil
+}
+
+// associations возвращает копию данных песни, которые записываются в ревизии вне её полей.
+func (d *memoryData) associations(songID int) songAssociations {
+	var associations songAssociations
+	for id := range d.songGenres[songID] {
+		associations.GenreIDs = append(associations.GenreIDs, id)
+	}
+	for id := range d.songTags[songID] {
+		associations.TagIDs = append(associations.TagIDs, id)
+	}
+	associations.SyncedLyrics = d.syncedLyrics[songID]
+	if len(d.translations[songID]) > 0 {
+		associations.Translations = maps.Clone(d.translations[songID])
+	}
+	return associations
+}
+
+// saveRevision записывает ревизию песни так же, как одноимённая функция GORM-хранилища.
+func (d *memoryData) saveRevision(songID int, from, to map[string]string, author string) {
+	created := from == nil
+	if created {
+		from = revisionFields(models.Song{}, songAssociations{})
+	}
+	changes := DiffFields(from, to)
+	if !created && len(changes) == 0 {
+		return
+	}
+
+	revisions := d.revisions[songID]
+	if len(revisions) == 0 && !created {
+		revisions = append(revisions, models.SongRevision{SongID: songID, Number: 1, Fields: from, Changes: []models.FieldChange{}, CreatedAt: time.Now().UTC()})
+	}
+	d.revisions[songID] = append(revisions, models.SongRevision{
+		SongID:    songID,
+		Number:    len(revisions) + 1,
+		Author:    author,
+		CreatedAt: time.Now().UTC(),
+		Fields:    to,
+		Changes:   changes,
+	})
+}
+
+func (r *memorySongRepository) Delete(id int) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	return r.data.trashSong(id)
+}
+
+func (r *memorySongRepository) DeleteIf(id int, match func(song models.Song) bool) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	song, ok := r.data.songs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if !match(r.data.withGroup(song)) {
+		return ErrPreconditionFailed
+	}
+	return r.data.trashSong(id)
+}
+
+// trashSong перемещает песню в корзину и убирает её из плейлистов, запоминая её места
+// в них. Вызывается под блокировкой.
+func (d *memoryData) trashSong(id int) error {
+	song, ok := d.songs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	var places []models.TrashedPlaylistItem
+	for playlistID, songIDs := range d.playlistSongs {
+		kept := songIDs[:0]
+		for i, songID := range songIDs {
+			if songID == id {
+				places = append(places, models.TrashedPlaylistItem{SongID: id, PlaylistID: playlistID, Position: i + 1})
+			} else {
+				kept = append(kept, songID)
+			}
+		}
+		d.playlistSongs[playlistID] = kept
+	}
+	sort.Slice(places, func(i, j int) bool { return places[i].Position < places[j].Position })
+
+	song.DeletedAt = gorm.DeletedAt{Time: time.Now().UTC(), Valid: true}
+	delete(d.songs, id)
+	d.trash[id] = song
+	d.trashedPlaylists[id] = places
+	return nil
+}
+
+func (r *memorySongRepository) ListTrash(limit, offset int) ([]models.Song, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	songs := make([]models.Song, 0, len(r.data.trash))
+	for _, song := range r.data.trash {
+		songs = append(songs, r.data.withGroup(song))
+	}
+	sort.Slice(songs, func(i, j int) bool {
+		if !songs[i].DeletedAt.Time.Equal(songs[j].DeletedAt.Time) {
+			return songs[i].DeletedAt.Time.After(songs[j].DeletedAt.Time)
+		}
+		return songs[i].ID < songs[j].ID
+	})
+
+	if offset >= len(songs) {
+		return nil, nil
+	}
+	songs = songs[offset:]
+	if limit > 0 && limit < len(songs) {
+		songs = songs[:limit]
+	}
+	return songs, nil
+}
+
+func (r *memorySongRepository) Restore(id int) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	song, ok := r.data.trash[id]
+	if !ok {
+		return ErrNotFound
+	}
+	song.DeletedAt = gorm.DeletedAt{}
+	r.data.songs[id] = song
+	delete(r.data.trash, id)
+
+	for _, place := range r.data.trashedPlaylists[id] {
+		if _, ok := r.data.playlists[place.PlaylistID]; !ok {
+			continue
+		}
+		songIDs := r.data.playlistSongs[place.PlaylistID]
+		if place.Position > len(songIDs) {
+			songIDs = append(songIDs, id)
+		} else {
+			songIDs = append(songIDs[:place.Position-1], append([]int{id}, songIDs[place.Position-1:]...)...)
+		}
+		r.data.playlistSongs[place.PlaylistID] = songIDs
+	}
+	delete(r.data.trashedPlaylists, id)
+	return nil
+}
+
+func (r *memorySongRepository) Purge(id int) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	if _, ok := r.data.trash[id]; !ok {
+		return ErrNotFound
+	}
+	r.data.deleteSong(id)
+	return nil
+}
+
+func (r *memorySongRepository) PurgeTrash(before time.Time) (int, error) {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	purged := 0
+	for id, song := range r.data.trash {
+		if song.DeletedAt.Time.Before(before) {
+			r.data.deleteSong(id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (r *memorySongRepository) ListDueForEnrichment(now time.Time, limit int) ([]int, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	var ids []int
+	for _, song := range r.data.songs {
+		if song.EnrichmentStatus != models.EnrichmentPending {
+			continue
+		}
+		if song.EnrichmentNextAt != nil && song.EnrichmentNextAt.After(now) {
+			continue
+		}
+		ids = append(ids, song.ID)
+	}
+	sort.Ints(ids)
+	if limit > 0 && limit < len(ids) {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+func (r *memorySongRepository) SetGenres(songID int, genreIDs []int, author string) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	return r.data.changeAssociations(songID, author, func() error {
+		links := make(map[int]bool, len(genreIDs))
+		for _, id := range genreIDs {
+			links[id] = true
+		}
+		r.data.songGenres[songID] = links
+		return nil
+	})
+}
+
+func (r *memorySongRepository) SetTags(songID int, tagIDs []int, author string) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	return r.data.changeAssociations(songID, author, func() error {
+		links := make(map[int]bool, len(tagIDs))
+		for _, id := range tagIDs {
+			links[id] = true
+		}
+		r.data.songTags[songID] = links
+		return nil
+	})
+}
+
+func (r *memorySongRepository) Facets(filter SongFilter) (*SongFacets, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	songs := r.data.filterSongs(filter)
+	genres := make(map[int]*TermCount)
+	tags := make(map[int]*TermCount)
+	for _, song := range songs {
+		for _, genre := range song.Genres {
+			if genres[genre.ID] == nil {
+				genres[genre.ID] = &TermCount{ID: genre.ID, Name: genre.Name}
+			}
+			genres[genre.ID].SongCount++
+		}
+		for _, tag := range song.Tags {
+			if tags[tag.ID] == nil {
+				tags[tag.ID] = &TermCount{ID: tag.ID, Name: tag.Name}
+			}
+			tags[tag.ID].SongCount++
+		}
+	}
+	return &SongFacets{
+		Total:  len(songs),
+		Genres: sortedTermCounts(genres),
+		Tags:   sortedTermCounts(tags),
+	}, nil
+}
+
+// sortedTermCounts упорядочивает счётчики по убыванию количества песен и затем по названию.
+func sortedTermCounts(counts map[int]*TermCount) []TermCount {
+	terms := make([]TermCount, 0, len(counts))
+	for _, count := range counts {
+		terms = append(terms, *count)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].SongCount != terms[j].SongCount {
+			return terms[i].SongCount > terms[j].SongCount
+		}
+		return terms[i].Name < terms[j].Name
+	})
+	return terms
+}
+
+func (r *memorySongRepository) GetLyrics(songID int) ([]models.LyricsSection, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	if _, ok := r.data.songs[songID]; !ok {
+		return nil, ErrNotFound
+	}
+	return append([]models.LyricsSection(nil), r.data.lyrics[songID]...), nil
+}
+
+func (r *memorySongRepository) SetLyrics(songID int, sections []models.LyricsSection, author string) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	old, ok := r.data.songs[songID]
+	if !ok {
+		return ErrNotFound
+	}
+	sections = songSections(songID, sections)
+	song := old
+	song.Text = lyrics.Render(sections)
+	r.data.songs[songID] = song
+	r.data.lyrics[songID] = sections
+	r.data.recordRevision(&old, song, author)
+	return nil
+}
+
+func (r *memorySongRepository) GetSyncedLyrics(songID int) ([]models.LyricsLine, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	if _, ok := r.data.songs[songID]; !ok {
+		return nil, ErrNotFound
+	}
+	return songLines(songID, r.data.syncedLyrics[songID]), nil
+}
+
+func (r *memorySongRepository) SetSyncedLyrics(songID int, lines []models.LyricsLine, author string) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	return r.data.changeAssociations(songID, author, func() error {
+		r.data.syncedLyrics[songID] = songLines(songID, lines)
+		return nil
+	})
+}
+
+func (r *memorySongRepository) ListRevisions(songID int) ([]models.SongRevision, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	if _, ok := r.data.songs[songID]; !ok {
+		return nil, ErrNotFound
+	}
+	return append([]models.SongRevision(nil), r.data.revisions[songID]...), nil
+}
+
+func (r *memorySongRepository) GetRevision(songID, number int) (*models.SongRevision, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	revisions := r.data.revisions[songID]
+	if number < 1 || number > len(revisions) {
+		return nil, ErrNotFound
+	}
+	revision := revisions[number-1]
+	return &revision, nil
+}
+
+func (r *memorySongRepository) ListTranslations(songID int) ([]models.SongTranslation, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	if _, ok := r.data.songs[songID]; !ok {
+		return nil, ErrNotFound
+	}
+	translations := make([]models.SongTranslation, 0, len(r.data.translations[songID]))
+	for language, text := range r.data.translations[songID] {
+		translations = append(translations, models.SongTranslation{SongID: songID, Language: language, Text: text})
+	}
+	sort.Slice(translations, func(i, j int) bool { return translations[i].Language < translations[j].Language })
+	return translations, nil
+}
+
+func (r *memorySongRepository) SetTranslation(songID int, language, text, author string) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	return r.data.changeAssociations(songID, author, func() error {
+		if r.data.translations[songID] == nil {
+			r.data.translations[songID] = make(map[string]string)
+		}
+		r.data.translations[songID][language] = text
+		return nil
+	})
+}
+
+func (r *memorySongRepository) DeleteTranslation(songID int, language, author string) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	return r.data.changeAssociations(songID, author, func() error {
+		if _, ok := r.data.translations[songID][language]; !ok {
+			return ErrNotFound
+		}
+		delete(r.data.translations[songID], language)
+		return nil
+	})
+}
+
+type memoryGroupRepository struct {
+	data *memoryData
+}
+
+func (r *memoryGroupRepository) FindOrCreate(name string) (*models.Group, error) {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	group := r.data.findOrCreateGroup(name)
+	return &group, nil
+}
+
+// findOrCreateGroup возвращает группу с таким названием или псевдонимом либо создаёт её.
+// Вызывается под блокировкой.
+func (d *memoryData) findOrCreateGroup(name string) models.Group {
+	if group, ok := d.findGroup(NormalizeName(name)); ok {
+		return group
+	}
+
+	d.nextGroupID++
+	group := models.Group{ID: d.nextGroupID, Name: strings.TrimSpace(name), NormalizedName: NormalizeName(name)}
+	d.groups[group.ID] = group
+	return group
+}
+
+// findGroup ищет группу с наименьшим ID по нормализованному названию, затем по псевдонимам.
+// Вызывается под блокировкой.
+func (d *memoryData) findGroup(normalized string) (models.Group, bool) {
+	var found models.Group
+	for _, group := range d.groups {
+		if group.NormalizedName == normalized && (found.ID == 0 || group.ID < found.ID) {
+			found = group
+		}
+	}
+	if found.ID != 0 {
+		return found, true
+	}
+	for _, alias := range d.aliases {
+		if alias.NormalizedName == normalized {
+			group, ok := d.groups[alias.GroupID]
+			return group, ok
+		}
+	}
+	return models.Group{}, false
+}
+
+func (r *memoryGroupRepository) GetByID(id int) (*models.Group, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	group, ok := r.data.groups[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &group, nil
+}
+
+func (r *memoryGroupRepository) FindByName(name string) (*models.Group, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	group, ok := r.data.findGroup(NormalizeName(name))
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &group, nil
+}
+
+func (r *memoryGroupRepository) List(limit, offset int) ([]GroupWithSongCount, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	counts := make(map[int]int)
+	for _, song := range r.data.songs {
+		counts[song.GroupID]++
+	}
+	groups := make([]GroupWithSongCount, 0, len(r.data.groups))
+	for _, group := range r.data.groups {
+		groups = append(groups, GroupWithSongCount{ID: group.ID, Name: group.Name, SongCount: counts[group.ID]})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+
+	if offset >= len(groups) {
+		return nil, nil
+	}
+	groups = groups[offset:]
+	if limit > 0 && limit < len(groups) {
+		groups = groups[:limit]
+	}
+	return groups, nil
+}
+
+func (r *memoryGroupRepository) Update(group *models.Group) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	if _, ok := r.data.groups[group.ID]; !ok {
+		return ErrNotFound
+	}
+	normalized := NormalizeName(group.Name)
+	for id, other := range r.data.groups {
+		if id != group.ID && other.NormalizedName == normalized {
+			return ErrNameConflict
+		}
+	}
+	for _, alias := range r.data.aliases {
+		if alias.GroupID != group.ID && alias.NormalizedName == normalized {
+			return ErrNameConflict
+		}
+	}
+	group.NormalizedName = normalized
+	r.data.groups[group.ID] = *group
+	return nil
+}
+
+func (r *memoryGroupRepository) Delete(id int, deleteSongs bool) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	if _, ok := r.data.groups[id]; !ok {
+		return ErrNotFound
+	}
+	var songIDs []int
+	for _, songs := range []map[int]models.Song{r.data.songs, r.data.trash} {
+		for _, song := range songs {
+			if song.GroupID == id {
+				songIDs = append(songIDs, song.ID)
+			}
+		}
+	}
+	if len(songIDs) > 0 && !deleteSongs {
+		return ErrGroupHasSongs
+	}
+	// Как и в GORM-хранилище, песни удаляются окончательно, минуя корзину.
+	for _, songID := range songIDs {
+		r.data.deleteSong(songID)
+	}
+	for albumID, album := range r.data.albums {
+		if album.GroupID == id {
+			r.data.detachAlbumSongs(albumID)
+			delete(r.data.albums, albumID)
+		}
+	}
+	for aliasID, alias := range r.data.aliases {
+		if alias.GroupID == id {
+			delete(r.data.aliases, aliasID)
+		}
+	}
+	delete(r.data.groups, id)
+	return nil
+}
+
+func (r *memoryGroupRepository) ListAliases(groupID int) ([]models.GroupAlias, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	var aliases []models.GroupAlias
+	for _, alias := range r.data.aliases {
+		if alias.GroupID == groupID {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].ID < aliases[j].ID })
+	return aliases, nil
+}
+
+func (r *memoryGroupRepository) AddAlias(groupID int, name string) (*models.GroupAlias, error) {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	if _, ok := r.data.groups[groupID]; !ok {
+		return nil, ErrNotFound
+	}
+	normalized := NormalizeName(name)
+	if _, taken := r.data.findGroup(normalized); taken {
+		return nil, ErrNameConflict
+	}
+
+	r.data.nextAliasID++
+	alias := models.GroupAlias{ID: r.data.nextAliasID, GroupID: groupID, Name: strings.TrimSpace(name), NormalizedName: normalized}
+	r.data.aliases[alias.ID] = alias
+	return &alias, nil
+}
+
+func (r *memoryGroupRepository) DeleteAlias(groupID, aliasID int) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	alias, ok := r.data.aliases[aliasID]
+	if !ok || alias.GroupID != groupID {
+		return ErrNotFound
+	}
+	delete(r.data.aliases, aliasID)
+	return nil
+}
+
+func (r *memoryGroupRepository) Merge(targetID int, sourceIDs []int, author string) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	target, ok := r.data.groups[targetID]
+	if !ok {
+		return ErrNotFound
+	}
+	sources := make(map[int]models.Group, len(sourceIDs))
+	for _, id := range sourceIDs {
+		source, ok := r.data.groups[id]
+		if !ok {
+			return ErrNotFound
+		}
+		sources[id] = source
+	}
+
+	for _, songs := range []map[int]models.Song{r.data.songs, r.data.trash} {
+		for id, old := range songs {
+			if _, ok := sources[old.GroupID]; ok {
+				song := old
+				song.GroupID = targetID
+				songs[id] = song
+				r.data.recordRevision(&old, song, author)
+			}
+		}
+	}
+	for id, album := range r.data.albums {
+		if _, ok := sources[album.GroupID]; ok {
+			album.GroupID = targetID
+			r.data.albums[id] = album
+		}
+	}
+	taken := make(map[string]bool)
+	for id, alias := range r.data.aliases {
+		if _, ok := sources[alias.GroupID]; ok {
+			alias.GroupID = targetID
+			r.data.aliases[id] = alias
+		}
+		taken[alias.NormalizedName] = true
+	}
+	for _, id := range sourceIDs {
+		source := sources[id]
+		if source.NormalizedName == target.NormalizedName || taken[source.NormalizedName] {
+			continue
+		}
+		taken[source.NormalizedName] = true
+		r.data.nextAliasID++
+		r.data.aliases[r.data.nextAliasID] = models.GroupAlias{
+			ID:             r.data.nextAliasID,
+			GroupID:        targetID,
+			Name:           source.Name,
+			NormalizedName: source.NormalizedName,
+		}
+	}
+	for id := range sources {
+		delete(r.data.groups, id)
+	}
+	return nil
+}
+
+type memoryAlbumRepository struct {
+	data *memoryData
+}
+
+func (r *memoryAlbumRepository) Create(album *models.Album) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	r.data.nextAlbumID++
+	album.ID = r.data.nextAlbumID
+	album.NormalizedTitle = NormalizeName(album.Title)
+	album.Group = models.Group{}
+	r.data.albums[album.ID] = *album
+	return nil
+}
+
+func (r *memoryAlbumRepository) GetByID(id int) (*models.Album, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	album, ok := r.data.albums[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	album.Group = r.data.groups[album.GroupID]
+	return &album, nil
+}
+
+func (r *memoryAlbumRepository) List(filter AlbumFilter) ([]AlbumWithTrackCount, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	counts := make(map[int]int)
+	for _, song := range r.data.songs {
+		if song.AlbumID != nil {
+			counts[*song.AlbumID]++
+		}
+	}
+	var albums []AlbumWithTrackCount
+	for _, album := range r.data.albums {
+		group := r.data.groups[album.GroupID]
+		if filter.GroupID != 0 && album.GroupID != filter.GroupID {
+			continue
+		}
+		if filter.Group != "" && group.NormalizedName != NormalizeName(filter.Group) {
+			continue
+		}
+		if filter.Title != "" && album.NormalizedTitle != NormalizeName(filter.Title) {
+			continue
+		}
+		albums = append(albums, AlbumWithTrackCount{
+			ID:          album.ID,
+			GroupID:     album.GroupID,
+			GroupName:   group.Name,
+			Title:       album.Title,
+			ReleaseDate: album.ReleaseDate,
+			CoverLink:   album.CoverLink,
+			TrackCount:  counts[album.ID],
+		})
+	}
+	sort.Slice(albums, func(i, j int) bool { return albums[i].ID < albums[j].ID })
+
+	if filter.Offset >= len(albums) {
+		return nil, nil
+	}
+	albums = albums[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(albums) {
+		albums = albums[:filter.Limit]
+	}
+	return albums, nil
+}
+
+func (r *memoryAlbumRepository) Update(album *models.Album) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	stored, ok := r.data.albums[album.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	album.NormalizedTitle = NormalizeName(album.Title)
+	stored.Title = album.Title
+	stored.NormalizedTitle = album.NormalizedTitle
+	stored.ReleaseDate = album.ReleaseDate
+	stored.CoverLink = album.CoverLink
+	r.data.albums[album.ID] = stored
+	return nil
+}
+
+func (r *memoryAlbumRepository) Delete(id int) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	if _, ok := r.data.albums[id]; !ok {
+		return ErrNotFound
+	}
+	r.data.detachAlbumSongs(id)
+	delete(r.data.albums, id)
+	return nil
+}
+
+func (r *memoryAlbumRepository) ListTracks(albumID int) ([]models.Song, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	var songs []models.Song
+	for _, song := range r.data.songs {
+		if song.AlbumID != nil && *song.AlbumID == albumID {
+			songs = append(songs, r.data.withGroup(song))
+		}
+	}
+	sort.Slice(songs, func(i, j int) bool {
+		a, b := songs[i], songs[j]
+		if a.DiscNumber != b.DiscNumber {
+			return a.DiscNumber < b.DiscNumber
+		}
+		if a.TrackNumber != b.TrackNumber {
+			return a.TrackNumber < b.TrackNumber
+		}
+		return a.ID < b.ID
+	})
+	return songs, nil
+}
+
+type memoryGenreRepository struct {
+	data *memoryData
+}
+
+func (r *memoryGenreRepository) Create(name string) (*models.Genre, error) {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	normalized := NormalizeName(name)
+	for _, genre := range r.data.genres {
+		if genre.NormalizedName == normalized {
+			return nil, ErrNameConflict
+		}
+	}
+
+	r.data.nextGenreID++
+	genre := models.Genre{ID: r.data.nextGenreID, Name: strings.TrimSpace(name), NormalizedName: normalized}
+	r.data.genres[genre.ID] = genre
+	return &genre, nil
+}
+
+func (r *memoryGenreRepository) GetByID(id int) (*models.Genre, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	genre, ok := r.data.genres[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &genre, nil
+}
+
+func (r *memoryGenreRepository) FindByNames(names []string) ([]models.Genre, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	wanted := make(map[string]bool)
+	for _, name := range normalizeNames(names) {
+		wanted[name] = true
+	}
+	var genres []models.Genre
+	for _, genre := range r.data.genres {
+		if wanted[genre.NormalizedName] {
+			genres = append(genres, genre)
+		}
+	}
+	sort.Slice(genres, func(i, j int) bool { return genres[i].Name < genres[j].Name })
+	return genres, nil
+}
+
+func (r *memoryGenreRepository) List() ([]TermCount, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	counts := make(map[int]int)
+	for songID, links := range r.data.songGenres {
+		if _, ok := r.data.songs[songID]; !ok {
+			continue
+		}
+		for id := range links {
+			counts[id]++
+		}
+	}
+	genres := make([]TermCount, 0, len(r.data.genres))
+	for _, genre := range r.data.genres {
+		genres = append(genres, TermCount{ID: genre.ID, Name: genre.Name, SongCount: counts[genre.ID]})
+	}
+	sort.Slice(genres, func(i, j int) bool { return genres[i].Name < genres[j].Name })
+	return genres, nil
+}
+
+func (r *memoryGenreRepository) Update(genre *models.Genre) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	if _, ok := r.data.genres[genre.ID]; !ok {
+		return ErrNotFound
+	}
+	genre.Name = strings.TrimSpace(genre.Name)
+	genre.NormalizedName = NormalizeName(genre.Name)
+	for _, other := range r.data.genres {
+		if other.ID != genre.ID && other.NormalizedName == genre.NormalizedName {
+			return ErrNameConflict
+		}
+	}
+	r.data.genres[genre.ID] = *genre
+	return nil
+}
+
+func (r *memoryGenreRepository) Delete(id int) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	if _, ok := r.data.genres[id]; !ok {
+		return ErrNotFound
+	}
+	for _, links := range r.data.songGenres {
+		delete(links, id)
+	}
+	delete(r.data.genres, id)
+	return nil
+}
+
+type memoryTagRepository struct {
+	data *memoryData
+}
+
+func (r *memoryTagRepository) FindOrCreate(names []string) ([]models.Tag, error) {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	existing := make(map[string]models.Tag, len(r.data.tags))
+	for _, tag := range r.data.tags {
+		existing[tag.NormalizedName] = tag
+	}
+
+	var tags []models.Tag
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		normalized := NormalizeName(name)
+		if normalized == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+
+		tag, ok := existing[normalized]
+		if !ok {
+			r.data.nextTagID++
+			tag = models.Tag{ID: r.data.nextTagID, Name: strings.TrimSpace(name), NormalizedName: normalized}
+			r.data.tags[tag.ID] = tag
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func (r *memoryTagRepository) List(limit, offset int) ([]TermCount, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	counts := make(map[int]*TermCount, len(r.data.tags))
+	for _, tag := range r.data.tags {
+		counts[tag.ID] = &TermCount{ID: tag.ID, Name: tag.Name}
+	}
+	for songID, links := range r.data.songTags {
+		if _, ok := r.data.songs[songID]; !ok {
+			continue
+		}
+		for id := range links {
+			counts[id].SongCount++
+		}
+	}
+	tags := sortedTermCounts(counts)
+
+	if offset >= len(tags) {
+		return nil, nil
+	}
+	tags = tags[offset:]
+	if limit > 0 && limit < len(tags) {
+		tags = tags[:limit]
+	}
+	return tags, nil
+}
+
+func (r *memoryTagRepository) Delete(id int) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	if _, ok := r.data.tags[id]; !ok {
+		return ErrNotFound
+	}
+	for _, links := range r.data.songTags {
+		delete(links, id)
+	}
+	delete(r.data.tags, id)
+	return nil
+}
+
+type memoryPlaylistRepository struct {
+	data *memoryData
+}
+
+func (r *memoryPlaylistRepository) Create(playlist *models.Playlist) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	r.data.nextPlaylistID++
+	playlist.ID = r.data.nextPlaylistID
+	playlist.CreatedAt = time.Now()
+	playlist.UpdatedAt = playlist.CreatedAt
+	r.data.playlists[playlist.ID] = *playlist
+	return nil
+}
+
+func (r *memoryPlaylistRepository) GetByID(id int) (*models.Playlist, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	playlist, ok := r.data.playlists[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &playlist, nil
+}
+
+func (r *memoryPlaylistRepository) List(limit, offset int) ([]PlaylistWithSongCount, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	playlists := make([]PlaylistWithSongCount, 0, len(r.data.playlists))
+	for _, playlist := range r.data.playlists {
+		playlists = append(playlists, PlaylistWithSongCount{
+			ID:          playlist.ID,
+			Name:        playlist.Name,
+			Description: playlist.Description,
+			SongCount:   len(r.data.playlistSongs[playlist.ID]),
+		})
+	}
+	sort.Slice(playlists, func(i, j int) bool { return playlists[i].ID < playlists[j].ID })
+
+	if offset >= len(playlists) {
+		return nil, nil
+	}
+	playlists = playlists[offset:]
+	if limit > 0 && limit < len(playlists) {
+		playlists = playlists[:limit]
+	}
+	return playlists, nil
+}
+
+func (r *memoryPlaylistRepository) Update(playlist *models.Playlist) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	stored, ok := r.data.playlists[playlist.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	stored.Name = playlist.Name
+	stored.Description = playlist.Description
+	stored.UpdatedAt = time.Now()
+	r.data.playlists[playlist.ID] = stored
+	return nil
+}
+
+func (r *memoryPlaylistRepository) Delete(id int) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	if _, ok := r.data.playlists[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.data.playlists, id)
+	delete(r.data.playlistSongs, id)
+	return nil
+}
+
+func (r *memoryPlaylistRepository) ListSongs(playlistID, limit, offset int) ([]models.Song, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	songIDs := r.data.playlistSongs[playlistID]
+	if offset >= len(songIDs) {
+		return nil, nil
+	}
+	songIDs = songIDs[offset:]
+	if limit > 0 && limit < len(songIDs) {
+		songIDs = songIDs[:limit]
+	}
+
+	songs := make([]models.Song, 0, len(songIDs))
+	for _, id := range songIDs {
+		songs = append(songs, r.data.withGroup(r.data.songs[id]))
+	}
+	return songs, nil
+}
+
+func (r *memoryPlaylistRepository) AddSong(playlistID, songID, position int) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	playlist, ok := r.data.playlists[playlistID]
+	if !ok {
+		return ErrNotFound
+	}
+	if _, ok := r.data.songs[songID]; !ok {
+		return ErrNotFound
+	}
+
+	songIDs := r.data.playlistSongs[playlistID]
+	if position < 1 || position > len(songIDs) {
+		songIDs = append(songIDs, songID)
+	} else {
+		songIDs = append(songIDs[:position-1], append([]int{songID}, songIDs[position-1:]...)...)
+	}
+	r.data.playlistSongs[playlistID] = songIDs
+	playlist.UpdatedAt = time.Now()
+	r.data.playlists[playlistID] = playlist
+	return nil
+}
+
+func (r *memoryPlaylistRepository) RemoveSong(playlistID, position int) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	playlist, ok := r.data.playlists[playlistID]
+	songIDs := r.data.playlistSongs[playlistID]
+	if !ok || position < 1 || position > len(songIDs) {
+		return ErrNotFound
+	}
+
+	r.data.playlistSongs[playlistID] = append(songIDs[:position-1], songIDs[position:]...)
+	playlist.UpdatedAt = time.Now()
+	r.data.playlists[playlistID] = playlist
+	return nil
+}
+
+func (r *memoryPlaylistRepository) MoveSong(playlistID, from, to int) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	playlist, ok := r.data.playlists[playlistID]
+	songIDs := r.data.playlistSongs[playlistID]
+	if !ok || from < 1 || from > len(songIDs) {
+		return ErrNotFound
+	}
+
+	songID := songIDs[from-1]
+	songIDs = append(songIDs[:from-1], songIDs[from:]...)
+	if to < 1 || to > len(songIDs) {
+		songIDs = append(songIDs, songID)
+	} else {
+		songIDs = append(songIDs[:to-1], append([]int{songID}, songIDs[to-1:]...)...)
+	}
+	r.data.playlistSongs[playlistID] = songIDs
+	playlist.UpdatedAt = time.Now()
+	r.data.playlists[playlistID] = playlist
+	return nil
+}
+
+type memoryMetadataCacheRepository struct {
+	data *memoryData
+}
+
+func (r *memoryMetadataCacheRepository) Get(key string) (*models.MetadataCacheEntry, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	entry, ok := r.data.cache[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &entry, nil
+}
+
+func (r *memoryMetadataCacheRepository) Put(entry *models.MetadataCacheEntry) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	r.data.cache[entry.Key] = *entry
+	return nil
+}
+
+func (r *memoryMetadataCacheRepository) DeleteExpired(now time.Time) (int64, error) {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	var deleted int64
+	for key, entry := range r.data.cache {
+		if !entry.ExpiresAt.After(now) {
+			delete(r.data.cache, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+type memoryIdempotencyRepository struct {
+	data *memoryData
+}
+
+func (r *memoryIdempotencyRepository) Get(key string) (*models.IdempotentResponse, error) {
+	r.data.mu.RLock()
+	defer r.data.mu.RUnlock()
+
+	response, ok := r.data.idempotency[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &response, nil
+}
+
+func (r *memoryIdempotencyRepository) Reserve(response *models.IdempotentResponse, now time.Time) (bool, error) {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	if stored, ok := r.data.idempotency[response.Key]; ok && stored.ExpiresAt.After(now) {
+		return false, nil
+	}
+	r.data.idempotency[response.Key] = *response
+	return true, nil
+}
+
+func (r *memoryIdempotencyRepository) Complete(response *models.IdempotentResponse) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	stored, ok := r.data.idempotency[response.Key]
+	if !ok {
+		return nil
+	}
+	stored.Status = response.Status
+	stored.ContentType = response.ContentType
+	stored.Body = response.Body
+	stored.ExpiresAt = response.ExpiresAt
+	r.data.idempotency[response.Key] = stored
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) Release(key string) error {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	if stored, ok := r.data.idempotency[key]; ok && stored.Pending() {
+		delete(r.data.idempotency, key)
+	}
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) DeleteExpired(now time.Time) (int64, error) {
+	r.data.mu.Lock()
+	defer r.data.mu.Unlock()
+
+	var deleted int64
+	for key, response := range r.data.idempotency {
+		if !response.ExpiresAt.After(now) {
+			delete(r.data.idempotency, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
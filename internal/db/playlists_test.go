@@ -0,0 +1,135 @@
+package db_test
+
+import (
+	"errors"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"slices"
+	"testing"
+)
+
+// createPlaylist добавляет плейлист с песнями songIDs по порядку и возвращает его ID.
+func createPlaylist(t *testing.T, storage *db.Storage, name string, songIDs ...int) int {
+	t.Helper()
+	playlist := models.Playlist{Name: name}
+	if err := storage.Playlists.Create(&playlist); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range songIDs {
+		if err := storage.Playlists.AddSong(playlist.ID, id, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return playlist.ID
+}
+
+// playlistSongIDs возвращает ID песен плейлиста в порядке позиций.
+func playlistSongIDs(t *testing.T, storage *db.Storage, playlistID int) []int {
+	t.Helper()
+	songs, err := storage.Playlists.ListSongs(playlistID, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return songIDs(songs)
+}
+
+func TestPlaylistReorder(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			var ids []int
+			for _, song := range []string{"Innuendo", "Headlong", "Bijou", "Mustapha"} {
+				ids = append(ids, createSong(t, storage, "Queen", models.Song{Song: song}).ID)
+			}
+			a, b, c, d := ids[0], ids[1], ids[2], ids[3]
+			playlist := createPlaylist(t, storage, "Innuendo", a, b, c)
+
+			steps := []struct {
+				name   string
+				change func() error
+				want   []int
+			}{
+				{"вставка в начало", func() error { return storage.Playlists.AddSong(playlist, d, 1) }, []int{d, a, b, c}},
+				{"вставка в середину", func() error { return storage.Playlists.AddSong(playlist, a, 3) }, []int{d, a, a, b, c}},
+				{"позиция за концом", func() error { return storage.Playlists.AddSong(playlist, b, 100) }, []int{d, a, a, b, c, b}},
+				{"перенос вперёд", func() error { return storage.Playlists.MoveSong(playlist, 1, 4) }, []int{a, a, b, d, c, b}},
+				{"перенос назад", func() error { return storage.Playlists.MoveSong(playlist, 5, 2) }, []int{a, c, a, b, d, b}},
+				{"перенос за конец", func() error { return storage.Playlists.MoveSong(playlist, 1, 100) }, []int{c, a, b, d, b, a}},
+				{"перенос на то же место", func() error { return storage.Playlists.MoveSong(playlist, 3, 3) }, []int{c, a, b, d, b, a}},
+				{"удаление из середины", func() error { return storage.Playlists.RemoveSong(playlist, 3) }, []int{c, a, d, b, a}},
+				{"удаление последней", func() error { return storage.Playlists.RemoveSong(playlist, 5) }, []int{c, a, d, b}},
+			}
+			for _, step := range steps {
+				if err := step.change(); err != nil {
+					t.Fatalf("%s: %v", step.name, err)
+				}
+				if got := playlistSongIDs(t, storage, playlist); !slices.Equal(got, step.want) {
+					t.Fatalf("%s: %v, ожидалось %v", step.name, got, step.want)
+				}
+			}
+
+			for name, err := range map[string]error{
+				"перенос с несуществующей позиции":  storage.Playlists.MoveSong(playlist, 5, 1),
+				"удаление несуществующей позиции":   storage.Playlists.RemoveSong(playlist, 0),
+				"добавление неизвестной песни":      storage.Playlists.AddSong(playlist, 1000, 1),
+				"добавление в неизвестный плейлист": storage.Playlists.AddSong(1000, a, 1),
+			} {
+				if !errors.Is(err, db.ErrNotFound) {
+					t.Errorf("%s: %v, ожидалась ErrNotFound", name, err)
+				}
+			}
+			if got := playlistSongIDs(t, storage, playlist); !slices.Equal(got, []int{c, a, d, b}) {
+				t.Errorf("после ошибок: %v", got)
+			}
+		})
+	}
+}
+
+func TestTrashedSongPlaylistPlaces(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			var ids []int
+			for _, song := range []string{"Innuendo", "Headlong", "Bijou"} {
+				ids = append(ids, createSong(t, storage, "Queen", models.Song{Song: song}).ID)
+			}
+			a, b, c := ids[0], ids[1], ids[2]
+			kept := createPlaylist(t, storage, "Любимое", a, b, a, c)
+			removed := createPlaylist(t, storage, "Удаляемый", c, a)
+
+			if err := storage.Songs.Delete(a); err != nil {
+				t.Fatal(err)
+			}
+			if got := playlistSongIDs(t, storage, kept); !slices.Equal(got, []int{b, c}) {
+				t.Fatalf("плейлист после удаления песни в корзину: %v", got)
+			}
+			if err := storage.Playlists.MoveSong(kept, 2, 1); err != nil {
+				t.Fatal(err)
+			}
+
+			// Места песни в удалённом плейлисте удаляются вместе с ним, и восстановление
+			// возвращает песню только в оставшийся.
+			if err := storage.Playlists.Delete(removed); err != nil {
+				t.Fatal(err)
+			}
+			if err := storage.Songs.Restore(a); err != nil {
+				t.Fatal(err)
+			}
+			if got := playlistSongIDs(t, storage, kept); !slices.Equal(got, []int{a, c, a, b}) {
+				t.Errorf("плейлист после восстановления: %v, ожидалось %v", got, []int{a, c, a, b})
+			}
+			if got := playlistSongIDs(t, storage, removed); len(got) != 0 {
+				t.Errorf("песни удалённого плейлиста: %v", got)
+			}
+
+			// Окончательно удалённая песня в плейлисты не возвращается.
+			if err := storage.Songs.Delete(c); err != nil {
+				t.Fatal(err)
+			}
+			if err := storage.Songs.Purge(c); err != nil {
+				t.Fatal(err)
+			}
+			if got := playlistSongIDs(t, storage, kept); !slices.Equal(got, []int{a, a, b}) {
+				t.Errorf("плейлист после окончательного удаления песни: %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,398 @@
+package db
+
+import (
+	"errors"
+	"music_storage/internal/models"
+	"strings"
+	"time"
+)
+
+// ErrNotFound возвращается репозиториями, если запрошенная запись не существует.
+var ErrNotFound = errors.New("запись не найдена")
+
+// ErrNameConflict возвращается, если название уже занято другой группой или псевдонимом.
+var ErrNameConflict = errors.New("название уже используется")
+
+// ErrPreconditionFailed возвращается условными изменениями, если запись не в ожидаемом
+// состоянии.
+var ErrPreconditionFailed = errors.New("запись изменилась")
+
+// ErrGroupHasSongs возвращается при удалении группы, у которой есть песни,
+// если не разрешено удалить их вместе с группой.
+var ErrGroupHasSongs = errors.New("у группы есть песни")
+
+// SongFilter описывает параметры фильтрации и пагинации списка песен.
+// Пустые значения полей означают отсутствие фильтра.
+type SongFilter struct {
+	ID          int
+	GroupID     int
+	Group       string
+	Song        string
+	ReleaseDate *time.Time
+	// ReleasedFrom и ReleasedTo — границы даты выпуска включительно. Песни без даты
+	// выпуска (нулевая дата) под диапазон не подходят.
+	ReleasedFrom *time.Time
+	ReleasedTo   *time.Time
+	Text         string
+	Link         string
+	// Album — название альбома, сравнивается как нормализованное (см. NormalizeName).
+	Album string
+	// Genres и Tags — названия жанров и тегов. По умолчанию песня подходит, если у неё есть
+	// хотя бы один из них; при AllGenres/AllTags — только если есть все.
+	Genres    []string
+	AllGenres bool
+	Tags      []string
+	AllTags   bool
+	// Query — поисковый запрос по названию и тексту песни (см. SongRepository.Search).
+	Query string
+	// Fuzzy — сравнивать Song и Group не на равенство, а по сходству триграмм: песня
+	// подходит, если сходство каждого из заданных названий не меньше Similarity
+	// (0 — DefaultSimilarity).
+	Fuzzy      bool
+	Similarity float64
+	// Sort — поля сортировки. Если не заданы, песни упорядочены по ID, а при поиске —
+	// по релевантности (см. SongRepository.Search). Последним всегда сравнивается ID.
+	Sort []SortField
+	// Cursor — начало или конец страницы в порядке Sort (см. SongCursor); Offset
+	// отсчитывается от курсора. При сортировке по релевантности курсор не применяется.
+	Cursor *SongCursor
+	Limit  int
+	Offset int
+}
+
+// DefaultSimilarity — минимальное сходство названий при нечётком сравнении по умолчанию
+// (как pg_trgm.similarity_threshold).
+const DefaultSimilarity = 0.3
+
+// SongSearchResult — песня, найденная поисковым запросом.
+type SongSearchResult struct {
+	Song models.Song
+	// Rank — релевантность от 0 до 1: чем больше, тем лучше песня подходит под запрос.
+	Rank float64
+	// Snippet — куплет, лучше всего подходящий под запрос, с совпадениями в тегах <b></b>.
+	// Пустой, если запрос совпал только с названием песни.
+	Snippet string
+	// Score — сходство названий песни и группы с фильтром при Fuzzy (от 0 до 1): среднее
+	// по заданным в фильтре названиям.
+	Score float64
+}
+
+// SongRepository описывает хранилище песен.
+// Create, CreateMany и Update разбирают новый или изменившийся текст песни на разделы
+// (см. lyrics.Detect). Create, CreateMany, Update, SaveEnrichment и методы, меняющие текст,
+// жанры, теги, синхронизированный текст и переводы песни, записывают изменения ревизией
+// от имени author.
+type SongRepository interface {
+	Create(song *models.Song, author string) error
+	// CreateMany добавляет песни в одной транзакции: при ошибке не сохраняется ни одна.
+	// Группу песни без GroupID он находит или создаёт по Group.Name в той же транзакции,
+	// так что при ошибке не остаётся и новых групп. ID добавленных песен и их групп
+	// записываются в songs.
+	CreateMany(songs []models.Song, author string) error
+	GetByID(id int) (*models.Song, error)
+	// GetFiltered возвращает песни, подходящие под фильтр, в порядке filter.Sort, без него —
+	// по возрастанию ID, а при заданном filter.Query или filter.Fuzzy — в порядке Search.
+	GetFiltered(filter SongFilter) ([]models.Song, error)
+	// Search ищет песни по filter.Query и/или нечётким названиям (filter.Fuzzy) с учётом
+	// остальных условий фильтра и возвращает их в порядке filter.Sort, а без него — по
+	// убыванию релевантности, затем сходства названий и по возрастанию ID.
+	Search(filter SongFilter) ([]SongSearchResult, error)
+	// Count возвращает количество песен, подходящих под фильтр, без учёта курсора и пагинации.
+	Count(filter SongFilter) (int, error)
+	// Update сохраняет песню. Возвращает ErrNotFound, если песни нет.
+	Update(song *models.Song, author string) error
+	// Delete перемещает песню в корзину и убирает её из всех плейлистов, запоминая её места
+	// в них. Песни в корзине не возвращаются остальными методами и не учитываются в счётчиках.
+	// Возвращает ErrNotFound, если песни нет или она уже в корзине.
+	Delete(id int) error
+	// DeleteIf перемещает песню в корзину, как Delete, только если match возвращает true для
+	// её текущего состояния с группой, альбомом, жанрами и тегами. Проверка и удаление
+	// выполняются атомарно: песню нельзя изменить между ними. Возвращает ErrNotFound, если
+	// песни нет, и ErrPreconditionFailed, если match вернул false.
+	DeleteIf(id int, match func(song models.Song) bool) error
+	// ListTrash возвращает песни в корзине от недавно удалённых к давно удалённым.
+	ListTrash(limit, offset int) ([]models.Song, error)
+	// Restore возвращает песню из корзины на прежние места в плейлистах, которые ещё
+	// существуют. Возвращает ErrNotFound, если песни нет в корзине.
+	Restore(id int) error
+	// Purge окончательно удаляет песню из корзины. Возвращает ErrNotFound, если песни нет
+	// в корзине.
+	Purge(id int) error
+	// PurgeTrash окончательно удаляет песни, попавшие в корзину раньше before, и возвращает
+	// их количество.
+	PurgeTrash(before time.Time) (int, error)
+	// SaveEnrichment записывает итог попытки обогащения песни: статус, число попыток,
+	// ошибку и время следующей попытки, а полученные данные — только в ещё пустые поля,
+	// чтобы не затереть изменения, сделанные во время запроса к источникам. Возвращает
+	// ErrNotFound, если песни нет.
+	SaveEnrichment(id int, result EnrichmentResult, author string) error
+	// ResetEnrichment переводит песню в статус pending со сброшенными счётчиком попыток,
+	// ошибкой и временем следующей попытки, не трогая остальные поля и не записывая
+	// ревизию. Возвращает обновлённую песню или ErrNotFound, если песни нет.
+	ResetEnrichment(id int) (*models.Song, error)
+	// ListDueForEnrichment возвращает ID песен в статусе pending, время следующей
+	// попытки обогащения которых не позже now, по возрастанию ID.
+	ListDueForEnrichment(now time.Time, limit int) ([]int, error)
+	// SetGenres заменяет жанры песни. Возвращает ErrNotFound, если песни нет.
+	SetGenres(songID int, genreIDs []int, author string) error
+	// SetTags заменяет теги песни. Возвращает ErrNotFound, если песни нет.
+	SetTags(songID int, tagIDs []int, author string) error
+	// Facets считает жанры и теги среди песен, подходящих под фильтр (без учёта пагинации).
+	Facets(filter SongFilter) (*SongFacets, error)
+	// GetLyrics возвращает разделы текста песни по порядку позиций. Возвращает ErrNotFound,
+	// если песни нет.
+	GetLyrics(songID int) ([]models.LyricsSection, error)
+	// SetLyrics заменяет разделы текста песни и записывает в текст песни собранный из них
+	// текст (см. lyrics.Render). Возвращает ErrNotFound, если песни нет.
+	SetLyrics(songID int, sections []models.LyricsSection, author string) error
+	// GetSyncedLyrics возвращает строки синхронизированного текста песни со словами по
+	// порядку позиций. Возвращает ErrNotFound, если песни нет.
+	GetSyncedLyrics(songID int) ([]models.LyricsLine, error)
+	// SetSyncedLyrics заменяет синхронизированный текст песни; пустой список удаляет его.
+	// Возвращает ErrNotFound, если песни нет.
+	SetSyncedLyrics(songID int, lines []models.LyricsLine, author string) error
+	// ListTranslations возвращает переводы текста песни в порядке кодов языков. Возвращает
+	// ErrNotFound, если песни нет.
+	ListTranslations(songID int) ([]models.SongTranslation, error)
+	// SetTranslation добавляет или заменяет перевод текста песни на язык language.
+	// Возвращает ErrNotFound, если песни нет.
+	SetTranslation(songID int, language, text, author string) error
+	// DeleteTranslation удаляет перевод текста песни на язык language. Возвращает
+	// ErrNotFound, если песни или перевода нет.
+	DeleteTranslation(songID int, language, author string) error
+	// ListRevisions возвращает ревизии песни по возрастанию номера. Возвращает ErrNotFound,
+	// если песни нет.
+	ListRevisions(songID int) ([]models.SongRevision, error)
+	// GetRevision возвращает ревизию песни по номеру. Возвращает ErrNotFound, если песни
+	// или ревизии нет.
+	GetRevision(songID, number int) (*models.SongRevision, error)
+}
+
+// EnrichmentResult — итог попытки обогащения песни (см. SongRepository.SaveEnrichment).
+type EnrichmentResult struct {
+	Status string
+	// Attempts — число попыток с учётом этой.
+	Attempts int
+	Error    string
+	NextAt   *time.Time
+	// ReleaseDate, Text и Link — данные из источников; нулевые значения не записываются.
+	ReleaseDate time.Time
+	Text        string
+	Link        string
+}
+
+// apply переносит итог обогащения в песню: статус заменяется, а данные из источников
+// записываются только в пустые поля.
+func (result EnrichmentResult) apply(song *models.Song) {
+	song.EnrichmentStatus = result.Status
+	song.EnrichmentAttempts = result.Attempts
+	song.EnrichmentError = result.Error
+	song.EnrichmentNextAt = result.NextAt
+	if song.ReleaseDate.IsZero() {
+		song.ReleaseDate = result.ReleaseDate
+	}
+	if song.Text == "" {
+		song.Text = result.Text
+	}
+	if song.Link == "" {
+		song.Link = result.Link
+	}
+}
+
+// TermCount — жанр или тег с количеством песен.
+type TermCount struct {
+	ID        int
+	Name      string
+	SongCount int
+}
+
+// SongFacets — количество песен по жанрам и тегам, по убыванию количества и затем по названию.
+type SongFacets struct {
+	Total  int
+	Genres []TermCount
+	Tags   []TermCount
+}
+
+// GroupWithSongCount — группа с количеством её песен.
+type GroupWithSongCount struct {
+	ID        int
+	Name      string
+	SongCount int
+}
+
+// NormalizeName приводит название группы к виду для сравнения:
+// нижний регистр, без пробелов по краям и повторных пробелов внутри.
+func NormalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// normalizeNames нормализует названия, отбрасывая пустые и повторяющиеся.
+func normalizeNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	var normalized []string
+	for _, name := range names {
+		name = NormalizeName(name)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized
+}
+
+// GroupRepository описывает хранилище музыкальных групп.
+// Группы ищутся по нормализованному названию (см. NormalizeName) и по псевдонимам.
+type GroupRepository interface {
+	// FindOrCreate возвращает группу с таким названием или псевдонимом либо создаёт новую.
+	FindOrCreate(name string) (*models.Group, error)
+	GetByID(id int) (*models.Group, error)
+	// FindByName возвращает группу с таким названием или псевдонимом либо ErrNotFound.
+	FindByName(name string) (*models.Group, error)
+	// List возвращает группы по возрастанию ID с количеством песен.
+	List(limit, offset int) ([]GroupWithSongCount, error)
+	// Update переименовывает группу. Возвращает ErrNameConflict, если название занято
+	// другой группой или её псевдонимом; собственные псевдонимы группы ему не мешают.
+	Update(group *models.Group) error
+	// Delete удаляет группу вместе с её альбомами. Если у группы есть песни, в том числе в
+	// корзине, они удаляются окончательно вместе с ней при deleteSongs = true, иначе
+	// возвращается ErrGroupHasSongs.
+	Delete(id int, deleteSongs bool) error
+	ListAliases(groupID int) ([]models.GroupAlias, error)
+	// AddAlias добавляет группе псевдоним. Возвращает ErrNameConflict, если название
+	// уже используется другой группой или псевдонимом.
+	AddAlias(groupID int, name string) (*models.GroupAlias, error)
+	DeleteAlias(groupID, aliasID int) error
+	// Merge атомарно переносит песни, альбомы и псевдонимы групп sourceIDs в группу targetID,
+	// сохраняет названия исходных групп как псевдонимы и удаляет исходные группы. Смена
+	// группы каждой перенесённой песни записывается ревизией от имени author.
+	Merge(targetID int, sourceIDs []int, author string) error
+}
+
+// AlbumFilter описывает параметры фильтрации и пагинации списка альбомов.
+type AlbumFilter struct {
+	GroupID int
+	Group   string
+	Title   string
+	Limit   int
+	Offset  int
+}
+
+// AlbumWithTrackCount — альбом с названием группы и количеством треков.
+type AlbumWithTrackCount struct {
+	ID          int
+	GroupID     int
+	GroupName   string
+	Title       string
+	ReleaseDate time.Time
+	CoverLink   string
+	TrackCount  int
+}
+
+// AlbumRepository описывает хранилище альбомов.
+type AlbumRepository interface {
+	Create(album *models.Album) error
+	// GetByID возвращает альбом вместе с группой.
+	GetByID(id int) (*models.Album, error)
+	// List возвращает альбомы по возрастанию ID с количеством треков.
+	List(filter AlbumFilter) ([]AlbumWithTrackCount, error)
+	Update(album *models.Album) error
+	// Delete удаляет альбом. Песни альбома сохраняются, но отвязываются от него.
+	Delete(id int) error
+	// ListTracks возвращает песни альбома по номеру диска, номеру трека и ID.
+	ListTracks(albumID int) ([]models.Song, error)
+}
+
+// GenreRepository описывает справочник жанров.
+type GenreRepository interface {
+	// Create добавляет жанр. Возвращает ErrNameConflict, если жанр с таким названием уже есть.
+	Create(name string) (*models.Genre, error)
+	GetByID(id int) (*models.Genre, error)
+	// FindByNames возвращает существующие жанры с такими названиями.
+	FindByNames(names []string) ([]models.Genre, error)
+	// List возвращает все жанры по названию с количеством песен.
+	List() ([]TermCount, error)
+	// Update переименовывает жанр. Возвращает ErrNameConflict, если название занято.
+	Update(genre *models.Genre) error
+	// Delete удаляет жанр и его связи с песнями.
+	Delete(id int) error
+}
+
+// TagRepository описывает хранилище тегов.
+type TagRepository interface {
+	// FindOrCreate возвращает теги с такими названиями, создавая недостающие.
+	FindOrCreate(names []string) ([]models.Tag, error)
+	// List возвращает теги по убыванию количества песен и затем по названию.
+	List(limit, offset int) ([]TermCount, error)
+	// Delete удаляет тег и его связи с песнями.
+	Delete(id int) error
+}
+
+// PlaylistWithSongCount — плейлист с количеством песен.
+type PlaylistWithSongCount struct {
+	ID          int
+	Name        string
+	Description string
+	SongCount   int
+}
+
+// PlaylistRepository описывает хранилище плейлистов.
+// Позиции песен в плейлисте нумеруются с 1 и пересчитываются после каждого изменения.
+type PlaylistRepository interface {
+	Create(playlist *models.Playlist) error
+	GetByID(id int) (*models.Playlist, error)
+	// List возвращает плейлисты по возрастанию ID с количеством песен.
+	List(limit, offset int) ([]PlaylistWithSongCount, error)
+	Update(playlist *models.Playlist) error
+	// Delete удаляет плейлист. Сами песни не удаляются.
+	Delete(id int) error
+	// ListSongs возвращает песни плейлиста в порядке позиций.
+	ListSongs(playlistID, limit, offset int) ([]models.Song, error)
+	// AddSong вставляет песню на позицию position, сдвигая последующие. Позиция 0 или
+	// больше числа песен означает добавление в конец. Возвращает ErrNotFound, если нет
+	// плейлиста или песни.
+	AddSong(playlistID, songID, position int) error
+	// RemoveSong удаляет песню с позиции position. Возвращает ErrNotFound, если позиции нет.
+	RemoveSong(playlistID, position int) error
+	// MoveSong переносит песню с позиции from на позицию to (не дальше последней).
+	// Возвращает ErrNotFound, если позиции from нет.
+	MoveSong(playlistID, from, to int) error
+}
+
+// MetadataCacheRepository описывает постоянное хранилище кэша источников метаданных.
+type MetadataCacheRepository interface {
+	// Get возвращает запись по ключу или ErrNotFound. Срок действия записи не проверяется.
+	Get(key string) (*models.MetadataCacheEntry, error)
+	// Put сохраняет запись, заменяя существующую с тем же ключом.
+	Put(entry *models.MetadataCacheEntry) error
+	// DeleteExpired удаляет записи, срок действия которых истёк к моменту now.
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+// IdempotencyRepository описывает хранилище ответов на запросы с ключом идемпотентности.
+type IdempotencyRepository interface {
+	// Get возвращает ответ по ключу или ErrNotFound. Срок действия ответа не проверяется.
+	Get(key string) (*models.IdempotentResponse, error)
+	// Reserve сохраняет резервацию ключа (ответ со статусом 0), если ответа с тем же ключом
+	// нет или его срок действия истёк к моменту now. Возвращает false, если ключ занят: из
+	// одновременных запросов с одним ключом резервацию получает только один.
+	Reserve(response *models.IdempotentResponse, now time.Time) (bool, error)
+	// Complete записывает ответ на зарезервированный ключ.
+	Complete(response *models.IdempotentResponse) error
+	// Release снимает резервацию ключа, ответ на который так и не записан.
+	Release(key string) error
+	// DeleteExpired удаляет ответы, срок действия которых истёк к моменту now.
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+// Storage объединяет репозитории одного хранилища.
+type Storage struct {
+	Songs         SongRepository
+	Groups        GroupRepository
+	Albums        AlbumRepository
+	Genres        GenreRepository
+	Tags          TagRepository
+	Playlists     PlaylistRepository
+	MetadataCache MetadataCacheRepository
+	// IdempotencyKeys хранит ответы на запросы с заголовком Idempotency-Key.
+	IdempotencyKeys IdempotencyRepository
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"encoding/json"
+	"music_storage/internal/lyrics"
+	"music_storage/internal/models"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RevisionFields — поля песни, изменения которых записываются в ревизии, в порядке вывода.
+// Названия совпадают с полями запроса на изменение песни; группа, альбом, жанры и теги
+// хранятся по ID, синхронизированный текст — в формате LRC, переводы — объектом JSON
+// с текстами по кодам языков.
+var RevisionFields = []string{
+	"song", "groupId", "albumId", "discNumber", "trackNumber",
+	"releaseDate", "duration", "text", "link", "language",
+	"genreIds", "tagIds", "syncedLyrics", "translations",
+}
+
+// SongFields возвращает значения отслеживаемых полей песни строками. Пустые дата выпуска
+// и альбом записываются пустой строкой.
+func SongFields(song models.Song) map[string]string {
+	fields := map[string]string{
+		"song":        song.Song,
+		"groupId":     strconv.Itoa(song.GroupID),
+		"albumId":     "",
+		"discNumber":  strconv.Itoa(song.DiscNumber),
+		"trackNumber": strconv.Itoa(song.TrackNumber),
+		"releaseDate": "",
+		"duration":    strconv.Itoa(song.Duration),
+		"text":        song.Text,
+		"link":        song.Link,
+		"language":    song.Language,
+	}
+	if song.AlbumID != nil {
+		fields["albumId"] = strconv.Itoa(*song.AlbumID)
+	}
+	if !song.ReleaseDate.IsZero() {
+		fields["releaseDate"] = song.ReleaseDate.Format("2006-01-02")
+	}
+	return fields
+}
+
+// songAssociations — данные песни, которые хранятся вне строки песни, но записываются
+// в ревизии.
+type songAssociations struct {
+	GenreIDs     []int
+	TagIDs       []int
+	SyncedLyrics []models.LyricsLine
+	Translations map[string]string
+}
+
+// revisionFields возвращает значения всех полей ревизии: поля песни (см. SongFields)
+// и связанные с ней данные. Пустые списки записываются пустой строкой.
+func revisionFields(song models.Song, associations songAssociations) map[string]string {
+	fields := SongFields(song)
+	fields["genreIds"] = joinIDs(associations.GenreIDs)
+	fields["tagIds"] = joinIDs(associations.TagIDs)
+	fields["syncedLyrics"] = ""
+	if len(associations.SyncedLyrics) > 0 {
+		fields["syncedLyrics"] = lyrics.FormatLRC(associations.SyncedLyrics, nil)
+	}
+	fields["translations"] = ""
+	if len(associations.Translations) > 0 {
+		data, _ := json.Marshal(associations.Translations)
+		fields["translations"] = string(data)
+	}
+	return fields
+}
+
+// joinIDs возвращает ID по возрастанию через запятую.
+func joinIDs(ids []int) string {
+	sorted := append([]int(nil), ids...)
+	sort.Ints(sorted)
+	parts := make([]string, len(sorted))
+	for i, id := range sorted {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ",")
+}
+
+// DiffFields возвращает поля, значения которых различаются в from и to, в порядке RevisionFields.
+func DiffFields(from, to map[string]string) []models.FieldChange {
+	var changes []models.FieldChange
+	for _, field := range RevisionFields {
+		if from[field] != to[field] {
+			changes = append(changes, models.FieldChange{Field: field, Old: from[field], New: to[field]})
+		}
+	}
+	return changes
+}
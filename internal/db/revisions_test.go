@@ -0,0 +1,168 @@
+package db_test
+
+import (
+	"errors"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// lastRevision возвращает последнюю ревизию песни и число её ревизий.
+func lastRevision(t *testing.T, storage *db.Storage, songID int) (models.SongRevision, int) {
+	t.Helper()
+	revisions, err := storage.Songs.ListRevisions(songID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) == 0 {
+		t.Fatalf("у песни %d нет ревизий", songID)
+	}
+	return revisions[len(revisions)-1], len(revisions)
+}
+
+// changedFields возвращает названия изменённых полей ревизии.
+func changedFields(revision models.SongRevision) []string {
+	fields := make([]string, 0, len(revision.Changes))
+	for _, change := range revision.Changes {
+		fields = append(fields, change.Field)
+	}
+	return fields
+}
+
+func TestAssociationChangesRecordRevisions(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			song := createSong(t, storage, "Queen", models.Song{Song: "Innuendo"})
+			rock, err := storage.Genres.Create("Rock")
+			if err != nil {
+				t.Fatal(err)
+			}
+			pop, err := storage.Genres.Create("Pop")
+			if err != nil {
+				t.Fatal(err)
+			}
+			tags, err := storage.Tags.FindOrCreate([]string{"live"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := []models.LyricsLine{{StartMs: 1000, Text: "Первая"}, {StartMs: 2500, Text: "Вторая"}}
+
+			steps := []struct {
+				name   string
+				change func() error
+				field  string
+				want   string
+			}{
+				{"жанры", func() error { return storage.Songs.SetGenres(song.ID, []int{pop.ID, rock.ID}, "alice") },
+					"genreIds", strconv.Itoa(rock.ID) + "," + strconv.Itoa(pop.ID)},
+				{"теги", func() error { return storage.Songs.SetTags(song.ID, []int{tags[0].ID}, "alice") },
+					"tagIds", strconv.Itoa(tags[0].ID)},
+				{"синхронизированный текст", func() error { return storage.Songs.SetSyncedLyrics(song.ID, lines, "alice") },
+					"syncedLyrics", "[00:01.00]Первая\n[00:02.50]Вторая\n"},
+				{"перевод", func() error { return storage.Songs.SetTranslation(song.ID, "en", "First", "alice") },
+					"translations", `{"en":"First"}`},
+				{"второй перевод", func() error { return storage.Songs.SetTranslation(song.ID, "de", "Erste", "alice") },
+					"translations", `{"de":"Erste","en":"First"}`},
+				{"удаление перевода", func() error { return storage.Songs.DeleteTranslation(song.ID, "de", "alice") },
+					"translations", `{"en":"First"}`},
+				{"удаление синхронизированного текста", func() error { return storage.Songs.SetSyncedLyrics(song.ID, nil, "alice") },
+					"syncedLyrics", ""},
+				{"снятие жанров", func() error { return storage.Songs.SetGenres(song.ID, nil, "alice") },
+					"genreIds", ""},
+			}
+			for _, step := range steps {
+				_, before := lastRevision(t, storage, song.ID)
+				if err := step.change(); err != nil {
+					t.Fatalf("%s: %v", step.name, err)
+				}
+				revision, count := lastRevision(t, storage, song.ID)
+				if count != before+1 {
+					t.Fatalf("%s: ревизий %d, ожидалось %d", step.name, count, before+1)
+				}
+				if fields := changedFields(revision); !slices.Equal(fields, []string{step.field}) || revision.Author != "alice" {
+					t.Errorf("%s: изменены поля %v автором %q", step.name, fields, revision.Author)
+				}
+				if got := revision.Fields[step.field]; got != step.want {
+					t.Errorf("%s: %s = %q, ожидалось %q", step.name, step.field, got, step.want)
+				}
+				if revision.Fields["song"] != "Innuendo" {
+					t.Errorf("%s: в ревизии нет полей песни: %v", step.name, revision.Fields)
+				}
+			}
+
+			// Повторная установка тех же значений ревизию не создаёт.
+			_, before := lastRevision(t, storage, song.ID)
+			if err := storage.Songs.SetTags(song.ID, []int{tags[0].ID, tags[0].ID}, "bob"); err != nil {
+				t.Fatal(err)
+			}
+			if err := storage.Songs.SetTranslation(song.ID, "en", "First", "bob"); err != nil {
+				t.Fatal(err)
+			}
+			if _, after := lastRevision(t, storage, song.ID); after != before {
+				t.Errorf("без изменений записано ревизий: %d", after-before)
+			}
+
+			// Изменение полей песни сохраняет в ревизии текущие теги и переводы.
+			changed := song
+			changed.Link = "https://example.com"
+			if err := storage.Songs.Update(&changed, "bob"); err != nil {
+				t.Fatal(err)
+			}
+			revision, _ := lastRevision(t, storage, song.ID)
+			if fields := changedFields(revision); !slices.Equal(fields, []string{"link"}) ||
+				revision.Fields["tagIds"] != strconv.Itoa(tags[0].ID) || revision.Fields["translations"] != `{"en":"First"}` {
+				t.Errorf("ревизия изменения ссылки: изменены %v, поля %v", fields, revision.Fields)
+			}
+
+			for name, err := range map[string]error{
+				"жанры":             storage.Songs.SetGenres(1000, []int{rock.ID}, "alice"),
+				"теги":              storage.Songs.SetTags(1000, nil, "alice"),
+				"текст":             storage.Songs.SetSyncedLyrics(1000, lines, "alice"),
+				"перевод":           storage.Songs.SetTranslation(1000, "en", "x", "alice"),
+				"удаление перевода": storage.Songs.DeleteTranslation(song.ID, "fr", "alice"),
+			} {
+				if !errors.Is(err, db.ErrNotFound) {
+					t.Errorf("%s: %v, ожидалась ErrNotFound", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeGroupsRecordsRevisions(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			queen := createSong(t, storage, "Queen", models.Song{Song: "Innuendo"})
+			live := createSong(t, storage, "Queen Live", models.Song{Song: "Innuendo (Live)"})
+			trashed := createSong(t, storage, "Queen Live", models.Song{Song: "Mustapha (Live)"})
+			if err := storage.Songs.Delete(trashed.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := storage.Groups.Merge(queen.GroupID, []int{live.GroupID}, "alice"); err != nil {
+				t.Fatal(err)
+			}
+
+			revision, count := lastRevision(t, storage, live.ID)
+			if count != 2 || !slices.Equal(changedFields(revision), []string{"groupId"}) || revision.Author != "alice" {
+				t.Fatalf("ревизия перенесённой песни: %+v, всего %d", revision, count)
+			}
+			if change := revision.Changes[0]; change.Old != strconv.Itoa(live.GroupID) || change.New != strconv.Itoa(queen.GroupID) {
+				t.Errorf("изменение группы: %+v", change)
+			}
+			if _, count := lastRevision(t, storage, queen.ID); count != 1 {
+				t.Errorf("у песни целевой группы %d ревизий, ожидалась 1", count)
+			}
+
+			if err := storage.Songs.Restore(trashed.ID); err != nil {
+				t.Fatal(err)
+			}
+			revision, _ = lastRevision(t, storage, trashed.ID)
+			if revision.Fields["groupId"] != strconv.Itoa(queen.GroupID) {
+				t.Errorf("песня из корзины: последняя ревизия %+v", revision)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package db
+
+import (
+	"music_storage/internal/lyrics"
+	"music_storage/internal/models"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Поиск в приложении используется, когда база данных не умеет полнотекстовый поиск
+// и сравнение по триграммам (SQLite, хранилище в памяти). Слово запроса совпадает со
+// словом песни, если является его началом без учёта регистра: «любов» находит «любовь»
+// и «любовью». Морфология и операторы запроса не поддерживаются. Сходство названий
+// считается по триграммам так же, как similarity() из pg_trgm.
+
+const (
+	snippetStartSel = "<b>"
+	snippetStopSel  = "</b>"
+)
+
+// searchTerms разбивает запрос на слова в нижнем регистре без повторов.
+func searchTerms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(query), isNotWordRune) {
+		if !seen[word] {
+			seen[word] = true
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// songMatcher проверяет песни на соответствие поисковому запросу и нечёткому сравнению
+// названий фильтра.
+type songMatcher struct {
+	query     bool
+	terms     []string
+	fuzzy     bool
+	song      string
+	group     string
+	threshold float64
+}
+
+func newSongMatcher(filter SongFilter) songMatcher {
+	return songMatcher{
+		query:     filter.Query != "",
+		terms:     searchTerms(filter.Query),
+		fuzzy:     filter.Fuzzy,
+		song:      filter.Song,
+		group:     NormalizeName(filter.Group),
+		threshold: similarityThreshold(filter),
+	}
+}
+
+// match проверяет песню с загруженной группой и возвращает результат поиска, как у SongRepository.Search.
+func (m songMatcher) match(song models.Song) (SongSearchResult, bool) {
+	result := SongSearchResult{Song: song}
+	if m.query {
+		var ok bool
+		if result, ok = matchQuery(song, m.terms); !ok {
+			return SongSearchResult{}, false
+		}
+	}
+	if m.fuzzy {
+		var scores []float64
+		if m.song != "" {
+			scores = append(scores, trigramSimilarity(song.Song, m.song))
+		}
+		if m.group != "" {
+			scores = append(scores, trigramSimilarity(song.Group.NormalizedName, m.group))
+		}
+		for _, score := range scores {
+			if score < m.threshold {
+				return SongSearchResult{}, false
+			}
+			result.Score += score / float64(len(scores))
+		}
+	}
+	return result, true
+}
+
+// matchQuery проверяет, что каждое из terms встречается в названии или тексте песни,
+// и считает релевантность и фрагмент текста. Совпадение в названии весит вдвое больше
+// совпадения в тексте.
+func matchQuery(song models.Song, terms []string) (SongSearchResult, bool) {
+	if len(terms) == 0 {
+		return SongSearchResult{}, false
+	}
+
+	titleHits := countTermHits(song.Song, terms)
+	textHits := countTermHits(song.Text, terms)
+	score := 0
+	for i := range terms {
+		if titleHits[i]+textHits[i] == 0 {
+			return SongSearchResult{}, false
+		}
+		score += 2*titleHits[i] + textHits[i]
+	}
+
+	result := SongSearchResult{Song: song, Rank: float64(score) / float64(score+1)}
+	best := 0
+	for _, verse := range lyrics.Split(song.Text) {
+		if snippet, hits := highlightVerse(verse, terms); hits > best {
+			result.Snippet, best = snippet, hits
+		}
+	}
+	return result, true
+}
+
+// countTermHits считает для каждого из terms количество слов text, которые с него начинаются.
+func countTermHits(text string, terms []string) []int {
+	hits := make([]int, len(terms))
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), isNotWordRune) {
+		for i, term := range terms {
+			if strings.HasPrefix(word, term) {
+				hits[i]++
+			}
+		}
+	}
+	return hits
+}
+
+// highlightVerse выделяет в куплете слова, совпавшие с terms, и возвращает количество совпадений.
+func highlightVerse(verse string, terms []string) (string, int) {
+	var b strings.Builder
+	hits := 0
+	writeWord := func(word string) {
+		lower := strings.ToLower(word)
+		for _, term := range terms {
+			if strings.HasPrefix(lower, term) {
+				b.WriteString(snippetStartSel + word + snippetStopSel)
+				hits++
+				return
+			}
+		}
+		b.WriteString(word)
+	}
+
+	start := -1
+	for i, r := range verse {
+		if !isNotWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			writeWord(verse[start:i])
+			start = -1
+		}
+		b.WriteRune(r)
+	}
+	if start >= 0 {
+		writeWord(verse[start:])
+	}
+	return b.String(), hits
+}
+
+// trigrams возвращает множество триграмм строки: каждое слово в нижнем регистре дополняется
+// двумя пробелами в начале и одним в конце, как в pg_trgm.
+func trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(s), isNotWordRune) {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// trigramSimilarity возвращает долю общих триграмм двух строк от 0 до 1.
+func trigramSimilarity(a, b string) float64 {
+	setA, setB := trigrams(a), trigrams(b)
+	common := 0
+	for trigram := range setA {
+		if setB[trigram] {
+			common++
+		}
+	}
+	total := len(setA) + len(setB) - common
+	if total == 0 {
+		return 0
+	}
+	return float64(common) / float64(total)
+}
+
+// similarityThreshold возвращает минимальное сходство названий для фильтра.
+func similarityThreshold(filter SongFilter) float64 {
+	if filter.Similarity > 0 {
+		return filter.Similarity
+	}
+	return DefaultSimilarity
+}
+
+// rankSearchResults упорядочивает результаты поиска так же, как SongRepository.Search,
+// и применяет к ним курсор и пагинацию фильтра.
+func rankSearchResults(results []SongSearchResult, filter SongFilter) []SongSearchResult {
+	if len(filter.Sort) > 0 {
+		sort.Slice(results, func(i, j int) bool {
+			return compareSongs(results[i].Song, results[j].Song, filter.Sort) < 0
+		})
+		return paginate(results, filter, func(result SongSearchResult) models.Song { return result.Song })
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Song.ID < results[j].Song.ID
+	})
+	filter.Cursor = nil
+	return paginate(results, filter, func(result SongSearchResult) models.Song { return result.Song })
+}
+
+// searchResultSongs возвращает песни из результатов поиска в том же порядке.
+func searchResultSongs(results []SongSearchResult) []models.Song {
+	songs := make([]models.Song, 0, len(results))
+	for _, result := range results {
+		songs = append(songs, result.Song)
+	}
+	return songs
+}
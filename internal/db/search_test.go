@@ -0,0 +1,144 @@
+package db
+
+import (
+	"math"
+	"music_storage/internal/models"
+	"slices"
+	"testing"
+)
+
+func TestSearchTerms(t *testing.T) {
+	if got := searchTerms("Love, LOVE me — «любовь»!"); !slices.Equal(got, []string{"love", "me", "любовь"}) {
+		t.Errorf("searchTerms() = %q", got)
+	}
+	if got := searchTerms(" ,.! "); len(got) != 0 {
+		t.Errorf("searchTerms() без слов = %q", got)
+	}
+}
+
+func TestMatchQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		song    models.Song
+		terms   []string
+		ok      bool
+		rank    float64
+		snippet string
+	}{
+		{"совпадение в названии весит вдвое больше",
+			models.Song{Song: "Любовь", Text: "Моя любовь\n\nДругой куплет"}, []string{"любов"},
+			true, 0.75, "Моя <b>любовь</b>"},
+		{"только название — без фрагмента",
+			models.Song{Song: "Innuendo", Text: "While the sun hangs in the sky"}, []string{"innuendo"},
+			true, 2.0 / 3, ""},
+		{"фрагмент — куплет с наибольшим числом совпадений",
+			models.Song{Song: "Other", Text: "Love me\n\nI love LOVE you"}, []string{"love"},
+			true, 0.75, "I <b>love</b> <b>LOVE</b> you"},
+		{"куплеты разделены строкой из пробелов и CRLF",
+			models.Song{Song: "Other", Text: "First verse\r\n  \r\nlove you\n\t\n\nlove, love!"}, []string{"love"},
+			true, 0.75, "<b>love</b>, <b>love</b>!"},
+		{"нужны все слова запроса",
+			models.Song{Song: "Любовь", Text: "Моя любовь"}, []string{"любов", "море"},
+			false, 0, ""},
+		{"слово совпадает только с начала",
+			models.Song{Song: "Love", Text: "love"}, []string{"ove"},
+			false, 0, ""},
+		{"пустой запрос",
+			models.Song{Song: "Love", Text: "love"}, nil,
+			false, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := matchQuery(tt.song, tt.terms)
+			if ok != tt.ok {
+				t.Fatalf("matchQuery() ok = %v, ожидалось %v", ok, tt.ok)
+			}
+			if result.Rank != tt.rank || result.Snippet != tt.snippet {
+				t.Errorf("matchQuery() = rank %v, snippet %q, ожидалось %v и %q", result.Rank, result.Snippet, tt.rank, tt.snippet)
+			}
+		})
+	}
+}
+
+func TestTrigramSimilarity(t *testing.T) {
+	// Ожидаемые значения совпадают с similarity() из pg_trgm.
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"word", "two words", 0.36363637},
+		{"Bohemian Rhapsody", "Bohemian Rapsody", 0.75},
+		{"Queen", "quen", 0.5714286},
+		{"rock'n'roll", "rock and roll", 0.5714286},
+		{"любовь", "любви", 0.3},
+		{"cat", "CAT", 1},
+		{"abc", "xyz", 0},
+		{"", "", 0},
+		{"", "cat", 0},
+	}
+	for _, tt := range tests {
+		got := trigramSimilarity(tt.a, tt.b)
+		if math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("trigramSimilarity(%q, %q) = %v, ожидалось %v", tt.a, tt.b, got, tt.want)
+		}
+		if reverse := trigramSimilarity(tt.b, tt.a); reverse != got {
+			t.Errorf("trigramSimilarity(%q, %q) = %v несимметрично: %v", tt.b, tt.a, reverse, got)
+		}
+	}
+}
+
+func TestSimilarityThreshold(t *testing.T) {
+	tests := []struct {
+		similarity float64
+		want       float64
+	}{
+		{0, DefaultSimilarity},
+		{-0.5, DefaultSimilarity},
+		{0.1, 0.1},
+		{1, 1},
+	}
+	for _, tt := range tests {
+		if got := similarityThreshold(SongFilter{Similarity: tt.similarity}); got != tt.want {
+			t.Errorf("similarityThreshold(%v) = %v, ожидалось %v", tt.similarity, got, tt.want)
+		}
+	}
+}
+
+func TestRankSearchResults(t *testing.T) {
+	results := func() []SongSearchResult {
+		return []SongSearchResult{
+			{Song: models.Song{ID: 1, Song: "Bijou"}, Rank: 0.5, Score: 0.4},
+			{Song: models.Song{ID: 2, Song: "Innuendo"}, Rank: 0.75, Score: 0.3},
+			{Song: models.Song{ID: 3, Song: "Mustapha"}, Rank: 0.5, Score: 0.9},
+			{Song: models.Song{ID: 4, Song: "Headlong"}, Rank: 0.5, Score: 0.4},
+			{Song: models.Song{ID: 5, Song: "Delilah"}, Rank: 0.75, Score: 0.3},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter SongFilter
+		want   []int
+	}{
+		{"по релевантности, сходству и ID", SongFilter{}, []int{2, 5, 3, 1, 4}},
+		{"страница", SongFilter{Limit: 2, Offset: 1}, []int{5, 3}},
+		{"за пределами результатов", SongFilter{Offset: 5}, nil},
+		{"курсор без сортировки не применяется",
+			SongFilter{Cursor: &SongCursor{Key: SongSortKey{ID: 3}}}, []int{2, 5, 3, 1, 4}},
+		{"по заданной сортировке", SongFilter{Sort: []SortField{{Field: SortSong}}}, []int{1, 5, 4, 2, 3}},
+		{"по сортировке с курсором",
+			SongFilter{Sort: []SortField{{Field: SortSong, Desc: true}}, Cursor: &SongCursor{Key: SongSortKey{ID: 2, Song: "Innuendo"}}, Limit: 2},
+			[]int{4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []int
+			for _, result := range rankSearchResults(results(), tt.filter) {
+				ids = append(ids, result.Song.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("rankSearchResults() = %v, ожидалось %v", ids, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package db_test
+
+import (
+	"errors"
+	"fmt"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDeleteIf(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			song := createSong(t, storage, "Queen", models.Song{Song: "Bohemian Rhapsody", Link: "https://example.com/a"})
+
+			var seen models.Song
+			err := storage.Songs.DeleteIf(song.ID, func(current models.Song) bool {
+				seen = current
+				return false
+			})
+			if !errors.Is(err, db.ErrPreconditionFailed) {
+				t.Fatalf("DeleteIf с неподходящим состоянием: %v, ожидалась ErrPreconditionFailed", err)
+			}
+			if seen.Link != song.Link || seen.Group.Name != "Queen" {
+				t.Errorf("match получил песню %+v", seen)
+			}
+			if _, err := storage.Songs.GetByID(song.ID); err != nil {
+				t.Fatalf("песня удалена, хотя условие не выполнено: %v", err)
+			}
+
+			if err := storage.Songs.DeleteIf(song.ID, func(models.Song) bool { return true }); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := storage.Songs.GetByID(song.ID); !errors.Is(err, db.ErrNotFound) {
+				t.Fatalf("песня не удалена: %v", err)
+			}
+			if err := storage.Songs.DeleteIf(song.ID, func(models.Song) bool { return true }); !errors.Is(err, db.ErrNotFound) {
+				t.Fatalf("повторное удаление: %v, ожидалась ErrNotFound", err)
+			}
+			trash, err := storage.Songs.ListTrash(10, 0)
+			if err != nil || len(trash) != 1 || trash[0].ID != song.ID {
+				t.Fatalf("корзина: %v, %v", trash, err)
+			}
+		})
+	}
+}
+
+func TestCreateManyResolvesGroups(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			queen := createSong(t, storage, "Queen", models.Song{Song: "Bohemian Rhapsody"})
+
+			songs := []models.Song{
+				{Group: models.Group{Name: "  queen "}, Song: "Innuendo"},
+				{Group: models.Group{Name: "Кино"}, Song: "Группа крови"},
+				{Group: models.Group{Name: "КИНО"}, Song: "Кукушка"},
+			}
+			if err := storage.Songs.CreateMany(songs, "test"); err != nil {
+				t.Fatal(err)
+			}
+			if songs[0].GroupID != queen.GroupID {
+				t.Errorf("песня добавлена в группу %d, ожидалась %d", songs[0].GroupID, queen.GroupID)
+			}
+			if songs[1].GroupID == 0 || songs[2].GroupID != songs[1].GroupID || songs[1].Group.Name != "Кино" {
+				t.Errorf("группы новых песен: %+v, %+v", songs[1].Group, songs[2].Group)
+			}
+		})
+	}
+}
+
+func TestCreateManyRollsBackNewGroups(t *testing.T) {
+	storage := storages(t)["sqlite"]
+	existing := createSong(t, storage, "Queen", models.Song{Song: "Bohemian Rhapsody"})
+
+	// Вторая песня нарушает первичный ключ, и часть откатывается вместе с группой первой.
+	songs := []models.Song{
+		{Group: models.Group{Name: "Кино"}, Song: "Группа крови"},
+		{ID: existing.ID, GroupID: existing.GroupID, Song: "Innuendo"},
+	}
+	if err := storage.Songs.CreateMany(songs, "test"); err == nil {
+		t.Fatal("CreateMany сохранил песню с занятым ID")
+	}
+	if group, err := storage.Groups.FindByName("Кино"); !errors.Is(err, db.ErrNotFound) {
+		t.Fatalf("после отката осталась группа %+v, %v", group, err)
+	}
+}
+
+func TestConcurrentUpdatesNumberRevisions(t *testing.T) {
+	const updates = 8
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			song := createSong(t, storage, "Queen", models.Song{Song: "Bohemian Rhapsody"})
+
+			var wg sync.WaitGroup
+			errs := make(chan error, updates)
+			for i := 0; i < updates; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					changed := song
+					changed.Link = fmt.Sprintf("https://example.com/%d", i)
+					errs <- storage.Songs.Update(&changed, "test")
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				if err != nil {
+					t.Errorf("Update: %v", err)
+				}
+			}
+
+			revisions, err := storage.Songs.ListRevisions(song.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(revisions) != updates+1 {
+				t.Fatalf("записано ревизий: %d, ожидалось %d", len(revisions), updates+1)
+			}
+			for i, revision := range revisions {
+				if revision.Number != i+1 {
+					t.Errorf("ревизия %d имеет номер %d", i+1, revision.Number)
+				}
+			}
+		})
+	}
+}
+
+func TestResetEnrichment(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			song := createSong(t, storage, "Queen", models.Song{Song: "Innuendo", Link: "https://example.com"})
+			failed := db.EnrichmentResult{Status: models.EnrichmentFailed, Attempts: 5, Error: "источник недоступен"}
+			if err := storage.Songs.SaveEnrichment(song.ID, failed, "enrichment"); err != nil {
+				t.Fatal(err)
+			}
+			before, err := storage.Songs.ListRevisions(song.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Правка, сделанная после чтения песни перед сбросом, должна сохраниться.
+			changed := song
+			changed.Link = "https://example.com/innuendo"
+			if err := storage.Songs.Update(&changed, "test"); err != nil {
+				t.Fatal(err)
+			}
+
+			reset, err := storage.Songs.ResetEnrichment(song.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reset.EnrichmentStatus != models.EnrichmentPending || reset.EnrichmentAttempts != 0 ||
+				reset.EnrichmentError != "" || reset.EnrichmentNextAt != nil {
+				t.Errorf("состояние обогащения после сброса: %+v", reset)
+			}
+			stored, err := storage.Songs.GetByID(song.ID)
+			if err != nil || stored.Link != changed.Link || stored.EnrichmentStatus != models.EnrichmentPending {
+				t.Fatalf("песня после сброса: %+v, %v", stored, err)
+			}
+			revisions, err := storage.Songs.ListRevisions(song.ID)
+			if err != nil || len(revisions) != len(before)+1 {
+				t.Errorf("ревизий после правки и сброса: %d, %v, ожидалось %d", len(revisions), err, len(before)+1)
+			}
+
+			if err := storage.Songs.Delete(song.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := storage.Songs.ResetEnrichment(song.ID); !errors.Is(err, db.ErrNotFound) {
+				t.Errorf("сброс песни в корзине: %v, ожидалась ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			love := createSong(t, storage, "Queen", models.Song{Song: "Love of My Life", Text: "Love of my life\n\nYou've hurt me"})
+			other := createSong(t, storage, "Queen", models.Song{Song: "Innuendo", Text: "While the sun\n \nI love you"})
+			light := createSong(t, storage, "ABBA", models.Song{Song: "Lovelight", Text: "Shine"})
+			createSong(t, storage, "Queen", models.Song{Song: "Mustapha", Text: "Ibrahim"})
+			trashed := createSong(t, storage, "Queen", models.Song{Song: "Love Kills"})
+			if err := storage.Songs.Delete(trashed.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			results, err := storage.Songs.Search(db.SongFilter{Query: "LOVE"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []struct {
+				id      int
+				rank    float64
+				snippet string
+			}{
+				{love.ID, 0.75, "<b>Love</b> of my life"},
+				{light.ID, 2.0 / 3, ""},
+				{other.ID, 0.5, "I <b>love</b> you"},
+			}
+			if len(results) != len(want) {
+				t.Fatalf("найдено %d песен, ожидалось %d", len(results), len(want))
+			}
+			for i, w := range want {
+				if r := results[i]; r.Song.ID != w.id || r.Rank != w.rank || r.Snippet != w.snippet || r.Song.Group.Name == "" {
+					t.Errorf("результат %d: песня %d, rank %v, snippet %q, группа %q; ожидалось %d, %v, %q",
+						i, r.Song.ID, r.Rank, r.Snippet, r.Song.Group.Name, w.id, w.rank, w.snippet)
+				}
+			}
+
+			for _, tc := range []struct {
+				filter db.SongFilter
+				want   []int
+			}{
+				{db.SongFilter{Query: "love", Limit: 1, Offset: 1}, []int{light.ID}},
+				{db.SongFilter{Query: "love", Group: "Queen"}, []int{love.ID, other.ID}},
+				{db.SongFilter{Query: "love life"}, []int{love.ID}},
+				{db.SongFilter{Query: "ove"}, nil},
+			} {
+				results, err := storage.Songs.Search(tc.filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var ids []int
+				for _, result := range results {
+					ids = append(ids, result.Song.ID)
+				}
+				if fmt.Sprint(ids) != fmt.Sprint(tc.want) {
+					t.Errorf("Search(%+v) = %v, ожидалось %v", tc.filter, ids, tc.want)
+				}
+			}
+
+			songs, err := storage.Songs.GetFiltered(db.SongFilter{Query: "love"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ids := songIDs(songs); fmt.Sprint(ids) != fmt.Sprint([]int{love.ID, light.ID, other.ID}) {
+				t.Errorf("GetFiltered() = %v, ожидался порядок поиска", ids)
+			}
+			if count, err := storage.Songs.Count(db.SongFilter{Query: "love", Limit: 1}); err != nil || count != 3 {
+				t.Errorf("Count() = %d, %v, ожидалось 3", count, err)
+			}
+		})
+	}
+}
+
+func TestReleaseDateRange(t *testing.T) {
+	date := func(value string) *time.Time {
+		d, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &d
+	}
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			ids := make(map[string]int)
+			for _, released := range []string{"1990-12-31", "1991-01-01", "1991-06-15", "1991-12-31", "1992-01-01"} {
+				ids[released] = createSong(t, storage, "Queen", models.Song{Song: released, ReleaseDate: *date(released)}).ID
+			}
+			createSong(t, storage, "Queen", models.Song{Song: "без даты"})
+
+			for _, tc := range []struct {
+				name     string
+				from, to string
+				want     []string
+			}{
+				{"год включительно", "1991-01-01", "1991-12-31", []string{"1991-01-01", "1991-06-15", "1991-12-31"}},
+				{"одна дата", "1991-06-15", "1991-06-15", []string{"1991-06-15"}},
+				{"только начало", "1991-12-31", "", []string{"1991-12-31", "1992-01-01"}},
+				{"только конец", "", "1991-01-01", []string{"1990-12-31", "1991-01-01"}},
+				{"пустой диапазон", "1991-12-31", "1991-01-01", nil},
+			} {
+				filter := db.SongFilter{}
+				if tc.from != "" {
+					filter.ReleasedFrom = date(tc.from)
+				}
+				if tc.to != "" {
+					filter.ReleasedTo = date(tc.to)
+				}
+				songs, err := storage.Songs.GetFiltered(filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var want []int
+				for _, released := range tc.want {
+					want = append(want, ids[released])
+				}
+				if got := songIDs(songs); !slices.Equal(got, want) {
+					t.Errorf("%s: %v, ожидалось %v", tc.name, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package db
+
+import (
+	"cmp"
+	"music_storage/internal/models"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Поля сортировки списка песен.
+const (
+	SortID          = "id"
+	SortSong        = "song"
+	SortGroup       = "group"
+	SortReleaseDate = "releaseDate"
+	SortAlbum       = "album"
+	SortDiscNumber  = "discNumber"
+	SortTrackNumber = "trackNumber"
+)
+
+// SortField — поле сортировки списка песен и её направление.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// SongSortKey — значения полей сортировки песни. Заполняются только поля, по которым
+// идёт сортировка, и ID.
+type SongSortKey struct {
+	ID          int        `json:"id"`
+	Song        string     `json:"song,omitempty"`
+	Group       string     `json:"group,omitempty"`
+	ReleaseDate *time.Time `json:"releaseDate,omitempty"`
+	// Album — нормализованное название альбома; nil, если у песни нет альбома.
+	Album       *string `json:"album,omitempty"`
+	DiscNumber  int     `json:"discNumber,omitempty"`
+	TrackNumber int     `json:"trackNumber,omitempty"`
+}
+
+// SongCursor — позиция в списке песен: страница начинается сразу после песни с ключом Key,
+// а при Backward заканчивается сразу перед ней.
+type SongCursor struct {
+	Key      SongSortKey
+	Backward bool
+}
+
+// songSortColumns сопоставляет полям сортировки столбцы. Группы и альбомы присоединяются
+// к запросу под псевдонимами sort_group и sort_album (см. applyOrder).
+var songSortColumns = map[string]string{
+	SortID:          "songs.id",
+	SortSong:        "songs.song",
+	SortGroup:       "sort_group.normalized_name",
+	SortReleaseDate: "songs.release_date",
+	SortAlbum:       "COALESCE(sort_album.normalized_title, '')",
+	SortDiscNumber:  "songs.disc_number",
+	SortTrackNumber: "songs.track_number",
+}
+
+// IsSongSortField проверяет, что по полю можно сортировать список песен.
+func IsSongSortField(field string) bool {
+	_, ok := songSortColumns[field]
+	return ok
+}
+
+// NewSongSortKey возвращает ключ сортировки песни с загруженными группой и альбомом
+// для полей fields.
+func NewSongSortKey(song models.Song, fields []SortField) SongSortKey {
+	key := SongSortKey{ID: song.ID}
+	for _, field := range fields {
+		switch field.Field {
+		case SortSong:
+			key.Song = song.Song
+		case SortGroup:
+			key.Group = song.Group.NormalizedName
+		case SortReleaseDate:
+			releaseDate := song.ReleaseDate.UTC()
+			key.ReleaseDate = &releaseDate
+		case SortAlbum:
+			if song.Album != nil {
+				key.Album = &song.Album.NormalizedTitle
+			}
+		case SortDiscNumber:
+			key.DiscNumber = song.DiscNumber
+		case SortTrackNumber:
+			key.TrackNumber = song.TrackNumber
+		}
+	}
+	return key
+}
+
+// sortColumn — выражение, по которому сортируется запрос, и значение этого выражения в ключе.
+type sortColumn struct {
+	expr  string
+	desc  bool
+	value func(key SongSortKey) any
+}
+
+// sortColumns возвращает выражения сортировки по полям fields и в конце по ID. Песни без
+// альбома при сортировке по альбому идут последними в любом направлении.
+func sortColumns(fields []SortField) []sortColumn {
+	var columns []sortColumn
+	for _, field := range fields {
+		if field.Field == SortAlbum {
+			columns = append(columns, sortColumn{
+				expr: "CASE WHEN sort_album.id IS NULL THEN 1 ELSE 0 END",
+				value: func(key SongSortKey) any {
+					if key.Album == nil {
+						return 1
+					}
+					return 0
+				},
+			})
+		}
+		columns = append(columns, sortColumn{
+			expr:  songSortColumns[field.Field],
+			desc:  field.Desc,
+			value: sortKeyValue(field.Field),
+		})
+	}
+	return append(columns, sortColumn{expr: "songs.id", value: sortKeyValue(SortID)})
+}
+
+// sortKeyValue возвращает функцию, извлекающую из ключа значение поля сортировки.
+func sortKeyValue(field string) func(key SongSortKey) any {
+	return func(key SongSortKey) any {
+		switch field {
+		case SortSong:
+			return key.Song
+		case SortGroup:
+			return key.Group
+		case SortReleaseDate:
+			if key.ReleaseDate == nil {
+				return time.Time{}
+			}
+			return *key.ReleaseDate
+		case SortAlbum:
+			if key.Album == nil {
+				return ""
+			}
+			return *key.Album
+		case SortDiscNumber:
+			return key.DiscNumber
+		case SortTrackNumber:
+			return key.TrackNumber
+		default:
+			return key.ID
+		}
+	}
+}
+
+// applyOrder добавляет к запросу по таблице songs сортировку filter.Sort и условие курсора
+// filter.Cursor. При Backward порядок обратный, и результат нужно развернуть.
+func applyOrder(query *gorm.DB, filter SongFilter) *gorm.DB {
+	joined := make(map[string]bool)
+	for _, field := range filter.Sort {
+		switch {
+		case field.Field == SortGroup && !joined[field.Field]:
+			query = query.Joins("LEFT JOIN groups AS sort_group ON sort_group.id = songs.group_id")
+		case field.Field == SortAlbum && !joined[field.Field]:
+			query = query.Joins("LEFT JOIN albums AS sort_album ON sort_album.id = songs.album_id")
+		}
+		joined[field.Field] = true
+	}
+
+	columns := sortColumns(filter.Sort)
+	backward := filter.Cursor != nil && filter.Cursor.Backward
+	if filter.Cursor != nil {
+		// (a > ?) OR (a = ? AND b > ?) OR ... — сравнение кортежей с разными направлениями.
+		var conditions []string
+		var args []any
+		for i, column := range columns {
+			var parts []string
+			for _, previous := range columns[:i] {
+				parts = append(parts, previous.expr+" = ?")
+				args = append(args, previous.value(filter.Cursor.Key))
+			}
+			operator := ">"
+			if column.desc != backward {
+				operator = "<"
+			}
+			parts = append(parts, column.expr+" "+operator+" ?")
+			args = append(args, column.value(filter.Cursor.Key))
+			conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+		}
+		query = query.Where(strings.Join(conditions, " OR "), args...)
+	}
+
+	for _, column := range columns {
+		if column.desc != backward {
+			query = query.Order(column.expr + " DESC")
+		} else {
+			query = query.Order(column.expr)
+		}
+	}
+	return query
+}
+
+// compareSortKeys сравнивает ключи так же, как сортирует applyOrder.
+func compareSortKeys(a, b SongSortKey, fields []SortField) int {
+	for _, column := range sortColumns(fields) {
+		c := compareValues(column.value(a), column.value(b))
+		if column.desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case int:
+		return cmp.Compare(a, b.(int))
+	case string:
+		return strings.Compare(a, b.(string))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	}
+	return 0
+}
+
+// compareSongs сравнивает песни с загруженными группой и альбомом так же, как сортирует applyOrder.
+func compareSongs(a, b models.Song, fields []SortField) int {
+	return compareSortKeys(NewSongSortKey(a, fields), NewSongSortKey(b, fields), fields)
+}
+
+// paginate применяет курсор и пагинацию фильтра к списку, упорядоченному по filter.Sort.
+// song возвращает песню элемента списка.
+func paginate[T any](items []T, filter SongFilter, song func(item T) models.Song) []T {
+	backward := false
+	if filter.Cursor != nil {
+		backward = filter.Cursor.Backward
+		var page []T
+		for _, item := range items {
+			c := compareSortKeys(NewSongSortKey(song(item), filter.Sort), filter.Cursor.Key, filter.Sort)
+			if (c > 0 && !backward) || (c < 0 && backward) {
+				page = append(page, item)
+			}
+		}
+		items = page
+	}
+
+	if backward {
+		slices.Reverse(items)
+	}
+	if filter.Offset >= len(items) {
+		return nil
+	}
+	items = items[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(items) {
+		items = items[:filter.Limit]
+	}
+	if backward {
+		slices.Reverse(items)
+	}
+	return items
+}
@@ -0,0 +1,168 @@
+package db_test
+
+import (
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestIsSongSortField(t *testing.T) {
+	for _, field := range []string{db.SortID, db.SortSong, db.SortGroup, db.SortReleaseDate, db.SortAlbum, db.SortDiscNumber, db.SortTrackNumber} {
+		if !db.IsSongSortField(field) {
+			t.Errorf("IsSongSortField(%q) = false", field)
+		}
+	}
+	for _, field := range []string{"", "text", "ID", "songs.id", "song_id", "release_date", "id; DROP TABLE songs"} {
+		if db.IsSongSortField(field) {
+			t.Errorf("IsSongSortField(%q) = true", field)
+		}
+	}
+}
+
+// sortedSongs добавляет песни с совпадающими значениями полей сортировки и возвращает их
+// ID в порядке добавления: песни 0 и 2, 1 и 4 отличаются только названием и ID.
+func sortedSongs(t *testing.T, storage *db.Storage) []int {
+	t.Helper()
+	date := func(year int) time.Time { return time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC) }
+	album := func(group, title string) *int {
+		g, err := storage.Groups.FindOrCreate(group)
+		if err != nil {
+			t.Fatal(err)
+		}
+		album := models.Album{GroupID: g.ID, Title: title}
+		if err := storage.Albums.Create(&album); err != nil {
+			t.Fatal(err)
+		}
+		return &album.ID
+	}
+	night, arrival := album("Queen", "A Night at the Opera"), album("ABBA", "Arrival")
+
+	var ids []int
+	for _, song := range []struct {
+		group string
+		song  models.Song
+	}{
+		{"Queen", models.Song{Song: "b", ReleaseDate: date(1980), AlbumID: night, DiscNumber: 1, TrackNumber: 2}},
+		{"ABBA", models.Song{Song: "a", ReleaseDate: date(1975), DiscNumber: 1, TrackNumber: 1}},
+		{"Queen", models.Song{Song: "a", ReleaseDate: date(1980), AlbumID: night, DiscNumber: 1, TrackNumber: 1}},
+		{"Кино", models.Song{Song: "c", ReleaseDate: date(1990)}},
+		{"ABBA", models.Song{Song: "b", ReleaseDate: date(1975), AlbumID: arrival, DiscNumber: 1, TrackNumber: 1}},
+	} {
+		ids = append(ids, createSong(t, storage, song.group, song.song).ID)
+	}
+	return ids
+}
+
+// songIDs возвращает ID песен.
+func songIDs(songs []models.Song) []int {
+	ids := make([]int, len(songs))
+	for i, song := range songs {
+		ids[i] = song.ID
+	}
+	return ids
+}
+
+func TestSongSortOrder(t *testing.T) {
+	asc := func(field string) db.SortField { return db.SortField{Field: field} }
+	desc := func(field string) db.SortField { return db.SortField{Field: field, Desc: true} }
+
+	tests := []struct {
+		name string
+		sort []db.SortField
+		// want — порядок песен из sortedSongs по их номерам.
+		want []int
+	}{
+		{"без сортировки по ID", nil, []int{0, 1, 2, 3, 4}},
+		{"по ID по убыванию", []db.SortField{desc(db.SortID)}, []int{4, 3, 2, 1, 0}},
+		{"по названию, равные по ID", []db.SortField{asc(db.SortSong)}, []int{1, 2, 0, 4, 3}},
+		{"по убыванию названия, равные по возрастанию ID", []db.SortField{desc(db.SortSong)}, []int{3, 0, 4, 1, 2}},
+		{"по нормализованному названию группы", []db.SortField{asc(db.SortGroup)}, []int{1, 4, 0, 2, 3}},
+		{"по убыванию группы", []db.SortField{desc(db.SortGroup)}, []int{3, 0, 2, 1, 4}},
+		{"по дате выпуска", []db.SortField{asc(db.SortReleaseDate)}, []int{1, 4, 0, 2, 3}},
+		{"по убыванию даты выпуска", []db.SortField{desc(db.SortReleaseDate)}, []int{3, 0, 2, 1, 4}},
+		{"по альбому, без альбома последними", []db.SortField{asc(db.SortAlbum)}, []int{0, 2, 4, 1, 3}},
+		{"по убыванию альбома, без альбома последними", []db.SortField{desc(db.SortAlbum)}, []int{4, 0, 2, 1, 3}},
+		{"по группе и убыванию названия", []db.SortField{asc(db.SortGroup), desc(db.SortSong)}, []int{4, 1, 0, 2, 3}},
+		{"по диску и треку", []db.SortField{asc(db.SortDiscNumber), asc(db.SortTrackNumber)}, []int{3, 1, 2, 4, 0}},
+		{"по убыванию трека", []db.SortField{desc(db.SortTrackNumber)}, []int{0, 1, 2, 4, 3}},
+	}
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			ids := sortedSongs(t, storage)
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					songs, err := storage.Songs.GetFiltered(db.SongFilter{Sort: tt.sort})
+					if err != nil {
+						t.Fatal(err)
+					}
+					want := make([]int, len(tt.want))
+					for i, n := range tt.want {
+						want[i] = ids[n]
+					}
+					if got := songIDs(songs); !slices.Equal(got, want) {
+						t.Errorf("порядок %v, ожидался %v", got, want)
+					}
+				})
+			}
+		})
+	}
+}
+
+// TestSongCursorPaging проверяет, что страницы по курсору в обоих направлениях проходят
+// все песни ровно по одному разу, даже если значения полей сортировки совпадают.
+func TestSongCursorPaging(t *testing.T) {
+	sorts := [][]db.SortField{
+		{{Field: db.SortGroup}},
+		{{Field: db.SortReleaseDate, Desc: true}},
+		{{Field: db.SortAlbum, Desc: true}},
+		{{Field: db.SortDiscNumber}, {Field: db.SortTrackNumber, Desc: true}},
+	}
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			sortedSongs(t, storage)
+			for _, sort := range sorts {
+				all, err := storage.Songs.GetFiltered(db.SongFilter{Sort: sort})
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := songIDs(all)
+
+				var forward []int
+				var cursor *db.SongCursor
+				for {
+					page, err := storage.Songs.GetFiltered(db.SongFilter{Sort: sort, Cursor: cursor, Limit: 2})
+					if err != nil {
+						t.Fatal(err)
+					}
+					if len(page) == 0 {
+						break
+					}
+					forward = append(forward, songIDs(page)...)
+					cursor = &db.SongCursor{Key: db.NewSongSortKey(page[len(page)-1], sort)}
+				}
+				if !slices.Equal(forward, want) {
+					t.Errorf("сортировка %v: вперёд %v, ожидалось %v", sort, forward, want)
+				}
+
+				var backward []int
+				cursor = &db.SongCursor{Key: db.NewSongSortKey(all[len(all)-1], sort), Backward: true}
+				for {
+					page, err := storage.Songs.GetFiltered(db.SongFilter{Sort: sort, Cursor: cursor, Limit: 2})
+					if err != nil {
+						t.Fatal(err)
+					}
+					if len(page) == 0 {
+						break
+					}
+					backward = append(songIDs(page), backward...)
+					cursor = &db.SongCursor{Key: db.NewSongSortKey(page[0], sort), Backward: true}
+				}
+				if !slices.Equal(backward, want[:len(want)-1]) {
+					t.Errorf("сортировка %v: назад %v, ожидалось %v", sort, backward, want[:len(want)-1])
+				}
+			}
+		})
+	}
+}
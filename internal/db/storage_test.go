@@ -0,0 +1,42 @@
+package db_test
+
+import (
+	"music_storage/internal/db"
+	"music_storage/internal/migrations"
+	"music_storage/internal/models"
+	"testing"
+)
+
+// storages возвращает пустые хранилища, на которых проверяются репозитории: в памяти и в
+// SQLite со всеми миграциями.
+func storages(t *testing.T) map[string]*db.Storage {
+	t.Helper()
+	t.Setenv("DB_DRIVER", db.DriverSQLite)
+	t.Setenv("DB_PATH", t.TempDir()+"/test.db")
+	conn := db.Connect()
+	m, err := migrations.New(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+	return map[string]*db.Storage{
+		"memory": db.NewMemoryStorage(),
+		"sqlite": db.NewGormStorage(conn),
+	}
+}
+
+// createSong добавляет песню группы group и возвращает её.
+func createSong(t *testing.T, storage *db.Storage, group string, song models.Song) models.Song {
+	t.Helper()
+	g, err := storage.Groups.FindOrCreate(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	song.GroupID = g.ID
+	if err := storage.Songs.Create(&song, "test"); err != nil {
+		t.Fatal(err)
+	}
+	return song
+}
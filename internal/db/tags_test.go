@@ -0,0 +1,117 @@
+package db_test
+
+import (
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// taggedSong добавляет песню группы Queen с жанрами genres и тегами tags.
+func taggedSong(t *testing.T, storage *db.Storage, name string, genres []*models.Genre, tags []models.Tag) models.Song {
+	t.Helper()
+	song := createSong(t, storage, "Queen", models.Song{Song: name})
+	var genreIDs, tagIDs []int
+	for _, genre := range genres {
+		genreIDs = append(genreIDs, genre.ID)
+	}
+	for _, tag := range tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	if err := storage.Songs.SetGenres(song.ID, genreIDs, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Songs.SetTags(song.ID, tagIDs, "test"); err != nil {
+		t.Fatal(err)
+	}
+	return song
+}
+
+// termCounts возвращает пары «название: количество» в порядке counts.
+func termCounts(counts []db.TermCount) []string {
+	var result []string
+	for _, count := range counts {
+		result = append(result, count.Name+":"+strconv.Itoa(count.SongCount))
+	}
+	return result
+}
+
+func TestGenreAndTagFilters(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			var genres []*models.Genre
+			for _, name := range []string{"Rock", "Pop", "Jazz"} {
+				genre, err := storage.Genres.Create(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				genres = append(genres, genre)
+			}
+			rock, pop := genres[0], genres[1]
+			tags, err := storage.Tags.FindOrCreate([]string{"live", "remaster"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			live, remaster := tags[0], tags[1]
+
+			both := taggedSong(t, storage, "Innuendo", []*models.Genre{rock, pop}, []models.Tag{live, remaster})
+			rockOnly := taggedSong(t, storage, "Headlong", []*models.Genre{rock}, []models.Tag{live})
+			popOnly := taggedSong(t, storage, "Bijou", []*models.Genre{pop}, nil)
+			createSong(t, storage, "Queen", models.Song{Song: "Mustapha"})
+			trashed := taggedSong(t, storage, "Delilah", []*models.Genre{rock}, []models.Tag{live})
+			if err := storage.Songs.Delete(trashed.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, tc := range []struct {
+				name   string
+				filter db.SongFilter
+				want   []int
+			}{
+				{"один жанр", db.SongFilter{Genres: []string{"Rock"}}, []int{both.ID, rockOnly.ID}},
+				{"любой из жанров", db.SongFilter{Genres: []string{"rock", " POP "}}, []int{both.ID, rockOnly.ID, popOnly.ID}},
+				{"все жанры", db.SongFilter{Genres: []string{"Rock", "Pop"}, AllGenres: true}, []int{both.ID}},
+				{"все жанры с повтором", db.SongFilter{Genres: []string{"Rock", "rock"}, AllGenres: true}, []int{both.ID, rockOnly.ID}},
+				{"жанр без песен среди всех", db.SongFilter{Genres: []string{"Rock", "Jazz"}, AllGenres: true}, nil},
+				{"неизвестный жанр", db.SongFilter{Genres: []string{"Metal"}}, nil},
+				{"любой из тегов", db.SongFilter{Tags: []string{"live", "remaster"}}, []int{both.ID, rockOnly.ID}},
+				{"все теги", db.SongFilter{Tags: []string{"live", "remaster"}, AllTags: true}, []int{both.ID}},
+				{"жанр и тег", db.SongFilter{Genres: []string{"Pop"}, Tags: []string{"live"}}, []int{both.ID}},
+			} {
+				songs, err := storage.Songs.GetFiltered(tc.filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ids := songIDs(songs); !slices.Equal(ids, tc.want) {
+					t.Errorf("%s: %v, ожидалось %v", tc.name, ids, tc.want)
+				}
+				if count, err := storage.Songs.Count(tc.filter); err != nil || count != len(tc.want) {
+					t.Errorf("%s: Count() = %d, %v, ожидалось %d", tc.name, count, err, len(tc.want))
+				}
+			}
+
+			for _, tc := range []struct {
+				name   string
+				filter db.SongFilter
+				total  int
+				genres []string
+				tags   []string
+			}{
+				{"все песни", db.SongFilter{Limit: 1}, 4, []string{"Pop:2", "Rock:2"}, []string{"live:2", "remaster:1"}},
+				{"по жанру", db.SongFilter{Genres: []string{"Rock"}}, 2, []string{"Rock:2", "Pop:1"}, []string{"live:2", "remaster:1"}},
+				{"по всем тегам", db.SongFilter{Tags: []string{"live", "remaster"}, AllTags: true}, 1, []string{"Pop:1", "Rock:1"}, []string{"live:1", "remaster:1"}},
+				{"без песен", db.SongFilter{Genres: []string{"Jazz"}}, 0, nil, nil},
+			} {
+				facets, err := storage.Songs.Facets(tc.filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if facets.Total != tc.total || !slices.Equal(termCounts(facets.Genres), tc.genres) || !slices.Equal(termCounts(facets.Tags), tc.tags) {
+					t.Errorf("%s: всего %d, жанры %v, теги %v; ожидалось %d, %v, %v",
+						tc.name, facets.Total, termCounts(facets.Genres), termCounts(facets.Tags), tc.total, tc.genres, tc.tags)
+				}
+			}
+		})
+	}
+}
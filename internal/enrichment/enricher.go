@@ -0,0 +1,254 @@
+// Package enrichment дополняет сохранённые песни метаданными в фоновом режиме.
+//
+// Новая песня сохраняется со статусом pending и ставится в очередь. Пул воркеров
+// запрашивает данные у источника метаданных и записывает их в ещё пустые поля песни, не
+// затирая изменения, сделанные во время запроса. При недоступности источника попытка
+// откладывается с экспоненциальной паузой, в том числе если другие источники ответили,
+// но данные без недоступного могут быть неполными (metadata.IncompleteError). После
+// MaxAttempts неудач песня получает статус failed, а при неполных данных — done с тем,
+// что удалось получить. Кроме очереди, воркеры периодически забирают из хранилища
+// песни, время следующей попытки которых наступило, поэтому повторы переживают перезапуск.
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"music_storage/internal/config"
+	"music_storage/internal/db"
+	"music_storage/internal/metadata"
+	"music_storage/internal/models"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Author — автор ревизий песен, записанных при обогащении.
+const Author = "enrichment"
+
+// Config задаёт параметры фонового обогащения.
+type Config struct {
+	Workers      int
+	QueueSize    int
+	PollInterval time.Duration
+	MaxAttempts  int
+	// RetryBackoff — пауза после первой неудачной попытки, далее удваивается до MaxRetryBackoff.
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+}
+
+// DefaultConfig возвращает параметры обогащения по умолчанию.
+func DefaultConfig() Config {
+	return Config{
+		Workers:         4,
+		QueueSize:       100,
+		PollInterval:    30 * time.Second,
+		MaxAttempts:     5,
+		RetryBackoff:    time.Minute,
+		MaxRetryBackoff: time.Hour,
+	}
+}
+
+// ConfigFromEnv читает параметры обогащения из переменных окружения ENRICHMENT_WORKERS,
+// ENRICHMENT_QUEUE_SIZE, ENRICHMENT_POLL_INTERVAL, ENRICHMENT_MAX_ATTEMPTS,
+// ENRICHMENT_RETRY_BACKOFF и ENRICHMENT_MAX_RETRY_BACKOFF.
+func ConfigFromEnv() Config {
+	cfg := DefaultConfig()
+	cfg.Workers = config.Int("ENRICHMENT_WORKERS", cfg.Workers)
+	cfg.QueueSize = config.Int("ENRICHMENT_QUEUE_SIZE", cfg.QueueSize)
+	cfg.PollInterval = config.Duration("ENRICHMENT_POLL_INTERVAL", cfg.PollInterval)
+	cfg.MaxAttempts = config.Int("ENRICHMENT_MAX_ATTEMPTS", cfg.MaxAttempts)
+	cfg.RetryBackoff = config.Duration("ENRICHMENT_RETRY_BACKOFF", cfg.RetryBackoff)
+	cfg.MaxRetryBackoff = config.Duration("ENRICHMENT_MAX_RETRY_BACKOFF", cfg.MaxRetryBackoff)
+	return cfg
+}
+
+// Enricher — пул воркеров, обогащающих песни.
+type Enricher struct {
+	songs    db.SongRepository
+	provider metadata.MetadataProvider
+	cfg      Config
+	queue    chan int
+	now      func() time.Time
+
+	mu       sync.Mutex
+	inFlight map[int]struct{}
+}
+
+// New создаёт пул воркеров. Воркеры запускаются методом Start.
+func New(songs db.SongRepository, provider metadata.MetadataProvider, cfg Config) *Enricher {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+	return &Enricher{
+		songs:    songs,
+		provider: provider,
+		cfg:      cfg,
+		queue:    make(chan int, cfg.QueueSize),
+		now:      time.Now,
+		inFlight: make(map[int]struct{}),
+	}
+}
+
+// Start запускает воркеры и периодический опрос хранилища. Останавливается при отмене ctx.
+func (e *Enricher) Start(ctx context.Context) {
+	for i := 0; i < e.cfg.Workers; i++ {
+		go e.work(ctx)
+	}
+	go e.poll(ctx)
+	logrus.Infof("Запущено фоновое обогащение песен, воркеров: %d", e.cfg.Workers)
+}
+
+// Enqueue ставит песню в очередь на обогащение. Если очередь заполнена,
+// песня будет взята при следующем опросе хранилища.
+func (e *Enricher) Enqueue(id int) {
+	e.mu.Lock()
+	if _, ok := e.inFlight[id]; ok {
+		e.mu.Unlock()
+		return
+	}
+	e.inFlight[id] = struct{}{}
+	e.mu.Unlock()
+
+	select {
+	case e.queue <- id:
+		logrus.Debugf("Песня с ID %d поставлена в очередь на обогащение", id)
+	default:
+		e.release(id)
+		logrus.Warnf("Очередь обогащения заполнена, песня с ID %d будет обработана позже", id)
+	}
+}
+
+func (e *Enricher) release(id int) {
+	e.mu.Lock()
+	delete(e.inFlight, id)
+	e.mu.Unlock()
+}
+
+func (e *Enricher) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-e.queue:
+			if err := e.Process(ctx, id); err != nil {
+				logrus.Errorf("Ошибка обогащения песни с ID %d: %v", id, err)
+			}
+			e.release(id)
+		}
+	}
+}
+
+func (e *Enricher) poll(ctx context.Context) {
+	ticker := time.NewTicker(e.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		ids, err := e.songs.ListDueForEnrichment(e.now().UTC(), e.cfg.QueueSize)
+		if err != nil {
+			logrus.Errorf("Ошибка получения песен для обогащения: %v", err)
+		}
+		for _, id := range ids {
+			e.Enqueue(id)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessNow выполняет одну попытку обогащения песни в вызывающей горутине. Если песня
+// уже в очереди или обрабатывается воркером, попытка пропускается.
+func (e *Enricher) ProcessNow(ctx context.Context, id int) error {
+	e.mu.Lock()
+	if _, ok := e.inFlight[id]; ok {
+		e.mu.Unlock()
+		return nil
+	}
+	e.inFlight[id] = struct{}{}
+	e.mu.Unlock()
+	defer e.release(id)
+
+	return e.Process(ctx, id)
+}
+
+// Process выполняет одну попытку обогащения песни и сохраняет её результат.
+// Песни не в статусе pending пропускаются.
+func (e *Enricher) Process(ctx context.Context, id int) error {
+	song, err := e.songs.GetByID(id)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if song.EnrichmentStatus != models.EnrichmentPending {
+		return nil
+	}
+
+	logrus.Infof("Обогащение песни с ID %d: %s - %s", id, song.Group.Name, song.Song)
+	data, err := e.provider.Lookup(ctx, song.Group.Name, song.Song)
+	result := db.EnrichmentResult{Status: models.EnrichmentPending, Attempts: song.EnrichmentAttempts + 1}
+	var incomplete *metadata.IncompleteError
+	switch {
+	case err == nil:
+		apply(&result, data)
+		result.Status = models.EnrichmentDone
+		logrus.Infof("Песня с ID %d успешно обогащена", id)
+	case errors.Is(err, metadata.ErrNotFound):
+		result.Status = models.EnrichmentFailed
+		result.Error = err.Error()
+		logrus.Warnf("Песня с ID %d не найдена ни в одном источнике метаданных", id)
+	case errors.As(err, &incomplete) && result.Attempts >= e.cfg.MaxAttempts:
+		// Неполные данные записываются только после последней попытки: записанное поле
+		// потом уже не заменить значением более важного источника.
+		apply(&result, incomplete.Data)
+		result.Status = models.EnrichmentDone
+		result.Error = err.Error()
+		logrus.Warnf("Песня с ID %d обогащена неполными данными после %d попыток: %v", id, result.Attempts, err)
+	case result.Attempts >= e.cfg.MaxAttempts:
+		result.Status = models.EnrichmentFailed
+		result.Error = err.Error()
+		logrus.Warnf("Обогащение песни с ID %d прекращено после %d попыток: %v", id, result.Attempts, err)
+	default:
+		next := e.now().UTC().Add(e.retryDelay(result.Attempts))
+		result.Error = err.Error()
+		result.NextAt = &next
+		logrus.Warnf("Обогащение песни с ID %d отложено до %s: %v", id, next.Format(time.RFC3339), err)
+	}
+
+	// Песня могла быть удалена, пока шёл запрос к источникам.
+	if err := e.songs.SaveEnrichment(id, result, Author); err != nil && !errors.Is(err, db.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// apply переносит в итог обогащения полученные данные. Они записываются только в пустые
+// поля песни (см. db.SongRepository.SaveEnrichment).
+func apply(result *db.EnrichmentResult, data *metadata.SongData) {
+	result.Text = data.Text
+	result.Link = data.Link
+	if data.ReleaseDate != "" {
+		if releaseDate, err := time.Parse("2006-01-02", data.ReleaseDate); err == nil {
+			result.ReleaseDate = releaseDate
+		} else {
+			logrus.Warnf("Ошибка при парсинге даты: %v", err)
+		}
+	}
+}
+
+// retryDelay возвращает паузу после attempt неудачных попыток.
+func (e *Enricher) retryDelay(attempt int) time.Duration {
+	delay := e.cfg.RetryBackoff << (attempt - 1)
+	if delay <= 0 || delay > e.cfg.MaxRetryBackoff {
+		delay = e.cfg.MaxRetryBackoff
+	}
+	return delay
+}
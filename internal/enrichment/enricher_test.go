@@ -0,0 +1,141 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"music_storage/internal/db"
+	"music_storage/internal/metadata"
+	"music_storage/internal/models"
+	"testing"
+	"time"
+)
+
+// blockingProvider отвечает на запрос только после закрытия release.
+type blockingProvider struct {
+	started chan struct{}
+	release chan struct{}
+	data    metadata.SongData
+}
+
+func (p *blockingProvider) Name() string { return "blocking" }
+
+func (p *blockingProvider) Lookup(ctx context.Context, group, song string) (*metadata.SongData, error) {
+	close(p.started)
+	<-p.release
+	data := p.data
+	return &data, nil
+}
+
+func TestProcessKeepsChangesMadeDuringLookup(t *testing.T) {
+	storage := db.NewMemoryStorage()
+	group, err := storage.Groups.FindOrCreate("Queen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	song := models.Song{GroupID: group.ID, Song: "Bohemian Rhapsody", EnrichmentStatus: models.EnrichmentPending}
+	if err := storage.Songs.Create(&song, "api"); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := &blockingProvider{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+		data:    metadata.SongData{ReleaseDate: "1975-10-31", Text: "Is this the real life?", Link: "https://example.com/source"},
+	}
+	enricher := New(storage.Songs, provider, DefaultConfig())
+	done := make(chan error)
+	go func() { done <- enricher.Process(context.Background(), song.ID) }()
+
+	<-provider.started
+	edited, err := storage.Songs.GetByID(song.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited.Song = "Bohemian Rhapsody (Remastered)"
+	edited.Link = "https://example.com/user"
+	if err := storage.Songs.Update(edited, "user"); err != nil {
+		t.Fatal(err)
+	}
+	close(provider.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := storage.Songs.GetByID(song.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Song != "Bohemian Rhapsody (Remastered)" || got.Link != "https://example.com/user" {
+		t.Errorf("изменения пользователя потеряны: song = %q, link = %q", got.Song, got.Link)
+	}
+	if got.Text != "Is this the real life?" || !got.ReleaseDate.Equal(time.Date(1975, 10, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("пустые поля не заполнены: text = %q, releaseDate = %s", got.Text, got.ReleaseDate)
+	}
+	if got.EnrichmentStatus != models.EnrichmentDone || got.EnrichmentAttempts != 1 {
+		t.Errorf("статус = %q, попыток = %d", got.EnrichmentStatus, got.EnrichmentAttempts)
+	}
+
+	revisions, err := storage.Songs.ListRevisions(song.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := revisions[len(revisions)-1]
+	if last.Author != Author {
+		t.Fatalf("автор последней ревизии = %q", last.Author)
+	}
+	for _, change := range last.Changes {
+		if change.Field != "text" && change.Field != "releaseDate" {
+			t.Errorf("ревизия обогащения меняет поле %s", change.Field)
+		}
+	}
+}
+
+// incompleteProvider отвечает неполными данными, как цепочка с недоступным источником.
+type incompleteProvider struct {
+	data metadata.SongData
+}
+
+func (p incompleteProvider) Name() string { return "incomplete" }
+
+func (p incompleteProvider) Lookup(context.Context, string, string) (*metadata.SongData, error) {
+	data := p.data
+	return nil, &metadata.IncompleteError{Data: &data, Err: errors.New("источник api недоступен")}
+}
+
+func TestProcessRetriesIncompleteData(t *testing.T) {
+	storage := db.NewMemoryStorage()
+	group, err := storage.Groups.FindOrCreate("Queen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	song := models.Song{GroupID: group.ID, Song: "Innuendo", EnrichmentStatus: models.EnrichmentPending}
+	if err := storage.Songs.Create(&song, "api"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.MaxAttempts = 2
+	enricher := New(storage.Songs, incompleteProvider{data: metadata.SongData{Link: "https://example.com/catalog"}}, cfg)
+
+	if err := enricher.Process(context.Background(), song.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, err := storage.Songs.GetByID(song.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.EnrichmentStatus != models.EnrichmentPending || got.EnrichmentNextAt == nil || got.Link != "" {
+		t.Fatalf("после первой попытки: статус %q, следующая попытка %v, link %q", got.EnrichmentStatus, got.EnrichmentNextAt, got.Link)
+	}
+
+	if err := enricher.Process(context.Background(), song.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, err = storage.Songs.GetByID(song.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.EnrichmentStatus != models.EnrichmentDone || got.Link != "https://example.com/catalog" || got.EnrichmentError == "" {
+		t.Fatalf("после последней попытки: статус %q, link %q, ошибка %q", got.EnrichmentStatus, got.Link, got.EnrichmentError)
+	}
+}
@@ -0,0 +1,142 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// breakerStep — действие над автоматом: сначала часы сдвигаются на wait, затем
+// выполняется do. Для allow проверяется, что ответ равен allowed.
+type breakerStep struct {
+	wait    time.Duration
+	do      string
+	allowed bool
+}
+
+// then возвращает новую последовательность: шаги steps, за которыми следуют more.
+func then(steps []breakerStep, more ...breakerStep) []breakerStep {
+	return append(append([]breakerStep{}, steps...), more...)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	const cooldown = time.Minute
+	open := []breakerStep{{do: "failure"}, {do: "failure"}, {do: "failure"}, {do: "allow", allowed: false}}
+	probe := then(open, breakerStep{wait: cooldown, do: "allow", allowed: true})
+
+	tests := []struct {
+		name      string
+		threshold int
+		steps     []breakerStep
+		want      breakerState
+	}{
+		{
+			name:      "остаётся замкнутым до порога",
+			threshold: 3,
+			steps: []breakerStep{
+				{do: "failure"}, {do: "failure"}, {do: "allow", allowed: true},
+				{do: "success"}, {do: "failure"}, {do: "failure"}, {do: "allow", allowed: true},
+			},
+			want: breakerClosed,
+		},
+		{
+			name:      "размыкается после порога",
+			threshold: 3,
+			steps:     then(open, breakerStep{wait: cooldown - time.Second, do: "allow", allowed: false}),
+			want:      breakerOpen,
+		},
+		{
+			name:      "после паузы пропускает один пробный запрос",
+			threshold: 3,
+			steps:     then(probe, breakerStep{do: "allow", allowed: false}),
+			want:      breakerHalfOpen,
+		},
+		{
+			name:      "успешный пробный запрос замыкает",
+			threshold: 3,
+			steps:     then(probe, breakerStep{do: "success"}, breakerStep{do: "allow", allowed: true}),
+			want:      breakerClosed,
+		},
+		{
+			name:      "неудачный пробный запрос снова размыкает",
+			threshold: 3,
+			steps: then(probe,
+				breakerStep{do: "failure"},
+				breakerStep{wait: cooldown - time.Second, do: "allow", allowed: false},
+				breakerStep{wait: time.Second, do: "allow", allowed: true},
+			),
+			want: breakerHalfOpen,
+		},
+		{
+			name:      "отменённый запрос не считается неудачей",
+			threshold: 1,
+			steps:     []breakerStep{{do: "allow", allowed: true}, {do: "cancel"}, {do: "allow", allowed: true}},
+			want:      breakerClosed,
+		},
+		{
+			name:      "после отменённого пробного запроса пропускается новый",
+			threshold: 3,
+			steps:     then(probe, breakerStep{do: "cancel"}, breakerStep{do: "allow", allowed: true}),
+			want:      breakerHalfOpen,
+		},
+		{
+			name:      "нулевой порог отключает автомат",
+			threshold: 0,
+			steps:     then(open[:3], breakerStep{do: "allow", allowed: true}),
+			want:      breakerClosed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			b := newCircuitBreaker(tt.threshold, cooldown)
+			b.now = func() time.Time { return now }
+
+			for i, step := range tt.steps {
+				now = now.Add(step.wait)
+				switch step.do {
+				case "allow":
+					if got := b.allow(); got != step.allowed {
+						t.Fatalf("шаг %d: allow() = %v, ожидалось %v", i, got, step.allowed)
+					}
+				case "success":
+					b.success()
+				case "failure":
+					b.failure()
+				case "cancel":
+					b.cancel()
+				default:
+					t.Fatalf("шаг %d: неизвестное действие %q", i, step.do)
+				}
+			}
+			if b.state != tt.want {
+				t.Errorf("состояние = %d, ожидалось %d", b.state, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientCanceledRequestKeepsBreakerClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = server.URL
+	cfg.MaxRetries = 0
+	cfg.BreakerThreshold = 1
+	client := NewClient(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if _, err := client.FetchSongData(ctx, "Queen", "Innuendo"); !errors.Is(err, ErrUnavailable) || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("FetchSongData() = %v, ожидалась ErrUnavailable", err)
+	}
+	if client.breaker.state != breakerClosed {
+		t.Errorf("после отменённого запроса автомат в состоянии %d", client.breaker.state)
+	}
+}
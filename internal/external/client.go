@@ -0,0 +1,226 @@
+// Package external содержит клиент внешнего API с информацией о песнях.
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"music_storage/internal/config"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrNotFound означает, что внешний API не знает запрошенную песню.
+	ErrNotFound = errors.New("песня не найдена во внешнем API")
+	// ErrUnavailable означает, что внешний API не ответил или ответил ошибкой сервера.
+	ErrUnavailable = errors.New("внешний API недоступен")
+	// ErrCircuitOpen означает, что запрос не выполнялся, потому что разомкнут автомат.
+	// Ошибка оборачивает ErrUnavailable.
+	ErrCircuitOpen = fmt.Errorf("%w: автоматический выключатель разомкнут", ErrUnavailable)
+	// ErrInvalidResponse означает, что ответ внешнего API не удалось разобрать.
+	ErrInvalidResponse = errors.New("некорректный ответ внешнего API")
+)
+
+// StatusError описывает неожиданный HTTP-статус ответа внешнего API.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("внешний API вернул статус %d", e.StatusCode)
+}
+
+// retryable сообщает, имеет ли смысл повторить запрос с таким статусом.
+func (e *StatusError) retryable() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+// SongData — данные о песне, полученные из внешнего API.
+type SongData struct {
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// Config задаёт параметры клиента внешнего API.
+type Config struct {
+	BaseURL string
+	// Timeout ограничивает одну попытку запроса.
+	Timeout time.Duration
+	// MaxRetries — число повторов после первой неудачной попытки.
+	MaxRetries int
+	// InitialBackoff и MaxBackoff задают экспоненциальную паузу между попытками.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// BreakerThreshold — число неудачных запросов подряд, после которого автомат размыкается.
+	// Нулевое значение отключает автомат.
+	BreakerThreshold int
+	// BreakerCooldown — время, на которое размыкается автомат.
+	BreakerCooldown time.Duration
+}
+
+// DefaultConfig возвращает параметры клиента по умолчанию.
+func DefaultConfig() Config {
+	return Config{
+		Timeout:          5 * time.Second,
+		MaxRetries:       3,
+		InitialBackoff:   200 * time.Millisecond,
+		MaxBackoff:       5 * time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+// ConfigFromEnv читает параметры клиента из переменных окружения:
+// API_BASE_URL, EXTERNAL_API_TIMEOUT, EXTERNAL_API_RETRIES, EXTERNAL_API_BACKOFF,
+// EXTERNAL_API_MAX_BACKOFF, EXTERNAL_API_BREAKER_THRESHOLD и EXTERNAL_API_BREAKER_COOLDOWN.
+// Некорректные значения заменяются значениями по умолчанию.
+func ConfigFromEnv() Config {
+	cfg := DefaultConfig()
+	cfg.BaseURL = os.Getenv("API_BASE_URL")
+	cfg.Timeout = config.Duration("EXTERNAL_API_TIMEOUT", cfg.Timeout)
+	cfg.MaxRetries = config.Int("EXTERNAL_API_RETRIES", cfg.MaxRetries)
+	cfg.InitialBackoff = config.Duration("EXTERNAL_API_BACKOFF", cfg.InitialBackoff)
+	cfg.MaxBackoff = config.Duration("EXTERNAL_API_MAX_BACKOFF", cfg.MaxBackoff)
+	cfg.BreakerThreshold = config.Int("EXTERNAL_API_BREAKER_THRESHOLD", cfg.BreakerThreshold)
+	cfg.BreakerCooldown = config.Duration("EXTERNAL_API_BREAKER_COOLDOWN", cfg.BreakerCooldown)
+	return cfg
+}
+
+// Client запрашивает данные о песнях во внешнем API с таймаутами,
+// повторами с экспоненциальной паузой и автоматическим выключателем.
+type Client struct {
+	cfg     Config
+	http    *http.Client
+	breaker *circuitBreaker
+}
+
+// NewClient создаёт клиент внешнего API.
+func NewClient(cfg Config) *Client {
+	return &Client{
+		cfg:     cfg,
+		http:    &http.Client{Timeout: cfg.Timeout},
+		breaker: newCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+	}
+}
+
+// FetchSongData запрашивает данные о песне.
+// Возвращает ErrNotFound, если песня неизвестна внешнему API, и ошибку,
+// оборачивающую ErrUnavailable, если API недоступен.
+func (c *Client) FetchSongData(ctx context.Context, group, song string) (*SongData, error) {
+	if !c.breaker.allow() {
+		logrus.Warn("Запрос к внешнему API пропущен: автоматический выключатель разомкнут")
+		return nil, ErrCircuitOpen
+	}
+
+	apiURL := strings.TrimRight(c.cfg.BaseURL, "/") + "/info?" + url.Values{
+		"group": {group},
+		"song":  {song},
+	}.Encode()
+
+	var lastErr error
+	for attempt := 0; attempt <= c.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			delay := c.backoff(attempt)
+			logrus.Warnf("Повтор запроса к внешнему API через %s (попытка %d): %v", delay, attempt+1, lastErr)
+			select {
+			case <-ctx.Done():
+				c.fail(ctx)
+				return nil, fmt.Errorf("%w: %v", ErrUnavailable, ctx.Err())
+			case <-time.After(delay):
+			}
+		}
+
+		logrus.Infof("Запрос данных через внешний API: %s", apiURL)
+		data, err := c.fetch(ctx, apiURL)
+		if err == nil {
+			c.breaker.success()
+			logrus.Infof("Данные успешно получены: %+v", *data)
+			return data, nil
+		}
+
+		var statusErr *StatusError
+		switch {
+		case errors.Is(err, ErrNotFound):
+			// Внешний API ответил корректно, просто не знает песню.
+			c.breaker.success()
+			return nil, err
+		case errors.As(err, &statusErr) && !statusErr.retryable():
+			c.breaker.success()
+			return nil, err
+		case errors.Is(err, ErrInvalidResponse):
+			c.breaker.success()
+			return nil, err
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	c.fail(ctx)
+	return nil, fmt.Errorf("%w: %v", ErrUnavailable, lastErr)
+}
+
+// fail отмечает в автоматическом выключателе неудачный запрос. Запрос, отменённый
+// вызывающим (например, клиент закрыл соединение), неудачей не считается.
+func (c *Client) fail(ctx context.Context) {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		c.breaker.cancel()
+		return
+	}
+	c.breaker.failure()
+}
+
+// fetch выполняет одну попытку запроса.
+func (c *Client) fetch(ctx context.Context, apiURL string) (*SongData, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		if err := Body.Close(); err != nil {
+			logrus.Errorf("Ошибка при закрытии тела ответа: %v", err)
+		}
+	}(resp.Body)
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	var data SongData
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	return &data, nil
+}
+
+// backoff возвращает паузу перед попыткой attempt: InitialBackoff * 2^(attempt-1)
+// со случайным разбросом до 20%, но не больше MaxBackoff.
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.cfg.InitialBackoff << (attempt - 1)
+	if delay <= 0 || (c.cfg.MaxBackoff > 0 && delay > c.cfg.MaxBackoff) {
+		delay = c.cfg.MaxBackoff
+	}
+	if delay > 0 {
+		delay += time.Duration(rand.Int63n(int64(delay)/5 + 1))
+	}
+	return delay
+}
@@ -0,0 +1,137 @@
+// Package idempotency хранит ответы на запросы с заголовком Idempotency-Key.
+//
+// Клиент, не получивший ответа, повторяет запрос с тем же ключом и получает сохранённый
+// ответ, а сам запрос повторно не выполняется. Перед выполнением запроса ключ резервируется,
+// поэтому из одновременных повторов выполняется только один. Ответы хранятся в базе данных
+// в течение TTL, поэтому повтор работает и после перезапуска сервера; просроченные ответы
+// периодически удаляются.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"music_storage/internal/config"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Config задаёт параметры хранения ответов.
+type Config struct {
+	// TTL — время, в течение которого повтор запроса с тем же ключом получает сохранённый ответ.
+	TTL time.Duration
+	// LockTimeout — время, в течение которого действует резервация ключа. Если сервер
+	// остановился, не записав ответ, запрос с этим ключом можно выполнить снова по истечении
+	// LockTimeout.
+	LockTimeout     time.Duration
+	CleanupInterval time.Duration
+}
+
+// maxReserveAttempts — число попыток зарезервировать ключ, резервацию которого снимают
+// одновременно с попыткой.
+const maxReserveAttempts = 3
+
+// DefaultConfig возвращает параметры хранения ответов по умолчанию.
+func DefaultConfig() Config {
+	return Config{
+		TTL:             24 * time.Hour,
+		LockTimeout:     time.Minute,
+		CleanupInterval: time.Hour,
+	}
+}
+
+// ConfigFromEnv читает параметры хранения ответов из переменных окружения IDEMPOTENCY_KEY_TTL,
+// IDEMPOTENCY_LOCK_TIMEOUT и IDEMPOTENCY_CLEANUP_INTERVAL.
+func ConfigFromEnv() Config {
+	cfg := DefaultConfig()
+	cfg.TTL = config.Duration("IDEMPOTENCY_KEY_TTL", cfg.TTL)
+	cfg.LockTimeout = config.Duration("IDEMPOTENCY_LOCK_TIMEOUT", cfg.LockTimeout)
+	cfg.CleanupInterval = config.Duration("IDEMPOTENCY_CLEANUP_INTERVAL", cfg.CleanupInterval)
+	return cfg
+}
+
+// Store сохраняет ответы и находит их по ключу.
+type Store struct {
+	responses db.IdempotencyRepository
+	cfg       Config
+	now       func() time.Time
+}
+
+// New создаёт хранилище ответов. Очистка просроченных ответов запускается методом Start.
+func New(responses db.IdempotencyRepository, cfg Config) *Store {
+	defaults := DefaultConfig()
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaults.TTL
+	}
+	if cfg.LockTimeout <= 0 {
+		cfg.LockTimeout = defaults.LockTimeout
+	}
+	if cfg.CleanupInterval <= 0 {
+		cfg.CleanupInterval = defaults.CleanupInterval
+	}
+	return &Store{responses: responses, cfg: cfg, now: time.Now}
+}
+
+// Start запускает периодическое удаление просроченных ответов. Останавливается при отмене ctx.
+func (s *Store) Start(ctx context.Context) {
+	go s.run(ctx)
+	logrus.Infof("Запущена очистка ключей идемпотентности, время хранения ответов: %s", s.cfg.TTL)
+}
+
+// Reserve резервирует ключ для запроса request на LockTimeout и возвращает true. Если ключ
+// уже занят сохранённым ответом или резервацией другого выполняющегося запроса, возвращает
+// их и false.
+func (s *Store) Reserve(key, request string) (*models.IdempotentResponse, bool, error) {
+	for attempt := 0; attempt < maxReserveAttempts; attempt++ {
+		now := s.now().UTC()
+		reservation := &models.IdempotentResponse{Key: key, Request: request, ExpiresAt: now.Add(s.cfg.LockTimeout)}
+		reserved, err := s.responses.Reserve(reservation, now)
+		if err != nil || reserved {
+			return nil, reserved, err
+		}
+
+		existing, err := s.responses.Get(key)
+		if errors.Is(err, db.ErrNotFound) {
+			// Резервацию сняли после нашей попытки — ключ снова свободен.
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return existing, false, nil
+	}
+	return nil, false, errors.New("не удалось зарезервировать ключ идемпотентности")
+}
+
+// Save записывает ответ на зарезервированный ключ на TTL.
+func (s *Store) Save(response *models.IdempotentResponse) error {
+	response.ExpiresAt = s.now().UTC().Add(s.cfg.TTL)
+	return s.responses.Complete(response)
+}
+
+// Release снимает резервацию ключа, чтобы запрос можно было повторить.
+func (s *Store) Release(key string) error {
+	return s.responses.Release(key)
+}
+
+func (s *Store) run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := s.responses.DeleteExpired(s.now().UTC())
+		if err != nil {
+			logrus.Errorf("Ошибка удаления просроченных ключей идемпотентности: %v", err)
+		} else if deleted > 0 {
+			logrus.Infof("Удалено просроченных ключей идемпотентности: %d", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
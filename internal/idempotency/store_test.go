@@ -0,0 +1,95 @@
+package idempotency
+
+import (
+	"music_storage/internal/db"
+	"music_storage/internal/migrations"
+	"music_storage/internal/models"
+	"testing"
+	"time"
+)
+
+// storages возвращает хранилища ответов, на которых проверяется Store: в памяти и в SQLite.
+func storages(t *testing.T) map[string]db.IdempotencyRepository {
+	t.Helper()
+	t.Setenv("DB_DRIVER", db.DriverSQLite)
+	t.Setenv("DB_PATH", t.TempDir()+"/test.db")
+	conn := db.Connect()
+	m, err := migrations.New(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+	return map[string]db.IdempotencyRepository{
+		"memory": db.NewMemoryStorage().IdempotencyKeys,
+		"sqlite": db.NewGormStorage(conn).IdempotencyKeys,
+	}
+}
+
+func TestStoreReserve(t *testing.T) {
+	for name, responses := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+			store := New(responses, Config{TTL: time.Hour, LockTimeout: time.Minute, CleanupInterval: time.Hour})
+			store.now = func() time.Time { return now }
+
+			if _, reserved, err := store.Reserve("k", "DELETE /songs/1"); err != nil || !reserved {
+				t.Fatalf("первая резервация: reserved = %v, err = %v", reserved, err)
+			}
+			saved, reserved, err := store.Reserve("k", "DELETE /songs/1")
+			if err != nil || reserved || !saved.Pending() {
+				t.Fatalf("повтор во время выполнения: saved = %+v, reserved = %v, err = %v", saved, reserved, err)
+			}
+
+			if err := store.Save(&models.IdempotentResponse{Key: "k", Request: "DELETE /songs/1", Status: 200, ContentType: "application/json", Body: "{}"}); err != nil {
+				t.Fatal(err)
+			}
+			saved, reserved, err = store.Reserve("k", "DELETE /songs/1")
+			if err != nil || reserved || saved.Pending() || saved.Status != 200 || saved.Body != "{}" {
+				t.Fatalf("повтор после ответа: saved = %+v, reserved = %v, err = %v", saved, reserved, err)
+			}
+
+			// Просроченный ответ, ещё не удалённый очисткой, не мешает новой резервации.
+			now = now.Add(2 * time.Hour)
+			if _, reserved, err := store.Reserve("k", "DELETE /songs/2"); err != nil || !reserved {
+				t.Fatalf("резервация просроченного ключа: reserved = %v, err = %v", reserved, err)
+			}
+
+			// Снятая резервация освобождает ключ.
+			if err := store.Release("k"); err != nil {
+				t.Fatal(err)
+			}
+			if _, reserved, err := store.Reserve("k", "DELETE /songs/2"); err != nil || !reserved {
+				t.Fatalf("резервация после снятия: reserved = %v, err = %v", reserved, err)
+			}
+
+			// Резервация остановленного запроса истекает через LockTimeout.
+			now = now.Add(2 * time.Minute)
+			if _, reserved, err := store.Reserve("k", "DELETE /songs/3"); err != nil || !reserved {
+				t.Fatalf("резервация после LockTimeout: reserved = %v, err = %v", reserved, err)
+			}
+		})
+	}
+}
+
+func TestStoreReleaseKeepsSavedResponse(t *testing.T) {
+	for name, responses := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			store := New(responses, DefaultConfig())
+			if _, _, err := store.Reserve("k", "DELETE /songs/1"); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Save(&models.IdempotentResponse{Key: "k", Request: "DELETE /songs/1", Status: 404}); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Release("k"); err != nil {
+				t.Fatal(err)
+			}
+			saved, reserved, err := store.Reserve("k", "DELETE /songs/1")
+			if err != nil || reserved || saved.Status != 404 {
+				t.Fatalf("saved = %+v, reserved = %v, err = %v", saved, reserved, err)
+			}
+		})
+	}
+}
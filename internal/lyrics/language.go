@@ -0,0 +1,89 @@
+package lyrics
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// languageTag — код языка в духе BCP 47: основной язык из 2–3 букв и необязательные
+// уточнения через дефис, например en, pt-br, zh-hant.
+var languageTag = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{1,8})*$`)
+
+// NormalizeLanguage приводит код языка к нижнему регистру с дефисами («en_US» → «en-us»)
+// и проверяет его. Возвращает false для некорректного кода.
+func NormalizeLanguage(code string) (string, bool) {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "_", "-"))
+	return code, languageTag.MatchString(code)
+}
+
+// primaryLanguage возвращает основной язык кода: «en» для «en-us».
+func primaryLanguage(code string) string {
+	primary, _, _ := strings.Cut(code, "-")
+	return primary
+}
+
+// Negotiate выбирает из available язык, наиболее подходящий заголовку Accept-Language.
+// Язык подходит диапазону, если совпадает с ним, уточняет его («en-gb» для «en») или имеет
+// тот же основной язык. Диапазоны перебираются по убыванию веса q, при равном весе — в
+// порядке заголовка; «*» выбирает первый из available. Диапазон с q=0 исключает язык и
+// уточняющие его языки. Возвращает false, если ничего не подошло.
+func Negotiate(header string, available []string) (string, bool) {
+	type languageRange struct {
+		code    string
+		quality float64
+	}
+	var ranges []languageRange
+	var excluded []string
+	for _, part := range strings.Split(header, ",") {
+		code, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if quality, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		code = strings.TrimSpace(code)
+		if quality <= 0 {
+			if normalized, ok := NormalizeLanguage(code); ok {
+				excluded = append(excluded, normalized)
+			}
+			continue
+		}
+		if code == "*" {
+			ranges = append(ranges, languageRange{code: code, quality: quality})
+		} else if normalized, ok := NormalizeLanguage(code); ok {
+			ranges = append(ranges, languageRange{code: normalized, quality: quality})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].quality > ranges[j].quality })
+
+	var acceptable []string
+	for _, code := range available {
+		if !slices.ContainsFunc(excluded, func(r string) bool { return code == r || strings.HasPrefix(code, r+"-") }) {
+			acceptable = append(acceptable, code)
+		}
+	}
+	available = acceptable
+
+	for _, r := range ranges {
+		if r.code == "*" && len(available) > 0 {
+			return available[0], true
+		}
+		for _, match := range []func(code string) bool{
+			func(code string) bool { return code == r.code },
+			func(code string) bool { return strings.HasPrefix(code, r.code+"-") },
+			func(code string) bool { return primaryLanguage(code) == primaryLanguage(r.code) },
+		} {
+			for _, code := range available {
+				if match(code) {
+					return code, true
+				}
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,46 @@
+package lyrics
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	available := []string{"ru", "en", "en-gb", "pt-br", "de"}
+	tests := []struct {
+		header    string
+		available []string
+		want      string
+	}{
+		{header: "", want: ""},
+		{header: "en", want: "en"},
+		{header: "EN-gb", want: "en-gb"},
+		{header: "en_GB", want: "en-gb"},
+		{header: "en-US", want: "en"},
+		{header: "pt", want: "pt-br"},
+		{header: "pt-PT", want: "pt-br"},
+		{header: "fr", want: ""},
+		{header: "fr, de;q=0.5, en;q=0.8", want: "en"},
+		{header: "de;q=0.5, en;q=0.5", want: "de"},
+		{header: "en;q=0.9, ru;q=0.95", want: "ru"},
+		{header: "en;q=abc, de", want: "de"},
+		{header: "en!, de;q=0.1", want: "de"},
+		{header: "en;q=0, de;q=0.5", want: "de"},
+		{header: "en;q=0, en-us", want: ""},
+		{header: "ru;q=0.000", want: ""},
+		{header: "*", want: "ru"},
+		{header: "fr, *;q=0.1, de;q=0.5", want: "de"},
+		{header: "*, ru;q=0", want: "en"},
+		{header: "*, ru;q=0, en;q=0", want: "pt-br"},
+		{header: "*", available: []string{}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			codes := available
+			if tt.available != nil {
+				codes = tt.available
+			}
+			got, ok := Negotiate(tt.header, codes)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("Negotiate(%q, %v) = %q, %v, ожидалось %q", tt.header, codes, got, ok, tt.want)
+			}
+		})
+	}
+}
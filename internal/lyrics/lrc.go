@@ -0,0 +1,225 @@
+package lyrics
+
+import (
+	"fmt"
+	"music_storage/internal/models"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Формат LRC: строка текста начинается с одной или нескольких меток времени [мм:сс.хх],
+// теги [ar:...], [ti:...], [length:...], [offset:...] и т. п. описывают песню. В расширенном
+// формате у каждого слова есть своя метка <мм:сс.хх>.
+
+var (
+	lineTimes = regexp.MustCompile(`^(\[[^\]]*\])+`)
+	lineTag   = regexp.MustCompile(`^\[([A-Za-z#]+):(.*)\]$`)
+	wordTime  = regexp.MustCompile(`<([^>]*)>`)
+	timestamp = regexp.MustCompile(`^(\d+):(\d{1,2})(?:[.:](\d{1,3}))?$`)
+)
+
+// ParseTime разбирает время в формате мм:сс, мм:сс.х, мм:сс.хх или мм:сс.ххх и возвращает его
+// в миллисекундах.
+func ParseTime(value string) (int, bool) {
+	match := timestamp.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return 0, false
+	}
+	minutes, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+	seconds, _ := strconv.Atoi(match[2])
+	if seconds > 59 {
+		return 0, false
+	}
+	ms := 0
+	if fraction := match[3]; fraction != "" {
+		ms, _ = strconv.Atoi((fraction + "00")[:3])
+	}
+	return (minutes*60+seconds)*1000 + ms, true
+}
+
+// FormatTime записывает время в миллисекундах в формате LRC мм:сс.хх.
+func FormatTime(ms int) string {
+	return fmt.Sprintf("%02d:%02d.%02d", ms/60000, ms/1000%60, ms%1000/10)
+}
+
+// ParseLRC разбирает синхронизированный текст в формате LRC, в том числе расширенном.
+// Строка с несколькими метками времени повторяется для каждой из них, тег [offset:]
+// сдвигает все метки. Метки не должны быть позже duration (в секундах), а если длительность
+// песни неизвестна (0) — позже тега [length:], если он есть. Строки возвращаются по времени.
+func ParseLRC(data string, duration int) ([]models.LyricsLine, error) {
+	var lines []models.LyricsLine
+	offset, length := 0, 0
+	for i, raw := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		number := i + 1
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		if tag := lineTag.FindStringSubmatch(raw); tag != nil {
+			value := strings.TrimSpace(tag[2])
+			switch strings.ToLower(tag[1]) {
+			case "offset":
+				var err error
+				if offset, err = strconv.Atoi(strings.TrimPrefix(value, "+")); err != nil {
+					return nil, fmt.Errorf("строка %d: некорректный тег offset %q", number, value)
+				}
+			case "length":
+				var ok bool
+				if length, ok = ParseTime(value); !ok {
+					return nil, fmt.Errorf("строка %d: некорректный тег length %q", number, value)
+				}
+			}
+			continue
+		}
+
+		prefix := lineTimes.FindString(raw)
+		if prefix == "" {
+			return nil, fmt.Errorf("строка %d: нет метки времени", number)
+		}
+		words, text, err := parseWords(strings.TrimSpace(raw[len(prefix):]))
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: %w", number, err)
+		}
+		for _, value := range strings.Split(strings.Trim(prefix, "[]"), "][") {
+			start, ok := ParseTime(value)
+			if !ok {
+				return nil, fmt.Errorf("строка %d: некорректная метка времени [%s]", number, value)
+			}
+			for _, word := range words {
+				if word.StartMs >= 0 && word.StartMs < start {
+					return nil, fmt.Errorf("строка %d: слово начинается раньше строки [%s]", number, value)
+				}
+			}
+			line := models.LyricsLine{StartMs: start, Text: text}
+			line.Words = append(line.Words, words...)
+			if len(words) > 0 && words[0].StartMs < 0 {
+				line.Words[0].StartMs = start
+			}
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("нет ни одной строки с меткой времени")
+	}
+
+	limit := duration * 1000
+	if limit == 0 {
+		limit = length
+	}
+	for i := range lines {
+		lines[i].StartMs = max(lines[i].StartMs-offset, 0)
+		for j := range lines[i].Words {
+			lines[i].Words[j].StartMs = max(lines[i].Words[j].StartMs-offset, 0)
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].StartMs < lines[j].StartMs })
+	if end := End(lines); limit > 0 && end > limit {
+		return nil, fmt.Errorf("метка времени %s позже конца песни %s", FormatTime(end), FormatTime(limit))
+	}
+	for i := range lines {
+		lines[i].Position = i + 1
+		for j := range lines[i].Words {
+			lines[i].Words[j].Line = i + 1
+			lines[i].Words[j].Position = j + 1
+		}
+	}
+	return lines, nil
+}
+
+// End возвращает самую позднюю метку времени строк и их слов в миллисекундах.
+func End(lines []models.LyricsLine) int {
+	end := 0
+	for _, line := range lines {
+		end = max(end, line.StartMs)
+		for _, word := range line.Words {
+			end = max(end, word.StartMs)
+		}
+	}
+	return end
+}
+
+// parseWords разбирает слова строки расширенного формата и возвращает их вместе с текстом
+// строки без меток. Текст до первой метки слова начинается вместе со строкой: его время
+// отмечено как -1 и заменяется временем строки.
+func parseWords(text string) ([]models.LyricsWord, string, error) {
+	matches := wordTime.FindAllStringSubmatchIndex(text, -1)
+	plain := strings.Join(strings.Fields(wordTime.ReplaceAllString(text, "")), " ")
+	if len(matches) == 0 {
+		return nil, plain, nil
+	}
+
+	var words []models.LyricsWord
+	if lead := text[:matches[0][0]]; strings.TrimSpace(lead) != "" {
+		words = append(words, models.LyricsWord{StartMs: -1, Text: lead})
+	}
+	for i, match := range matches {
+		value := text[match[2]:match[3]]
+		start, ok := ParseTime(value)
+		if !ok {
+			return nil, "", fmt.Errorf("некорректная метка слова <%s>", value)
+		}
+		if len(words) > 0 && start < words[len(words)-1].StartMs {
+			return nil, "", fmt.Errorf("метка слова <%s> раньше предыдущей", value)
+		}
+		end := len(text)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		words = append(words, models.LyricsWord{StartMs: start, Text: text[match[1]:end]})
+	}
+	return words, plain, nil
+}
+
+// FormatLRC записывает строки синхронизированного текста в формате LRC. Теги tags
+// (например, ar и ti) выводятся в начале в порядке ключей; пустые значения пропускаются.
+func FormatLRC(lines []models.LyricsLine, tags map[string]string) string {
+	var b strings.Builder
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if tags[key] != "" {
+			fmt.Fprintf(&b, "[%s:%s]\n", key, tags[key])
+		}
+	}
+
+	for _, line := range lines {
+		b.WriteString("[" + FormatTime(line.StartMs) + "]")
+		if len(line.Words) == 0 {
+			b.WriteString(line.Text)
+		}
+		for _, word := range line.Words {
+			b.WriteString("<" + FormatTime(word.StartMs) + ">" + word.Text)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// LineAt возвращает строку, которая звучит в момент at (в миллисекундах): последнюю строку,
+// начавшуюся не позже at. Возвращает false, если ни одна строка ещё не началась.
+func LineAt(lines []models.LyricsLine, at int) (models.LyricsLine, bool) {
+	i := sort.Search(len(lines), func(i int) bool { return lines[i].StartMs > at })
+	if i == 0 {
+		return models.LyricsLine{}, false
+	}
+	return lines[i-1], true
+}
+
+// WordAt возвращает слово строки, которое звучит в момент at, без пробелов по краям.
+// Возвращает пустую строку, если слова строки не размечены или at между словами.
+func WordAt(line models.LyricsLine, at int) string {
+	i := sort.Search(len(line.Words), func(i int) bool { return line.Words[i].StartMs > at })
+	if i == 0 {
+		return ""
+	}
+	return strings.TrimSpace(line.Words[i-1].Text)
+}
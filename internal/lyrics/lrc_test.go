@@ -0,0 +1,243 @@
+package lyrics
+
+import (
+	"music_storage/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+		ok    bool
+	}{
+		{"01:02", 62000, true},
+		{"1:02.5", 62500, true},
+		{"01:02.34", 62340, true},
+		{"01:02.345", 62345, true},
+		{"01:02:34", 62340, true},
+		{" 120:00.00 ", 7200000, true},
+		{"01:60.00", 0, false},
+		{"01:2.3456", 0, false},
+		{"-1:00", 0, false},
+		{"aa:bb", 0, false},
+		{"01", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseTime(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseTime(%q) = %d, %v, ожидалось %d, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// line возвращает строку с позицией position, начинающуюся в start.
+func line(position, start int, text string, words ...models.LyricsWord) models.LyricsLine {
+	for i := range words {
+		words[i].Line = position
+		words[i].Position = i + 1
+	}
+	return models.LyricsLine{Position: position, StartMs: start, Text: text, Words: words}
+}
+
+// word возвращает слово, начинающееся в start.
+func word(start int, text string) models.LyricsWord {
+	return models.LyricsWord{StartMs: start, Text: text}
+}
+
+func TestParseLRC(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		duration int
+		want     []models.LyricsLine
+	}{
+		{
+			name: "теги песни пропускаются",
+			data: "[ar:Queen]\r\n[ti:Innuendo]\r\n\r\n[00:01.00] Первая   строка \r\n[00:02.50]Вторая\r\n",
+			want: []models.LyricsLine{line(1, 1000, "Первая строка"), line(2, 2500, "Вторая")},
+		},
+		{
+			name: "несколько меток времени в строке",
+			data: "[00:10.00][00:30.00]Припев\n[00:20.00]Куплет",
+			want: []models.LyricsLine{line(1, 10000, "Припев"), line(2, 20000, "Куплет"), line(3, 30000, "Припев")},
+		},
+		{
+			name: "строки упорядочиваются по времени",
+			data: "[00:03.00]c\n[00:01.00]a\n[00:02.00]b",
+			want: []models.LyricsLine{line(1, 1000, "a"), line(2, 2000, "b"), line(3, 3000, "c")},
+		},
+		{
+			name: "положительный offset сдвигает метки раньше, но не раньше нуля",
+			data: "[offset:+500]\n[00:01.00]a\n[00:00.20]b",
+			want: []models.LyricsLine{line(1, 0, "b"), line(2, 500, "a")},
+		},
+		{
+			name: "отрицательный offset сдвигает метки и слова позже",
+			data: "[00:01.00]a <00:01.50>b\n[OFFSET: -250]",
+			want: []models.LyricsLine{line(1, 1250, "a b", word(1250, "a "), word(1750, "b"))},
+		},
+		{
+			name: "метки слов",
+			data: "[00:05.00]<00:05.00>Hello <00:05.50>big <00:06.00>world",
+			want: []models.LyricsLine{line(1, 5000, "Hello big world", word(5000, "Hello "), word(5500, "big "), word(6000, "world"))},
+		},
+		{
+			name: "текст до первой метки слова начинается со строкой",
+			data: "[00:05.00]Hello <00:05.50>world",
+			want: []models.LyricsLine{line(1, 5000, "Hello world", word(5000, "Hello "), word(5500, "world"))},
+		},
+		{
+			name: "метка слова повторяется для каждой метки строки",
+			data: "[00:01.00][00:03.00]<00:03.50>la",
+			want: []models.LyricsLine{
+				line(1, 1000, "la", word(3500, "la")),
+				line(2, 3000, "la", word(3500, "la")),
+			},
+		},
+		{
+			name:     "метка на конце песни",
+			data:     "[00:10.00]a",
+			duration: 10,
+			want:     []models.LyricsLine{line(1, 10000, "a")},
+		},
+		{
+			name: "тег length ограничивает метки, если длительность неизвестна",
+			data: "[length: 00:30]\n[00:29.99]a",
+			want: []models.LyricsLine{line(1, 29990, "a")},
+		},
+		{
+			name:     "длительность песни важнее тега length",
+			data:     "[length:00:30]\n[00:45.00]a",
+			duration: 60,
+			want:     []models.LyricsLine{line(1, 45000, "a")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLRC(tt.data, tt.duration)
+			if err != nil {
+				t.Fatalf("ParseLRC(): %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLRC() = %+v, ожидалось %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLRCErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		duration int
+	}{
+		{"пустой текст", "", 0},
+		{"только теги", "[ar:Queen]\n[ti:Innuendo]", 0},
+		{"строка без метки", "[00:01.00]a\nb", 0},
+		{"секунд больше 59", "[00:61.00]a", 0},
+		{"буквы вместо времени", "[aa:bb]a", 0},
+		{"некорректная вторая метка", "[00:01.00][1:2:3:4]a", 0},
+		{"некорректная метка слова", "[00:01.00]a <0x:00>b", 0},
+		{"слово раньше строки", "[00:05.00]<00:04.00>a", 0},
+		{"слово раньше второй метки строки", "[00:01.00][00:05.00]<00:03.00>a", 0},
+		{"слова не по порядку", "[00:01.00]<00:03.00>a <00:02.00>b", 0},
+		{"некорректный offset", "[offset:soon]\n[00:01.00]a", 0},
+		{"некорректный length", "[length:long]\n[00:01.00]a", 0},
+		{"метка позже конца песни", "[00:10.01]a", 10},
+		{"слово позже конца песни", "[00:09.00]a <00:10.50>b", 10},
+		{"метка позже тега length", "[length:00:30]\n[00:31.00]a", 0},
+		{"offset не спасает от длительности", "[offset:-2000]\n[00:09.00]a", 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if lines, err := ParseLRC(tt.data, tt.duration); err == nil {
+				t.Errorf("ParseLRC(%q) = %+v без ошибки", tt.data, lines)
+			}
+		})
+	}
+}
+
+func TestFormatLRC(t *testing.T) {
+	lines := []models.LyricsLine{
+		line(1, 1000, "Первая строка"),
+		line(2, 62340, "Hello world", word(62340, "Hello "), word(63000, "world")),
+	}
+	got := FormatLRC(lines, map[string]string{"ti": "Innuendo", "ar": "Queen", "al": ""})
+	want := "[ar:Queen]\n[ti:Innuendo]\n[00:01.00]Первая строка\n[01:02.34]<01:02.34>Hello <01:03.00>world\n"
+	if got != want {
+		t.Errorf("FormatLRC() = %q, ожидалось %q", got, want)
+	}
+}
+
+func TestFormatLRCRoundTrip(t *testing.T) {
+	for _, data := range []string{
+		"[00:01.00]Первая строка\n[00:02.50]Вторая\n",
+		"[00:10.00][00:30.00]Припев\n[00:20.00]Куплет\n",
+		"[ar:Queen]\n[00:05.00]Hello <00:05.50>big <00:06.00>world\n[01:00.10]<01:00.10>la <01:00.20>la\n",
+		"[offset:+500]\n[00:01.00]a <00:01.50>b\n",
+	} {
+		lines, err := ParseLRC(data, 0)
+		if err != nil {
+			t.Fatalf("ParseLRC(%q): %v", data, err)
+		}
+		formatted := FormatLRC(lines, map[string]string{"ar": "Queen"})
+		again, err := ParseLRC(formatted, 0)
+		if err != nil {
+			t.Fatalf("ParseLRC(%q) после FormatLRC: %v", formatted, err)
+		}
+		if !reflect.DeepEqual(again, lines) {
+			t.Errorf("после FormatLRC %q разобрано %+v, ожидалось %+v", formatted, again, lines)
+		}
+	}
+}
+
+func TestLineAt(t *testing.T) {
+	lines := []models.LyricsLine{line(1, 1000, "a"), line(2, 2000, "b"), line(3, 2000, "c"), line(4, 5000, "d")}
+	tests := []struct {
+		at   int
+		want string
+	}{
+		{0, ""},
+		{999, ""},
+		{1000, "a"},
+		{1999, "a"},
+		{2000, "c"},
+		{4999, "c"},
+		{5000, "d"},
+		{600000, "d"},
+	}
+	for _, tt := range tests {
+		got, ok := LineAt(lines, tt.at)
+		if ok != (tt.want != "") || got.Text != tt.want {
+			t.Errorf("LineAt(%d) = %q, %v, ожидалась строка %q", tt.at, got.Text, ok, tt.want)
+		}
+	}
+	if _, ok := LineAt(nil, 1000); ok {
+		t.Error("LineAt() без строк нашёл строку")
+	}
+}
+
+func TestWordAt(t *testing.T) {
+	synced := line(1, 5000, "Hello big world", word(5000, "Hello "), word(5500, "big "), word(6000, "world"))
+	tests := []struct {
+		line models.LyricsLine
+		at   int
+		want string
+	}{
+		{synced, 4999, ""},
+		{synced, 5000, "Hello"},
+		{synced, 5499, "Hello"},
+		{synced, 5700, "big"},
+		{synced, 6000, "world"},
+		{synced, 60000, "world"},
+		{line(1, 5000, "Hello world"), 5500, ""},
+	}
+	for _, tt := range tests {
+		if got := WordAt(tt.line, tt.at); got != tt.want {
+			t.Errorf("WordAt(%q, %d) = %q, ожидалось %q", tt.line.Text, tt.at, got, tt.want)
+		}
+	}
+}
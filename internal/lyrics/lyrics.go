@@ -0,0 +1,100 @@
+// Package lyrics разбирает текст песни на разделы и собирает текст из разделов.
+package lyrics
+
+import (
+	"music_storage/internal/models"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Types — допустимые типы разделов в порядке, в котором они перечисляются в API.
+var Types = []string{
+	models.SectionVerse,
+	models.SectionChorus,
+	models.SectionBridge,
+	models.SectionIntro,
+	models.SectionOutro,
+}
+
+// blankLines разделяет блоки текста: одна или несколько пустых строк, в том числе из пробелов.
+var blankLines = regexp.MustCompile(`\n[ \t]*\n\s*`)
+
+// IsType проверяет, что t — допустимый тип раздела.
+func IsType(t string) bool {
+	return slices.Contains(Types, t)
+}
+
+// Split разбивает текст на блоки, разделённые пустыми строками. Пробелы по краям блоков
+// отбрасываются, пустые блоки пропускаются.
+func Split(text string) []string {
+	var blocks []string
+	for _, block := range blankLines.Split(strings.ReplaceAll(text, "\r\n", "\n"), -1) {
+		if block = strings.TrimSpace(block); block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// Detect разбирает обычный текст на разделы. Блок, который встречается в тексте несколько
+// раз (без учёта регистра и пробелов), считается припевом: первое вхождение хранит текст,
+// остальные ссылаются на него. Остальные блоки — куплеты.
+//
+// Эвристика грубая: припевом становится любой повторяющийся блок, в том числе повторённый
+// куплет, вступление или концовка, и каждая группа повторов — отдельным припевом. Припев,
+// который при повторе хоть немного меняется, не распознаётся. Точную разметку задают
+// вручную через PUT /songs/{id}/lyrics.
+func Detect(text string) []models.LyricsSection {
+	blocks := Split(text)
+	counts := make(map[string]int)
+	for _, block := range blocks {
+		counts[blockKey(block)]++
+	}
+
+	first := make(map[string]int)
+	sections := make([]models.LyricsSection, 0, len(blocks))
+	for i, block := range blocks {
+		position := i + 1
+		key := blockKey(block)
+		section := models.LyricsSection{Position: position, Type: models.SectionVerse, Text: block}
+		if counts[key] > 1 {
+			section.Type = models.SectionChorus
+			if original, ok := first[key]; ok {
+				section.Text = ""
+				section.RepeatOf = &original
+			} else {
+				first[key] = position
+			}
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+func blockKey(block string) string {
+	return strings.Join(strings.Fields(strings.ToLower(block)), " ")
+}
+
+// Render собирает текст песни из разделов, подставляя текст повторов, и разделяет
+// разделы пустой строкой.
+func Render(sections []models.LyricsSection) string {
+	texts := make([]string, 0, len(sections))
+	for _, section := range sections {
+		texts = append(texts, Text(sections, section))
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// Text возвращает текст раздела, а для повтора — текст раздела, на который он ссылается.
+func Text(sections []models.LyricsSection, section models.LyricsSection) string {
+	if section.RepeatOf == nil {
+		return section.Text
+	}
+	for _, original := range sections {
+		if original.Position == *section.RepeatOf {
+			return original.Text
+		}
+	}
+	return ""
+}
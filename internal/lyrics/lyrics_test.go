@@ -0,0 +1,97 @@
+package lyrics
+
+import (
+	"music_storage/internal/models"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// repeatOf возвращает указатель на позицию position для models.LyricsSection.RepeatOf.
+func repeatOf(position int) *int {
+	return &position
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"  \n\n ", nil},
+		{"Одна строка", []string{"Одна строка"}},
+		{"Первая\nстрока\n\nВторой куплет", []string{"Первая\nстрока", "Второй куплет"}},
+		{"\n\nПервый\r\n\r\nВторой\n \t \n\n\nТретий  \n", []string{"Первый", "Второй", "Третий"}},
+	}
+	for _, tt := range tests {
+		if got := Split(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("Split(%q) = %q, ожидалось %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	verse := func(position int, text string) models.LyricsSection {
+		return models.LyricsSection{Position: position, Type: models.SectionVerse, Text: text}
+	}
+	chorus := func(position int, text string) models.LyricsSection {
+		return models.LyricsSection{Position: position, Type: models.SectionChorus, Text: text}
+	}
+	repeat := func(position, original int) models.LyricsSection {
+		return models.LyricsSection{Position: position, Type: models.SectionChorus, RepeatOf: repeatOf(original)}
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []models.LyricsSection
+	}{
+		{"пустой текст", "", []models.LyricsSection{}},
+		{"без повторов", "Куплет 1\n\nКуплет 2",
+			[]models.LyricsSection{verse(1, "Куплет 1"), verse(2, "Куплет 2")}},
+		{"припев между куплетами", "Куплет 1\n\nПрипев\nприпев\n\nКуплет 2\n\nприпев\n  ПРИПЕВ ",
+			[]models.LyricsSection{verse(1, "Куплет 1"), chorus(2, "Припев\nприпев"), verse(3, "Куплет 2"), repeat(4, 2)}},
+		{"припев трижды", "Припев\n\nКуплет\n\nПрипев\n\nПрипев",
+			[]models.LyricsSection{chorus(1, "Припев"), verse(2, "Куплет"), repeat(3, 1), repeat(4, 1)}},
+		// Эвристика: любой повторяющийся блок считается припевом, каждая группа — своим.
+		{"повторённое вступление тоже припев", "Ла-ла-ла\n\nКуплет\n\nПрипев\n\nПрипев\n\nЛа-ла-ла",
+			[]models.LyricsSection{chorus(1, "Ла-ла-ла"), verse(2, "Куплет"), chorus(3, "Припев"), repeat(4, 3), repeat(5, 1)}},
+		{"изменённый повтор не припев", "Припев\nпервый раз\n\nПрипев\nвторой раз",
+			[]models.LyricsSection{verse(1, "Припев\nпервый раз"), verse(2, "Припев\nвторой раз")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Detect() = %+v, ожидалось %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	sections := []models.LyricsSection{
+		{Position: 1, Type: models.SectionIntro, Text: "Вступление"},
+		{Position: 2, Type: models.SectionChorus, Text: "Припев"},
+		{Position: 3, Type: models.SectionVerse, Text: "Куплет"},
+		{Position: 4, Type: models.SectionChorus, RepeatOf: repeatOf(2)},
+		{Position: 5, Type: models.SectionOutro, RepeatOf: repeatOf(10)},
+	}
+	if got, want := Render(sections), "Вступление\n\nПрипев\n\nКуплет\n\nПрипев\n\n"; got != want {
+		t.Errorf("Render() = %q, ожидалось %q", got, want)
+	}
+	if got := Text(sections, sections[3]); got != "Припев" {
+		t.Errorf("Text() повтора = %q", got)
+	}
+	if got := Render(nil); got != "" {
+		t.Errorf("Render(nil) = %q", got)
+	}
+
+	// Render обратен Detect для текста с разделами через одну пустую строку.
+	text := "Куплет 1\n\nПрипев\n\nКуплет 2\n\nПрипев"
+	if got := Render(Detect(text)); got != text {
+		t.Errorf("Render(Detect()) = %q, ожидалось %q", got, text)
+	}
+	if got := Render(Detect("  Куплет\r\n\r\n\r\nПрипев \n \nПрипев\n")); got != "Куплет\n\nПрипев\n\nПрипев" {
+		t.Errorf("Render(Detect()) лишних пробелов и строк = %q", got)
+	}
+}
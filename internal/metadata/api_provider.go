@@ -0,0 +1,36 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"music_storage/internal/external"
+)
+
+// InfoAPIProvider получает метаданные из внешнего API API_BASE_URL/info.
+type InfoAPIProvider struct {
+	client *external.Client
+}
+
+// NewInfoAPIProvider создаёт источник поверх клиента внешнего API.
+func NewInfoAPIProvider(client *external.Client) *InfoAPIProvider {
+	return &InfoAPIProvider{client: client}
+}
+
+func (p *InfoAPIProvider) Name() string {
+	return "api"
+}
+
+func (p *InfoAPIProvider) Lookup(ctx context.Context, group, song string) (*SongData, error) {
+	data, err := p.client.FetchSongData(ctx, group, song)
+	if errors.Is(err, external.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &SongData{
+		ReleaseDate: data.ReleaseDate,
+		Text:        data.Text,
+		Link:        data.Link,
+	}, nil
+}
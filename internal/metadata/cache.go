@@ -0,0 +1,257 @@
+package metadata
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"music_storage/internal/config"
+	"music_storage/internal/db"
+	"music_storage/internal/models"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CacheConfig задаёт параметры кэша ответов источника.
+type CacheConfig struct {
+	// TTL — время жизни найденных метаданных.
+	TTL time.Duration
+	// NegativeTTL — время жизни ответа «песня не найдена». Нулевое значение отключает отрицательное кэширование.
+	NegativeTTL time.Duration
+	// Size — максимальное число записей в памяти; при переполнении вытесняются давно не использованные.
+	Size int
+	// Persistent включает хранение кэша в таблице metadata_cache.
+	Persistent bool
+	// CleanupInterval — интервал удаления просроченных записей постоянного кэша.
+	CleanupInterval time.Duration
+}
+
+// DefaultCacheConfig возвращает параметры кэша по умолчанию.
+func DefaultCacheConfig() CacheConfig {
+	return CacheConfig{
+		TTL:             24 * time.Hour,
+		NegativeTTL:     time.Hour,
+		Size:            1000,
+		CleanupInterval: time.Hour,
+	}
+}
+
+// CacheConfigFromEnv читает параметры кэша из переменных окружения METADATA_CACHE_TTL,
+// METADATA_CACHE_NEGATIVE_TTL, METADATA_CACHE_SIZE, METADATA_CACHE_PERSISTENT и
+// METADATA_CACHE_CLEANUP_INTERVAL.
+func CacheConfigFromEnv() CacheConfig {
+	cfg := DefaultCacheConfig()
+	cfg.TTL = config.Duration("METADATA_CACHE_TTL", cfg.TTL)
+	cfg.NegativeTTL = config.Duration("METADATA_CACHE_NEGATIVE_TTL", cfg.NegativeTTL)
+	cfg.Size = config.Int("METADATA_CACHE_SIZE", cfg.Size)
+	cfg.Persistent = config.Bool("METADATA_CACHE_PERSISTENT", cfg.Persistent)
+	cfg.CleanupInterval = config.Duration("METADATA_CACHE_CLEANUP_INTERVAL", cfg.CleanupInterval)
+	return cfg
+}
+
+// cacheItem — запись LRU-кэша. data == nil означает отрицательный ответ.
+type cacheItem struct {
+	key       string
+	data      *SongData
+	expiresAt time.Time
+}
+
+// CachedProvider кэширует ответы другого источника по нормализованной паре группа/песня.
+//
+// Записи хранятся в памяти с ограничением размера (LRU) и, если задано хранилище,
+// дублируются в таблицу metadata_cache, чтобы кэш переживал перезапуск.
+// Временные ошибки источника не кэшируются.
+type CachedProvider struct {
+	inner      MetadataProvider
+	cfg        CacheConfig
+	persistent db.MetadataCacheRepository
+	now        func() time.Time
+
+	mu    sync.Mutex
+	items map[string]*list.Element
+	order *list.List
+}
+
+// NewCachedProvider оборачивает источник inner кэшем. persistent может быть nil.
+// Очистка просроченных записей постоянного кэша запускается методом Start.
+func NewCachedProvider(inner MetadataProvider, cfg CacheConfig, persistent db.MetadataCacheRepository) *CachedProvider {
+	if cfg.CleanupInterval <= 0 {
+		cfg.CleanupInterval = DefaultCacheConfig().CleanupInterval
+	}
+	return &CachedProvider{
+		inner:      inner,
+		cfg:        cfg,
+		persistent: persistent,
+		now:        time.Now,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Name возвращает имя обёрнутого источника, чтобы кэш не влиял на политику объединения.
+func (p *CachedProvider) Name() string {
+	return p.inner.Name()
+}
+
+func (p *CachedProvider) Lookup(ctx context.Context, group, song string) (*SongData, error) {
+	key := p.inner.Name() + ":" + normalizeKey(group, song)
+
+	if item, ok := p.get(key); ok {
+		logrus.Debugf("Метаданные %s - %s взяты из кэша", group, song)
+		return cachedResult(item)
+	}
+	if item, ok := p.loadPersistent(key); ok {
+		logrus.Debugf("Метаданные %s - %s взяты из постоянного кэша", group, song)
+		p.set(item)
+		return cachedResult(item)
+	}
+
+	data, err := p.inner.Lookup(ctx, group, song)
+	switch {
+	case err == nil:
+		p.store(&cacheItem{key: key, data: data, expiresAt: p.now().Add(p.cfg.TTL)})
+	case errors.Is(err, ErrNotFound) && p.cfg.NegativeTTL > 0:
+		p.store(&cacheItem{key: key, expiresAt: p.now().Add(p.cfg.NegativeTTL)})
+	}
+	return data, err
+}
+
+func cachedResult(item *cacheItem) (*SongData, error) {
+	if item.data == nil {
+		return nil, ErrNotFound
+	}
+	data := *item.data
+	return &data, nil
+}
+
+// get возвращает действующую запись из памяти и отмечает её как недавно использованную.
+func (p *CachedProvider) get(key string) (*cacheItem, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	element, ok := p.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := element.Value.(*cacheItem)
+	if !p.now().Before(item.expiresAt) {
+		p.order.Remove(element)
+		delete(p.items, key)
+		return nil, false
+	}
+	p.order.MoveToFront(element)
+	return item, true
+}
+
+// set помещает запись в память, вытесняя давно не использованные записи сверх лимита.
+func (p *CachedProvider) set(item *cacheItem) {
+	if p.cfg.Size <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if element, ok := p.items[item.key]; ok {
+		element.Value = item
+		p.order.MoveToFront(element)
+		return
+	}
+	p.items[item.key] = p.order.PushFront(item)
+	for p.order.Len() > p.cfg.Size {
+		oldest := p.order.Back()
+		p.order.Remove(oldest)
+		delete(p.items, oldest.Value.(*cacheItem).key)
+	}
+}
+
+// store сохраняет запись в памяти и в постоянном кэше.
+func (p *CachedProvider) store(item *cacheItem) {
+	p.set(item)
+	if p.persistent == nil {
+		return
+	}
+
+	entry := &models.MetadataCacheEntry{
+		Key:       item.key,
+		Found:     item.data != nil,
+		ExpiresAt: item.expiresAt.UTC(),
+	}
+	if item.data != nil {
+		entry.ReleaseDate = item.data.ReleaseDate
+		entry.Text = item.data.Text
+		entry.Link = item.data.Link
+	}
+	if err := p.persistent.Put(entry); err != nil {
+		logrus.Warnf("Ошибка сохранения постоянного кэша метаданных: %v", err)
+	}
+}
+
+// loadPersistent читает действующую запись из постоянного кэша.
+func (p *CachedProvider) loadPersistent(key string) (*cacheItem, bool) {
+	if p.persistent == nil {
+		return nil, false
+	}
+
+	entry, err := p.persistent.Get(key)
+	if err != nil {
+		if !errors.Is(err, db.ErrNotFound) {
+			logrus.Warnf("Ошибка чтения постоянного кэша метаданных: %v", err)
+		}
+		return nil, false
+	}
+	if !p.now().Before(entry.ExpiresAt) {
+		return nil, false
+	}
+
+	item := &cacheItem{key: key, expiresAt: entry.ExpiresAt}
+	if entry.Found {
+		item.data = &SongData{
+			ReleaseDate: entry.ReleaseDate,
+			Text:        entry.Text,
+			Link:        entry.Link,
+		}
+	}
+	return item, true
+}
+
+// Start запускает периодическое удаление просроченных записей постоянного кэша: первое —
+// сразу, следующие — раз в CleanupInterval. Останавливается при отмене ctx. Без постоянного
+// кэша ничего не делает: записи в памяти ограничены размером и проверяются при чтении.
+func (p *CachedProvider) Start(ctx context.Context) {
+	if p.persistent == nil {
+		return
+	}
+	go p.run(ctx)
+}
+
+// PurgeExpired удаляет просроченные записи постоянного кэша.
+func (p *CachedProvider) PurgeExpired() {
+	if p.persistent == nil {
+		return
+	}
+	deleted, err := p.persistent.DeleteExpired(p.now().UTC())
+	if err != nil {
+		logrus.Warnf("Ошибка очистки постоянного кэша метаданных: %v", err)
+		return
+	}
+	if deleted > 0 {
+		logrus.Infof("Из постоянного кэша метаданных удалено просроченных записей: %d", deleted)
+	}
+}
+
+func (p *CachedProvider) run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		p.PurgeExpired()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"music_storage/internal/db"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingProvider — источник, который считает обращения к себе. Если err задан, он
+// возвращается вместо ответа inner.
+type countingProvider struct {
+	inner MetadataProvider
+	err   error
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func newCountingProvider(inner MetadataProvider) *countingProvider {
+	return &countingProvider{inner: inner, calls: make(map[string]int)}
+}
+
+func (p *countingProvider) Name() string { return p.inner.Name() }
+
+func (p *countingProvider) Lookup(ctx context.Context, group, song string) (*SongData, error) {
+	p.mu.Lock()
+	p.calls[song]++
+	err := p.err
+	p.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return p.inner.Lookup(ctx, group, song)
+}
+
+// callsFor возвращает число обращений к источнику за песней song.
+func (p *countingProvider) callsFor(song string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls[song]
+}
+
+// lookupAll запрашивает песни группы Queen у кэша.
+func lookupAll(t *testing.T, cache *CachedProvider, songs ...string) {
+	t.Helper()
+	for _, song := range songs {
+		if _, err := cache.Lookup(context.Background(), "Queen", song); err != nil && !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Lookup(%q): %v", song, err)
+		}
+	}
+}
+
+func queenCatalog() *StaticProvider {
+	return NewStaticProvider("api").
+		Add("Queen", "Innuendo", SongData{Text: "While the sun hangs in the sky"}).
+		Add("Queen", "Mustapha", SongData{Text: "Ibrahim"}).
+		Add("Queen", "Bijou", SongData{Text: "You and me"})
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	inner := newCountingProvider(queenCatalog())
+	cache := NewCachedProvider(inner, CacheConfig{TTL: time.Hour, Size: 2}, nil)
+
+	// Innuendo используется после Mustapha, поэтому при добавлении Bijou вытесняется Mustapha.
+	lookupAll(t, cache, "Innuendo", "Mustapha", "Innuendo", "Bijou", "Innuendo", "Mustapha")
+	for song, want := range map[string]int{"Innuendo": 1, "Mustapha": 2, "Bijou": 1} {
+		if got := inner.callsFor(song); got != want {
+			t.Errorf("обращений за %s: %d, ожидалось %d", song, got, want)
+		}
+	}
+
+	// Ключ не зависит от регистра и лишних пробелов.
+	if data, err := cache.Lookup(context.Background(), " queen ", "INNUENDO"); err != nil || data.Text != "While the sun hangs in the sky" {
+		t.Fatalf("Lookup() без учёта регистра = %+v, %v", data, err)
+	}
+	if got := inner.callsFor("INNUENDO"); got != 0 {
+		t.Errorf("запрос без учёта регистра дошёл до источника %d раз", got)
+	}
+}
+
+func TestCacheExpiresEntries(t *testing.T) {
+	inner := newCountingProvider(queenCatalog())
+	cache := NewCachedProvider(inner, CacheConfig{TTL: time.Hour, NegativeTTL: time.Minute, Size: 10}, nil)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	lookupAll(t, cache, "Innuendo", "Unknown")
+	now = now.Add(time.Minute - time.Second)
+	lookupAll(t, cache, "Innuendo", "Unknown")
+	if inner.callsFor("Innuendo") != 1 || inner.callsFor("Unknown") != 1 {
+		t.Fatalf("до истечения срока обращений: %v", inner.calls)
+	}
+
+	// Ответ «не найдено» живёт NegativeTTL, найденные данные — TTL.
+	now = now.Add(time.Second)
+	lookupAll(t, cache, "Innuendo", "Unknown")
+	if inner.callsFor("Innuendo") != 1 || inner.callsFor("Unknown") != 2 {
+		t.Fatalf("после NegativeTTL обращений: %v", inner.calls)
+	}
+	now = now.Add(time.Hour)
+	lookupAll(t, cache, "Innuendo")
+	if got := inner.callsFor("Innuendo"); got != 2 {
+		t.Errorf("после TTL обращений за Innuendo: %d, ожидалось 2", got)
+	}
+}
+
+func TestCacheNegativeResults(t *testing.T) {
+	tests := []struct {
+		name        string
+		negativeTTL time.Duration
+		err         error
+		want        int
+	}{
+		{"ответ «не найдено» кэшируется", time.Hour, nil, 1},
+		{"нулевой NegativeTTL отключает отрицательное кэширование", 0, nil, 3},
+		{"временные ошибки не кэшируются", time.Hour, errors.New("источник недоступен"), 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := newCountingProvider(queenCatalog())
+			inner.err = tt.err
+			cache := NewCachedProvider(inner, CacheConfig{TTL: time.Hour, NegativeTTL: tt.negativeTTL, Size: 10}, nil)
+
+			for i := 0; i < 3; i++ {
+				_, err := cache.Lookup(context.Background(), "Queen", "Unknown")
+				if tt.err == nil && !errors.Is(err, ErrNotFound) {
+					t.Fatalf("Lookup() = %v, ожидалась ErrNotFound", err)
+				}
+				if tt.err != nil && err == nil {
+					t.Fatal("Lookup() без ошибки")
+				}
+			}
+			if got := inner.callsFor("Unknown"); got != tt.want {
+				t.Errorf("обращений к источнику: %d, ожидалось %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPersistentCache(t *testing.T) {
+	store := db.NewMemoryStorage().MetadataCache
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	first := NewCachedProvider(newCountingProvider(queenCatalog()), CacheConfig{TTL: time.Hour, NegativeTTL: time.Minute, Size: 10}, store)
+	first.now = clock
+	lookupAll(t, first, "Innuendo", "Unknown")
+
+	// Новый кэш, например после перезапуска, берёт записи из хранилища, а не из источника.
+	inner := newCountingProvider(queenCatalog())
+	second := NewCachedProvider(inner, CacheConfig{TTL: time.Hour, NegativeTTL: time.Minute, Size: 10}, store)
+	second.now = clock
+	data, err := second.Lookup(context.Background(), "Queen", "Innuendo")
+	if err != nil || data.Text != "While the sun hangs in the sky" {
+		t.Fatalf("Lookup() из постоянного кэша = %+v, %v", data, err)
+	}
+	if _, err := second.Lookup(context.Background(), "Queen", "Unknown"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Lookup() отрицательного ответа из постоянного кэша = %v", err)
+	}
+	if inner.callsFor("Innuendo") != 0 || inner.callsFor("Unknown") != 0 {
+		t.Fatalf("обращения к источнику при записях в постоянном кэше: %v", inner.calls)
+	}
+
+	// Просроченные записи не используются и удаляются очисткой.
+	now = now.Add(2 * time.Minute)
+	third := NewCachedProvider(inner, CacheConfig{TTL: time.Hour, NegativeTTL: time.Minute, Size: 10}, store)
+	third.now = clock
+	lookupAll(t, third, "Unknown")
+	if got := inner.callsFor("Unknown"); got != 1 {
+		t.Errorf("обращений за просроченной записью: %d, ожидалось 1", got)
+	}
+	now = now.Add(2 * time.Hour)
+	third.PurgeExpired()
+	for _, key := range []string{"api:" + normalizeKey("Queen", "Innuendo"), "api:" + normalizeKey("Queen", "Unknown")} {
+		if entry, err := store.Get(key); !errors.Is(err, db.ErrNotFound) {
+			t.Errorf("после очистки осталась запись %s: %+v, %v", key, entry, err)
+		}
+	}
+}
+
+func TestCacheStartPurgesPeriodically(t *testing.T) {
+	store := db.NewMemoryStorage().MetadataCache
+	cache := NewCachedProvider(newCountingProvider(queenCatalog()), CacheConfig{TTL: time.Hour, Size: 10, CleanupInterval: time.Millisecond}, store)
+	var mu sync.Mutex
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	lookupAll(t, cache, "Innuendo")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cache.Start(ctx)
+
+	key := "api:" + normalizeKey("Queen", "Innuendo")
+	time.Sleep(10 * time.Millisecond)
+	if _, err := store.Get(key); err != nil {
+		t.Fatalf("действующая запись удалена: %v", err)
+	}
+	mu.Lock()
+	now = now.Add(2 * time.Hour)
+	mu.Unlock()
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := store.Get(key); errors.Is(err, db.ErrNotFound) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("просроченная запись не удалена периодической очисткой")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
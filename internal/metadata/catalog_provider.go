@@ -0,0 +1,105 @@
+package metadata
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// catalogEntry — запись файла каталога.
+type catalogEntry struct {
+	Group       string `json:"group"`
+	Song        string `json:"song"`
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// CatalogProvider отдаёт метаданные из локального файла каталога.
+//
+// Поддерживаются JSON-массив объектов с полями group, song, releaseDate, text, link
+// и CSV-файл с заголовком из тех же колонок (порядок колонок произвольный).
+// Формат определяется по расширению файла.
+type CatalogProvider struct {
+	*StaticProvider
+}
+
+// LoadCatalogProvider читает каталог из файла path.
+func LoadCatalogProvider(path string) (*CatalogProvider, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []catalogEntry
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.NewDecoder(file).Decode(&entries)
+	case ".csv":
+		entries, err = readCSVCatalog(file)
+	default:
+		return nil, fmt.Errorf("неподдерживаемый формат каталога: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("чтение каталога %s: %w", path, err)
+	}
+
+	provider := &CatalogProvider{StaticProvider: NewStaticProvider("catalog")}
+	for _, entry := range entries {
+		provider.Add(entry.Group, entry.Song, SongData{
+			ReleaseDate: entry.ReleaseDate,
+			Text:        entry.Text,
+			Link:        entry.Link,
+		})
+	}
+	return provider, nil
+}
+
+func readCSVCatalog(r io.Reader) ([]catalogEntry, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	if _, ok := columns["group"]; !ok {
+		return nil, errors.New("в заголовке CSV нет колонки group")
+	}
+	if _, ok := columns["song"]; !ok {
+		return nil, errors.New("в заголовке CSV нет колонки song")
+	}
+
+	column := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	var entries []catalogEntry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, catalogEntry{
+			Group:       column(record, "group"),
+			Song:        column(record, "song"),
+			ReleaseDate: column(record, "releaseDate"),
+			Text:        column(record, "text"),
+			Link:        column(record, "link"),
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MergePolicy задаёт для каждого поля порядок источников по имени: значение берётся
+// из первого источника в списке, вернувшего непустое поле. Источники, не указанные
+// для поля, проверяются после перечисленных в порядке цепочки.
+type MergePolicy map[Field][]string
+
+// Chain опрашивает источники в порядке приоритета и объединяет их ответы по MergePolicy.
+// Chain сам реализует MetadataProvider.
+type Chain struct {
+	providers []MetadataProvider
+	policy    MergePolicy
+}
+
+// NewChain создаёт цепочку источников. Порядок providers задаёт приоритет по умолчанию.
+func NewChain(policy MergePolicy, providers ...MetadataProvider) *Chain {
+	return &Chain{providers: providers, policy: policy}
+}
+
+func (c *Chain) Name() string {
+	return "chain"
+}
+
+// IncompleteError возвращается, если источник, который для какого-то поля важнее
+// источника найденного значения, был недоступен: объединённые данные Data могут быть
+// неполными, и запрос стоит повторить. Err — последняя ошибка недоступного источника.
+type IncompleteError struct {
+	Data *SongData
+	Err  error
+}
+
+func (e *IncompleteError) Error() string {
+	return "данные источников метаданных неполные: " + e.Err.Error()
+}
+
+func (e *IncompleteError) Unwrap() error {
+	return e.Err
+}
+
+// Lookup опрашивает все источники и объединяет найденные данные.
+// Возвращает ErrNotFound, если песню не знает ни один источник, и ошибку источника,
+// если данных нет, а хотя бы один источник был недоступен: в этом случае запрос стоит повторить.
+// Если данные есть, но для какого-то поля недоступен источник, стоящий в его порядке раньше
+// выбранного (или поле не нашлось вовсе), возвращается *IncompleteError с этими данными.
+func (c *Chain) Lookup(ctx context.Context, group, song string) (*SongData, error) {
+	results := make(map[string]*SongData, len(c.providers))
+	unavailable := make(map[string]bool)
+	var lastErr error
+	for _, provider := range c.providers {
+		data, err := provider.Lookup(ctx, group, song)
+		switch {
+		case err == nil:
+			results[provider.Name()] = data
+		case errors.Is(err, ErrNotFound):
+			logrus.Debugf("Источник %s не знает песню %s - %s", provider.Name(), group, song)
+		default:
+			logrus.Warnf("Источник %s недоступен: %v", provider.Name(), err)
+			unavailable[provider.Name()] = true
+			lastErr = err
+		}
+	}
+
+	if len(results) == 0 {
+		if lastErr != nil {
+			return nil, lastErr
+		}
+		return nil, ErrNotFound
+	}
+
+	merged := &SongData{}
+	incomplete := false
+	for _, field := range Fields {
+		for _, name := range c.order(field) {
+			if unavailable[name] {
+				incomplete = true
+				continue
+			}
+			data, ok := results[name]
+			if !ok {
+				continue
+			}
+			if value := data.get(field); value != "" {
+				merged.set(field, value)
+				logrus.Debugf("Поле %s взято из источника %s", field, name)
+				break
+			}
+		}
+	}
+	if incomplete {
+		return nil, &IncompleteError{Data: merged, Err: lastErr}
+	}
+	return merged, nil
+}
+
+// order возвращает имена источников в порядке проверки для поля.
+func (c *Chain) order(field Field) []string {
+	seen := make(map[string]bool, len(c.providers))
+	var names []string
+	for _, name := range c.policy[field] {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, provider := range c.providers {
+		if !seen[provider.Name()] {
+			seen[provider.Name()] = true
+			names = append(names, provider.Name())
+		}
+	}
+	return names
+}
@@ -0,0 +1,118 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// unavailableProvider — источник, который всегда недоступен.
+type unavailableProvider struct {
+	name string
+}
+
+func (p unavailableProvider) Name() string { return p.name }
+
+func (p unavailableProvider) Lookup(context.Context, string, string) (*SongData, error) {
+	return nil, errors.New("источник " + p.name + " недоступен")
+}
+
+func TestChainIncompleteWhenPreferredProviderUnavailable(t *testing.T) {
+	catalog := NewStaticProvider("catalog").Add("Queen", "Innuendo", SongData{Text: "While the sun hangs in the sky", Link: "https://example.com/catalog"})
+	api := unavailableProvider{name: "api"}
+
+	tests := []struct {
+		name       string
+		policy     MergePolicy
+		providers  []MetadataProvider
+		incomplete bool
+	}{
+		{"недоступный источник важнее найденного", nil, []MetadataProvider{api, catalog}, true},
+		{"недоступный источник после найденного, но дата не найдена", nil, []MetadataProvider{catalog, api}, true},
+		{"недоступный источник важнее только по политике", MergePolicy{FieldLink: {"api"}}, []MetadataProvider{catalog, api}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := NewChain(tt.policy, tt.providers...).Lookup(context.Background(), "Queen", "Innuendo")
+			var incomplete *IncompleteError
+			if !errors.As(err, &incomplete) {
+				t.Fatalf("Lookup() = %+v, %v, ожидалась IncompleteError", data, err)
+			}
+			if incomplete.Data.Text != "While the sun hangs in the sky" || incomplete.Data.Link != "https://example.com/catalog" {
+				t.Errorf("неполные данные = %+v", incomplete.Data)
+			}
+		})
+	}
+
+	// Если все поля нашлись раньше недоступного источника, данные полные.
+	full := NewStaticProvider("catalog").Add("Queen", "Innuendo", SongData{Text: "t", Link: "l", ReleaseDate: "1991-01-14"})
+	data, err := NewChain(nil, full, api).Lookup(context.Background(), "Queen", "Innuendo")
+	if err != nil || data.ReleaseDate != "1991-01-14" {
+		t.Fatalf("Lookup() = %+v, %v", data, err)
+	}
+
+	// Если данных нет вовсе, возвращается ошибка недоступного источника.
+	if _, err := NewChain(nil, api, NewStaticProvider("catalog")).Lookup(context.Background(), "Queen", "Innuendo"); err == nil || errors.Is(err, ErrNotFound) || errors.As(err, new(*IncompleteError)) {
+		t.Fatalf("Lookup() без данных: %v", err)
+	}
+}
+
+func TestChainOrder(t *testing.T) {
+	chain := NewChain(MergePolicy{
+		FieldText: {"catalog", "lastfm", "catalog"},
+		FieldLink: {"api"},
+	}, NewStaticProvider("api"), NewStaticProvider("catalog"), NewStaticProvider("lastfm"))
+
+	tests := []struct {
+		field Field
+		want  []string
+	}{
+		{FieldText, []string{"catalog", "lastfm", "api"}},
+		{FieldLink, []string{"api", "catalog", "lastfm"}},
+		{FieldReleaseDate, []string{"api", "catalog", "lastfm"}},
+	}
+	for _, tt := range tests {
+		if got := chain.order(tt.field); !slices.Equal(got, tt.want) {
+			t.Errorf("order(%s) = %v, ожидалось %v", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestChainMergePolicy(t *testing.T) {
+	api := NewStaticProvider("api").Add("Queen", "Innuendo", SongData{Text: "api text", Link: "https://example.com/api"})
+	catalog := NewStaticProvider("catalog").Add("Queen", "Innuendo", SongData{Text: "catalog text", ReleaseDate: "1991-01-14"})
+
+	tests := []struct {
+		name      string
+		policy    MergePolicy
+		providers []MetadataProvider
+		want      SongData
+	}{
+		{"порядок цепочки", nil, []MetadataProvider{api, catalog},
+			SongData{Text: "api text", Link: "https://example.com/api", ReleaseDate: "1991-01-14"}},
+		{"обратный порядок цепочки", nil, []MetadataProvider{catalog, api},
+			SongData{Text: "catalog text", Link: "https://example.com/api", ReleaseDate: "1991-01-14"}},
+		{"политика для текста", MergePolicy{FieldText: {"catalog"}}, []MetadataProvider{api, catalog},
+			SongData{Text: "catalog text", Link: "https://example.com/api", ReleaseDate: "1991-01-14"}},
+		{"пустое поле берётся из следующего источника", MergePolicy{FieldLink: {"catalog"}}, []MetadataProvider{api, catalog},
+			SongData{Text: "api text", Link: "https://example.com/api", ReleaseDate: "1991-01-14"}},
+		{"источник не знает песню", MergePolicy{FieldText: {"lastfm"}}, []MetadataProvider{NewStaticProvider("lastfm"), api},
+			SongData{Text: "api text", Link: "https://example.com/api"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := NewChain(tt.policy, tt.providers...).Lookup(context.Background(), "Queen", "Innuendo")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *data != tt.want {
+				t.Errorf("Lookup() = %+v, ожидалось %+v", *data, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewChain(nil, api, catalog).Lookup(context.Background(), "Queen", "Bijou"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Lookup() неизвестной песни = %v, ожидалась ErrNotFound", err)
+	}
+}
@@ -0,0 +1,87 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"music_storage/internal/db"
+	"music_storage/internal/external"
+	"os"
+	"slices"
+	"strings"
+)
+
+// FromEnv собирает цепочку источников из переменных окружения.
+//
+// METADATA_PROVIDERS — имена источников через запятую в порядке приоритета
+// (api, catalog; по умолчанию api). Для источника catalog путь к файлу задаётся
+// в METADATA_CATALOG_PATH. Политика объединения задаётся переменными
+// METADATA_MERGE_TEXT, METADATA_MERGE_LINK и METADATA_MERGE_RELEASE_DATE —
+// списками имён источников через запятую; в них допустимы только источники из
+// METADATA_PROVIDERS.
+//
+// Ответы внешнего API кэшируются с параметрами из CacheConfigFromEnv; если включён
+// постоянный кэш, записи сохраняются в cacheStorage, а просроченные периодически удаляются
+// до отмены ctx.
+func FromEnv(ctx context.Context, client *external.Client, cacheStorage db.MetadataCacheRepository) (*Chain, error) {
+	names := splitList(os.Getenv("METADATA_PROVIDERS"))
+	if len(names) == 0 {
+		names = []string{"api"}
+	}
+
+	policy := MergePolicy{}
+	for field, key := range mergeEnv {
+		for _, name := range splitList(os.Getenv(key)) {
+			if !slices.Contains(names, name) {
+				return nil, fmt.Errorf("неизвестный источник метаданных в %s: %s", key, name)
+			}
+			policy[field] = append(policy[field], name)
+		}
+	}
+
+	var providers []MetadataProvider
+	for _, name := range names {
+		switch name {
+		case "api":
+			cacheCfg := CacheConfigFromEnv()
+			var persistent db.MetadataCacheRepository
+			if cacheCfg.Persistent {
+				persistent = cacheStorage
+			}
+			cached := NewCachedProvider(NewInfoAPIProvider(client), cacheCfg, persistent)
+			cached.Start(ctx)
+			providers = append(providers, cached)
+		case "catalog":
+			path := os.Getenv("METADATA_CATALOG_PATH")
+			if path == "" {
+				return nil, errors.New("для источника catalog не задан METADATA_CATALOG_PATH")
+			}
+			catalog, err := LoadCatalogProvider(path)
+			if err != nil {
+				return nil, err
+			}
+			providers = append(providers, catalog)
+		default:
+			return nil, fmt.Errorf("неизвестный источник метаданных: %s", name)
+		}
+	}
+
+	return NewChain(policy, providers...), nil
+}
+
+// mergeEnv сопоставляет полям переменные окружения с их порядком источников.
+var mergeEnv = map[Field]string{
+	FieldText:        "METADATA_MERGE_TEXT",
+	FieldLink:        "METADATA_MERGE_LINK",
+	FieldReleaseDate: "METADATA_MERGE_RELEASE_DATE",
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
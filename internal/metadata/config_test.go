@@ -0,0 +1,29 @@
+package metadata
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFromEnvMergePolicy(t *testing.T) {
+	t.Setenv("METADATA_PROVIDERS", "api")
+	t.Setenv("METADATA_MERGE_TEXT", "api")
+	chain, err := FromEnv(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := chain.policy[FieldText]; len(got) != 1 || got[0] != "api" {
+		t.Errorf("политика для текста = %v", got)
+	}
+
+	for _, key := range []string{"METADATA_MERGE_TEXT", "METADATA_MERGE_LINK", "METADATA_MERGE_RELEASE_DATE"} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, "api, catalog")
+			_, err := FromEnv(context.Background(), nil, nil)
+			if err == nil || !strings.Contains(err.Error(), key) || !strings.Contains(err.Error(), "catalog") {
+				t.Fatalf("FromEnv() = %v, ожидалась ошибка о неизвестном источнике", err)
+			}
+		})
+	}
+}
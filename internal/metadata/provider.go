@@ -0,0 +1,72 @@
+// Package metadata получает дополнительные данные о песнях (текст, ссылку, дату выпуска)
+// из нескольких источников и объединяет их по заданной политике.
+package metadata
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrNotFound означает, что источник не знает запрошенную песню.
+var ErrNotFound = errors.New("песня не найдена в источнике метаданных")
+
+// SongData — метаданные песни. Пустое поле означает, что источник его не знает.
+type SongData struct {
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// Field — поле метаданных, для которого задаётся политика объединения.
+type Field string
+
+const (
+	FieldText        Field = "text"
+	FieldLink        Field = "link"
+	FieldReleaseDate Field = "releaseDate"
+)
+
+// Fields перечисляет все поля метаданных.
+var Fields = []Field{FieldText, FieldLink, FieldReleaseDate}
+
+func (d *SongData) get(field Field) string {
+	switch field {
+	case FieldText:
+		return d.Text
+	case FieldLink:
+		return d.Link
+	case FieldReleaseDate:
+		return d.ReleaseDate
+	}
+	return ""
+}
+
+func (d *SongData) set(field Field, value string) {
+	switch field {
+	case FieldText:
+		d.Text = value
+	case FieldLink:
+		d.Link = value
+	case FieldReleaseDate:
+		d.ReleaseDate = value
+	}
+}
+
+// MetadataProvider — источник метаданных песен.
+type MetadataProvider interface {
+	// Name возвращает имя источника, используемое в политике объединения и логах.
+	Name() string
+	// Lookup возвращает метаданные песни или ErrNotFound, если источник её не знает.
+	// Остальные ошибки считаются временными.
+	Lookup(ctx context.Context, group, song string) (*SongData, error)
+}
+
+// normalizeKey приводит пару группа/песня к ключу поиска без учёта регистра и лишних пробелов.
+func normalizeKey(group, song string) string {
+	return normalizeName(group) + "\x00" + normalizeName(song)
+}
+
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
@@ -0,0 +1,35 @@
+package metadata
+
+import (
+	"context"
+)
+
+// StaticProvider возвращает заранее заданные метаданные. Используется в тестах
+// и для фиксированных данных, которые не должны запрашиваться во внешних источниках.
+type StaticProvider struct {
+	name    string
+	entries map[string]SongData
+}
+
+// NewStaticProvider создаёт пустой статический источник с именем name.
+func NewStaticProvider(name string) *StaticProvider {
+	return &StaticProvider{name: name, entries: make(map[string]SongData)}
+}
+
+// Add добавляет метаданные песни. Поиск не учитывает регистр и лишние пробелы.
+func (p *StaticProvider) Add(group, song string, data SongData) *StaticProvider {
+	p.entries[normalizeKey(group, song)] = data
+	return p
+}
+
+func (p *StaticProvider) Name() string {
+	return p.name
+}
+
+func (p *StaticProvider) Lookup(_ context.Context, group, song string) (*SongData, error) {
+	data, ok := p.entries[normalizeKey(group, song)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &data, nil
+}
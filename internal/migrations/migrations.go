@@ -0,0 +1,233 @@
+// Package migrations применяет версионированные SQL-миграции, встроенные в бинарный файл.
+//
+// Миграции лежат в каталоге, соответствующем драйверу базы данных (postgres, sqlite),
+// и называются по шаблону NNNN_описание.up.sql / NNNN_описание.down.sql.
+// Применённые версии записываются в таблицу schema_migrations.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// Migration описывает одну версию схемы базы данных.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+	// UpFunc — шаг миграции на Go (см. goMigrations), выполняется после Up.
+	UpFunc func(tx *gorm.DB) error
+}
+
+// Status описывает состояние миграции в конкретной базе данных.
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// schemaMigration — запись таблицы schema_migrations.
+type schemaMigration struct {
+	Version   int    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"not null"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator применяет и откатывает миграции для одного подключения.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New загружает миграции для диалекта подключения и создаёт таблицу schema_migrations.
+func New(conn *gorm.DB) (*Migrator, error) {
+	migrations, err := load(conn.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, fmt.Errorf("создание таблицы schema_migrations: %w", err)
+	}
+	return &Migrator{db: conn, migrations: migrations}, nil
+}
+
+// load читает миграции из каталога диалекта и сортирует их по версии.
+func load(dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("нет миграций для драйвера %s: %w", dialect, err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		versionStr, title, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("некорректное имя файла миграции: %s", name)
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("некорректная версия миграции %s: %w", name, err)
+		}
+
+		content, err := fs.ReadFile(files, path.Join(dialect, name))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: title}
+			byVersion[version] = migration
+		}
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	for version, up := range goMigrations {
+		migration, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("шаг на Go для несуществующей миграции %04d", version)
+		}
+		migration.UpFunc = up
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("у миграции %04d_%s нет up-файла", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// applied возвращает применённые версии с датой применения.
+func (m *Migrator) applied() (map[int]time.Time, error) {
+	var rows []schemaMigration
+	if err := m.db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[int]time.Time, len(rows))
+	for _, row := range rows {
+		result[row.Version] = row.AppliedAt
+	}
+	return result, nil
+}
+
+// Up применяет все неприменённые миграции по возрастанию версии и возвращает их количество.
+// Каждая миграция выполняется в отдельной транзакции.
+func (m *Migrator) Up() (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		logrus.Infof("Применение миграции %04d_%s", migration.Version, migration.Name)
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+			if migration.UpFunc != nil {
+				if err := migration.UpFunc(tx); err != nil {
+					return err
+				}
+			}
+			return tx.Create(&schemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("миграция %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Down откатывает последнюю применённую миграцию. Возвращает false, если откатывать нечего.
+func (m *Migrator) Down() (bool, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return false, err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if migration.Down == "" {
+			return false, fmt.Errorf("у миграции %04d_%s нет down-файла", migration.Version, migration.Name)
+		}
+		logrus.Infof("Откат миграции %04d_%s", migration.Version, migration.Name)
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return false, fmt.Errorf("откат %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// Status возвращает состояние всех известных миграций.
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
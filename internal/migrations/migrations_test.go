@@ -0,0 +1,128 @@
+package migrations
+
+import (
+	"music_storage/internal/db"
+	"slices"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// openSQLite подключается к новой базе SQLite во временном каталоге теста.
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	t.Setenv("DB_DRIVER", db.DriverSQLite)
+	t.Setenv("DB_PATH", t.TempDir()+"/test.db")
+	return db.Connect()
+}
+
+// migrateTo применяет миграции до версии version включительно.
+func migrateTo(t *testing.T, m *Migrator, version int) {
+	t.Helper()
+	all := m.migrations
+	defer func() { m.migrations = all }()
+	for i, migration := range all {
+		if migration.Version > version {
+			m.migrations = all[:i]
+			break
+		}
+	}
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("Up() до версии %d: %v", version, err)
+	}
+}
+
+var groupNames = []string{"Кино", "  ДДТ\t", "The\t\tBeatles", "Ÿ  Äpfel   ", "Queen"}
+
+func TestNormalizeGroupNamesOnMigrate(t *testing.T) {
+	conn := openSQLite(t)
+	m, err := New(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrateTo(t, m, 4)
+	for _, name := range groupNames {
+		if err := conn.Exec("INSERT INTO `groups` (name) VALUES (?)", name).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNormalized(t, conn)
+}
+
+func TestNormalizeGroupNamesMergesDuplicates(t *testing.T) {
+	conn := openSQLite(t)
+	m, err := New(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrateTo(t, m, 4)
+	for _, name := range append(groupNames, " КИНО", "queen") {
+		if err := conn.Exec("INSERT INTO `groups` (name) VALUES (?)", name).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Песни дубликатов "КИНО" (6) и "queen" (7) переходят к группам 1 и 5.
+	for _, groupID := range []int{1, 6, 7} {
+		if err := conn.Exec("INSERT INTO songs (group_id, song) VALUES (?, ?)", groupID, "song").Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNormalized(t, conn)
+	var count int64
+	if err := conn.Table("groups").Count(&count).Error; err != nil || count != int64(len(groupNames)) {
+		t.Fatalf("групп после миграции: %d, %v, ожидалось %d", count, err, len(groupNames))
+	}
+	var groupIDs []int
+	if err := conn.Table("songs").Order("id").Pluck("group_id", &groupIDs).Error; err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1, 1, 5}; !slices.Equal(groupIDs, want) {
+		t.Errorf("группы песен: %v, ожидалось %v", groupIDs, want)
+	}
+	if err := conn.Exec("INSERT INTO `groups` (name, normalized_name) VALUES ('Кино', 'кино')").Error; err == nil {
+		t.Error("normalized_name групп не уникален")
+	}
+}
+
+// assertNormalized проверяет, что normalized_name групп совпадает с db.NormalizeName и
+// группы находятся по любому написанию названия, а не создаются заново.
+func assertNormalized(t *testing.T, conn *gorm.DB) {
+	t.Helper()
+	var groups []groupName
+	if err := conn.Table("groups").Select("id, name, normalized_name").Find(&groups).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, group := range groups {
+		if want := db.NormalizeName(group.Name); group.NormalizedName != want {
+			t.Errorf("normalized_name группы %q = %q, ожидалось %q", group.Name, group.NormalizedName, want)
+		}
+	}
+
+	storage := db.NewGormStorage(conn)
+	for _, tc := range []struct {
+		name string
+		id   int
+	}{
+		{"кино", 1},
+		{"КИНО", 1},
+		{"ддт", 2},
+		{"the beatles", 3},
+		{"ÿ äpfel", 4},
+	} {
+		group, err := storage.Groups.FindOrCreate(tc.name)
+		if err != nil {
+			t.Fatalf("FindOrCreate(%q): %v", tc.name, err)
+		}
+		if group.ID != tc.id {
+			t.Errorf("FindOrCreate(%q) вернул группу %d, ожидалась %d", tc.name, group.ID, tc.id)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS groups (
+    id   BIGSERIAL PRIMARY KEY,
+    name TEXT
+);
+
+CREATE TABLE IF NOT EXISTS songs (
+    id           BIGSERIAL PRIMARY KEY,
+    group_id     BIGINT REFERENCES groups (id),
+    song         TEXT,
+    release_date DATE,
+    text         TEXT,
+    link         TEXT
+);
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_songs_group_id ON songs (group_id);
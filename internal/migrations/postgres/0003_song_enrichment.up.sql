@@ -0,0 +1,7 @@
+ALTER TABLE songs
+    ADD COLUMN enrichment_status   TEXT    NOT NULL DEFAULT 'done',
+    ADD COLUMN enrichment_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN enrichment_error    TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN enrichment_next_at  TIMESTAMPTZ;
+
+CREATE INDEX idx_songs_enrichment_pending ON songs (enrichment_next_at) WHERE enrichment_status = 'pending';
@@ -0,0 +1,10 @@
+CREATE TABLE metadata_cache (
+    key          TEXT PRIMARY KEY,
+    found        BOOLEAN     NOT NULL,
+    release_date TEXT        NOT NULL DEFAULT '',
+    text         TEXT        NOT NULL DEFAULT '',
+    link         TEXT        NOT NULL DEFAULT '',
+    expires_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_metadata_cache_expires_at ON metadata_cache (expires_at);
@@ -0,0 +1,14 @@
+ALTER TABLE groups ADD COLUMN normalized_name TEXT NOT NULL DEFAULT '';
+
+-- normalized_name существующих групп заполняется шагом миграции на Go (см. goMigrations),
+-- он же объединяет группы с одинаковыми названиями и создаёт уникальный индекс
+-- idx_groups_normalized_name.
+
+CREATE TABLE group_aliases (
+    id              BIGSERIAL PRIMARY KEY,
+    group_id        BIGINT NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    name            TEXT   NOT NULL,
+    normalized_name TEXT   NOT NULL UNIQUE
+);
+
+CREATE INDEX idx_group_aliases_group_id ON group_aliases (group_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_songs_album_track;
+
+ALTER TABLE songs
+    DROP COLUMN track_number,
+    DROP COLUMN disc_number,
+    DROP COLUMN album_id;
+
+DROP TABLE IF EXISTS albums;
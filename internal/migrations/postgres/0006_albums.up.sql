@@ -0,0 +1,18 @@
+CREATE TABLE albums (
+    id               BIGSERIAL PRIMARY KEY,
+    group_id         BIGINT NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    title            TEXT   NOT NULL,
+    normalized_title TEXT   NOT NULL,
+    release_date     DATE,
+    cover_link       TEXT   NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_albums_group_id ON albums (group_id);
+CREATE INDEX idx_albums_normalized_title ON albums (normalized_title);
+
+ALTER TABLE songs
+    ADD COLUMN album_id     BIGINT  REFERENCES albums (id) ON DELETE SET NULL,
+    ADD COLUMN disc_number  INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN track_number INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_songs_album_track ON songs (album_id, disc_number, track_number);
@@ -0,0 +1,17 @@
+CREATE TABLE playlists (
+    id          BIGSERIAL PRIMARY KEY,
+    name        TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE playlist_items (
+    id          BIGSERIAL PRIMARY KEY,
+    playlist_id BIGINT NOT NULL REFERENCES playlists (id) ON DELETE CASCADE,
+    song_id     BIGINT NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    position    INTEGER NOT NULL
+);
+
+CREATE INDEX idx_playlist_items_playlist_position ON playlist_items (playlist_id, position);
+CREATE INDEX idx_playlist_items_song_id ON playlist_items (song_id);
@@ -0,0 +1,11 @@
+-- Тексты песен бывают на русском и английском, поэтому вектор строится с обеими
+-- конфигурациями: русская не меняет латиницу, английская — кириллицу.
+ALTER TABLE songs
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', coalesce(song, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(song, '')), 'A') ||
+        setweight(to_tsvector('russian', coalesce(text, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(text, '')), 'B')
+    ) STORED;
+
+CREATE INDEX idx_songs_search_vector ON songs USING GIN (search_vector);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_groups_normalized_name_trgm;
+DROP INDEX IF EXISTS idx_songs_song_trgm;
+
+DROP EXTENSION IF EXISTS pg_trgm;
@@ -0,0 +1,6 @@
+-- Для нечёткого сравнения названий песен и групп нужно расширение pg_trgm
+-- (создание расширения требует соответствующих прав).
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_songs_song_trgm ON songs USING GIN (song gin_trgm_ops);
+CREATE INDEX idx_groups_normalized_name_trgm ON groups USING GIN (normalized_name gin_trgm_ops);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS lyrics_words;
+DROP TABLE IF EXISTS lyrics_lines;
+
+ALTER TABLE songs DROP COLUMN duration;
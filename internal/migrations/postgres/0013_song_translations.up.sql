@@ -0,0 +1,10 @@
+ALTER TABLE songs ADD COLUMN language TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE song_translations (
+    id       BIGSERIAL PRIMARY KEY,
+    song_id  BIGINT NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    language TEXT NOT NULL,
+    text     TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_song_translations_song_language ON song_translations (song_id, language);
@@ -0,0 +1,11 @@
+CREATE TABLE song_revisions (
+    id         BIGSERIAL PRIMARY KEY,
+    song_id    BIGINT NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    number     INTEGER NOT NULL,
+    author     TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    fields     TEXT NOT NULL,
+    changes    TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_song_revisions_song_number ON song_revisions (song_id, number);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS trashed_playlist_items;
+
+DROP INDEX IF EXISTS idx_songs_deleted_at;
+
+ALTER TABLE songs DROP COLUMN deleted_at;
@@ -0,0 +1,14 @@
+ALTER TABLE songs ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_songs_deleted_at ON songs (deleted_at);
+
+-- Места песен в плейлистах на момент удаления в корзину: при восстановлении песня
+-- возвращается в плейлисты на прежние позиции.
+CREATE TABLE trashed_playlist_items (
+    id          BIGSERIAL PRIMARY KEY,
+    song_id     BIGINT NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    playlist_id BIGINT NOT NULL REFERENCES playlists (id) ON DELETE CASCADE,
+    position    INTEGER NOT NULL
+);
+
+CREATE INDEX idx_trashed_playlist_items_song ON trashed_playlist_items (song_id);
@@ -0,0 +1,10 @@
+CREATE TABLE idempotency_keys (
+    key          TEXT PRIMARY KEY,
+    request      TEXT        NOT NULL,
+    status       INTEGER     NOT NULL,
+    content_type TEXT        NOT NULL DEFAULT '',
+    body         TEXT        NOT NULL DEFAULT '',
+    expires_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
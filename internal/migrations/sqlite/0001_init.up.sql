@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `groups` (
+    id   INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT
+);
+
+CREATE TABLE IF NOT EXISTS songs (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    group_id     INTEGER REFERENCES `groups` (id),
+    song         TEXT,
+    release_date DATE,
+    text         TEXT,
+    link         TEXT
+);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_songs_enrichment_pending;
+
+ALTER TABLE songs DROP COLUMN enrichment_status;
+ALTER TABLE songs DROP COLUMN enrichment_attempts;
+ALTER TABLE songs DROP COLUMN enrichment_error;
+ALTER TABLE songs DROP COLUMN enrichment_next_at;
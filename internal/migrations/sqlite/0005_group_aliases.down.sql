@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS group_aliases;
+
+DROP INDEX IF EXISTS idx_groups_normalized_name;
+
+ALTER TABLE `groups` DROP COLUMN normalized_name;
@@ -0,0 +1,27 @@
+CREATE TABLE genres (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    name            TEXT NOT NULL,
+    normalized_name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE tags (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    name            TEXT NOT NULL,
+    normalized_name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE song_genres (
+    song_id  INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    genre_id INTEGER NOT NULL REFERENCES genres (id) ON DELETE CASCADE,
+    PRIMARY KEY (song_id, genre_id)
+);
+
+CREATE INDEX idx_song_genres_genre_id ON song_genres (genre_id);
+
+CREATE TABLE song_tags (
+    song_id INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    tag_id  INTEGER NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (song_id, tag_id)
+);
+
+CREATE INDEX idx_song_tags_tag_id ON song_tags (tag_id);
@@ -0,0 +1,17 @@
+CREATE TABLE playlists (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    name        TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE playlist_items (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    playlist_id INTEGER NOT NULL REFERENCES playlists (id) ON DELETE CASCADE,
+    song_id     INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    position    INTEGER NOT NULL
+);
+
+CREATE INDEX idx_playlist_items_playlist_position ON playlist_items (playlist_id, position);
+CREATE INDEX idx_playlist_items_song_id ON playlist_items (song_id);
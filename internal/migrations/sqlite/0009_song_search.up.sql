@@ -0,0 +1,2 @@
+-- В SQLite поиск по тексту песен выполняется в приложении, схема не меняется.
+SELECT 1;
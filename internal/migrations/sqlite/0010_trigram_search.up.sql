@@ -0,0 +1,2 @@
+-- В SQLite нечёткое сравнение названий выполняется в приложении, схема не меняется.
+SELECT 1;
@@ -0,0 +1,10 @@
+CREATE TABLE lyrics_sections (
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    song_id   INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    position  INTEGER NOT NULL,
+    type      TEXT NOT NULL,
+    text      TEXT NOT NULL DEFAULT '',
+    repeat_of INTEGER
+);
+
+CREATE UNIQUE INDEX idx_lyrics_sections_song_position ON lyrics_sections (song_id, position);
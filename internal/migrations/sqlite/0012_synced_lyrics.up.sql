@@ -0,0 +1,22 @@
+ALTER TABLE songs ADD COLUMN duration INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE lyrics_lines (
+    id       INTEGER PRIMARY KEY AUTOINCREMENT,
+    song_id  INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    start_ms INTEGER NOT NULL,
+    text     TEXT NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX idx_lyrics_lines_song_position ON lyrics_lines (song_id, position);
+
+CREATE TABLE lyrics_words (
+    id       INTEGER PRIMARY KEY AUTOINCREMENT,
+    song_id  INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    line     INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    start_ms INTEGER NOT NULL,
+    text     TEXT NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX idx_lyrics_words_song_line_position ON lyrics_words (song_id, line, position);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS song_translations;
+
+ALTER TABLE songs DROP COLUMN language;
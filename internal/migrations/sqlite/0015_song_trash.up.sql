@@ -0,0 +1,14 @@
+ALTER TABLE songs ADD COLUMN deleted_at DATETIME;
+
+CREATE INDEX idx_songs_deleted_at ON songs (deleted_at);
+
+-- Места песен в плейлистах на момент удаления в корзину: при восстановлении песня
+-- возвращается в плейлисты на прежние позиции.
+CREATE TABLE trashed_playlist_items (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    song_id     INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    playlist_id INTEGER NOT NULL REFERENCES playlists (id) ON DELETE CASCADE,
+    position    INTEGER NOT NULL
+);
+
+CREATE INDEX idx_trashed_playlist_items_song ON trashed_playlist_items (song_id);
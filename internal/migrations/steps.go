@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"music_storage/internal/db"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// goMigrations — шаги миграций, которые нельзя одинаково выразить в SQL обоих диалектов,
+// по версиям. Шаг выполняется в транзакции миграции после её up-файла.
+var goMigrations = map[int]func(tx *gorm.DB) error{
+	5: normalizeGroupNames,
+}
+
+// groupName — столбцы таблицы groups, нужные для нормализации названий.
+type groupName struct {
+	ID             int
+	Name           string
+	NormalizedName string
+}
+
+// normalizeGroupNames заполняет groups.normalized_name функцией db.NormalizeName. SQL-функции
+// lower и trim в SQLite и PostgreSQL по-разному обрабатывают не-ASCII буквы и пробельные
+// символы, поэтому названия, по которым ищутся группы, вычисляются так же, как в приложении.
+// Группы, названия которых совпали после нормализации, объединяются в группу с меньшим ID,
+// после чего normalized_name становится уникальным.
+func normalizeGroupNames(tx *gorm.DB) error {
+	var groups []groupName
+	if err := tx.Table("groups").Select("id, name, normalized_name").Order("id").Find(&groups).Error; err != nil {
+		return err
+	}
+
+	byName := make(map[string][]int)
+	var names []string
+	for _, group := range groups {
+		normalized := db.NormalizeName(group.Name)
+		if _, ok := byName[normalized]; !ok {
+			names = append(names, normalized)
+		}
+		byName[normalized] = append(byName[normalized], group.ID)
+		if normalized == group.NormalizedName {
+			continue
+		}
+		if err := tx.Table("groups").Where("id = ?", group.ID).Update("normalized_name", normalized).Error; err != nil {
+			return err
+		}
+	}
+
+	// До версии 5 на группы ссылаются только песни.
+	for _, name := range names {
+		ids := byName[name]
+		if len(ids) < 2 {
+			continue
+		}
+		keep, duplicates := ids[0], ids[1:]
+		if err := tx.Table("songs").Where("group_id IN ?", duplicates).Update("group_id", keep).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`DELETE FROM "groups" WHERE id IN ?`, duplicates).Error; err != nil {
+			return err
+		}
+		logrus.Warnf("Группы с ID %s называются одинаково (%q) и объединены в группу %d", joinIDs(duplicates), name, keep)
+	}
+
+	return tx.Exec(`CREATE UNIQUE INDEX idx_groups_normalized_name ON "groups" (normalized_name)`).Error
+}
+
+func joinIDs(ids []int) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.Itoa(id))
+	}
+	return strings.Join(parts, ", ")
+}
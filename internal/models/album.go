@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// Album — альбом группы. Песни альбома ссылаются на него через Song.AlbumID
+// и упорядочиваются по номеру диска и трека.
+type Album struct {
+	ID      int    `gorm:"primaryKey"`
+	GroupID int    `json:"groupID"`
+	Group   Group  `gorm:"foreignKey:GroupID"`
+	Title   string `json:"title"`
+	// NormalizedTitle — название в нижнем регистре без лишних пробелов, по нему работает фильтр album.
+	NormalizedTitle string    `json:"-"`
+	ReleaseDate     time.Time `json:"releaseDate" gorm:"type:date"`
+	CoverLink       string    `json:"coverLink"`
+}
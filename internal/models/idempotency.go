@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// IdempotentResponse — сохранённый ответ на запрос с заголовком Idempotency-Key.
+// Request — метод и путь запроса, для которого использован ключ. Пока запрос выполняется,
+// ключ зарезервирован: ответ сохранён со статусом 0 (см. Pending).
+type IdempotentResponse struct {
+	Key         string `gorm:"primaryKey"`
+	Request     string
+	Status      int
+	ContentType string
+	Body        string
+	ExpiresAt   time.Time
+}
+
+func (IdempotentResponse) TableName() string {
+	return "idempotency_keys"
+}
+
+// Pending сообщает, что запрос с этим ключом ещё выполняется и ответа пока нет.
+func (r IdempotentResponse) Pending() bool {
+	return r.Status == 0
+}
@@ -0,0 +1,53 @@
+package models
+
+// Типы разделов текста песни.
+const (
+	SectionVerse  = "verse"
+	SectionChorus = "chorus"
+	SectionBridge = "bridge"
+	SectionIntro  = "intro"
+	SectionOutro  = "outro"
+)
+
+// LyricsSection — раздел текста песни. Position задаёт порядок и нумеруется с 1 без пропусков.
+// Повтор раздела хранится ссылкой RepeatOf на позицию раздела с тем же текстом, а его Text пуст.
+type LyricsSection struct {
+	ID       int    `gorm:"primaryKey"`
+	SongID   int    `json:"songID"`
+	Position int    `json:"position"`
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	RepeatOf *int   `json:"repeatOf"`
+}
+
+// LyricsLine — строка синхронизированного текста песни (LRC). StartMs — время начала строки
+// в миллисекундах от начала песни; Position нумеруется с 1 в порядке времени.
+type LyricsLine struct {
+	ID       int    `gorm:"primaryKey"`
+	SongID   int    `json:"songID"`
+	Position int    `json:"position"`
+	StartMs  int    `json:"startMs"`
+	Text     string `json:"text"`
+	// Words — слова строки со своим временем начала (расширенный формат LRC).
+	Words []LyricsWord `json:"words" gorm:"-"`
+}
+
+// LyricsWord — слово строки синхронизированного текста. Line — позиция строки; Text включает
+// пробелы после слова, а пустой Text отмечает конец предыдущего слова.
+type LyricsWord struct {
+	ID       int    `gorm:"primaryKey"`
+	SongID   int    `json:"songID"`
+	Line     int    `json:"line"`
+	Position int    `json:"position"`
+	StartMs  int    `json:"startMs"`
+	Text     string `json:"text"`
+}
+
+// SongTranslation — перевод текста песни. Language — код языка в нижнем регистре (en, pt-br);
+// язык оригинала хранится в Song.Language, а сам оригинал — в Song.Text.
+type SongTranslation struct {
+	ID       int    `gorm:"primaryKey"`
+	SongID   int    `json:"songID"`
+	Language string `json:"language"`
+	Text     string `json:"text"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// MetadataCacheEntry — сохранённый ответ источника метаданных.
+// Found = false означает, что источник не знает песню (отрицательное кэширование).
+type MetadataCacheEntry struct {
+	Key         string `gorm:"primaryKey"`
+	Found       bool
+	ReleaseDate string
+	Text        string
+	Link        string
+	ExpiresAt   time.Time
+}
+
+func (MetadataCacheEntry) TableName() string {
+	return "metadata_cache"
+}
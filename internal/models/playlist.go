@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// Playlist — подборка песен, составленная пользователем.
+type Playlist struct {
+	ID          int       `gorm:"primaryKey"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// PlaylistItem — песня в плейлисте. Position задаёт порядок и нумеруется с 1 без пропусков;
+// одна и та же песня может встречаться в плейлисте несколько раз.
+type PlaylistItem struct {
+	ID         int `gorm:"primaryKey"`
+	PlaylistID int `json:"playlistID"`
+	SongID     int `json:"songID"`
+	Position   int `json:"position"`
+}
+
+// TrashedPlaylistItem — место песни в плейлисте на момент её удаления в корзину.
+type TrashedPlaylistItem struct {
+	ID         int `gorm:"primaryKey"`
+	SongID     int `json:"songID"`
+	PlaylistID int `json:"playlistID"`
+	Position   int `json:"position"`
+}
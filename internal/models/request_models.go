@@ -6,9 +6,106 @@ type UpdateSongRequest struct {
 	ReleaseDate *string `json:"releaseDate,omitempty" example:"0001-01-01"`
 	Text        *string `json:"text,omitempty"`
 	Link        *string `json:"link,omitempty"`
+	// AlbumID — ID альбома той же группы; 0 отвязывает песню от альбома.
+	AlbumID     *int `json:"albumId,omitempty"`
+	DiscNumber  *int `json:"discNumber,omitempty"`
+	TrackNumber *int `json:"trackNumber,omitempty"`
+	// Duration — длительность песни в секундах; 0 — неизвестна.
+	Duration *int `json:"duration,omitempty" example:"354"`
+	// Language — код языка оригинального текста, например ru или en; пустая строка — неизвестен.
+	Language *string `json:"language,omitempty" example:"ru"`
 }
 
 type CreateSongRequest struct {
 	Group string `json:"group"`
 	Song  string `json:"song"`
+	// AlbumID — необязательный ID альбома той же группы.
+	AlbumID     *int `json:"albumId,omitempty"`
+	DiscNumber  int  `json:"discNumber,omitempty"`
+	TrackNumber int  `json:"trackNumber,omitempty"`
+}
+
+type UpdateGroupRequest struct {
+	Name string `json:"name"`
+}
+
+type CreateGroupAliasRequest struct {
+	Name string `json:"name"`
+}
+
+type MergeGroupsRequest struct {
+	// SourceIDs — ID групп, которые будут объединены с целевой группой и удалены.
+	SourceIDs []int `json:"sourceIds"`
+}
+
+type CreateAlbumRequest struct {
+	Group       string `json:"group"`
+	Title       string `json:"title"`
+	ReleaseDate string `json:"releaseDate,omitempty" example:"1975-11-21"`
+	CoverLink   string `json:"coverLink,omitempty"`
+}
+
+type UpdateAlbumRequest struct {
+	Title       *string `json:"title,omitempty"`
+	ReleaseDate *string `json:"releaseDate,omitempty" example:"1975-11-21"`
+	CoverLink   *string `json:"coverLink,omitempty"`
+}
+
+type CreateGenreRequest struct {
+	Name string `json:"name"`
+}
+
+type UpdateGenreRequest struct {
+	Name string `json:"name"`
+}
+
+type SetSongGenresRequest struct {
+	// Genres — названия жанров из справочника; пустой список снимает все жанры.
+	Genres []string `json:"genres"`
+}
+
+type SetSongTagsRequest struct {
+	// Tags — названия тегов; новые теги создаются автоматически, пустой список снимает все теги.
+	Tags []string `json:"tags"`
+}
+
+type CreatePlaylistRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type UpdatePlaylistRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+type AddPlaylistSongRequest struct {
+	SongID int `json:"songId"`
+	// Position — позиция вставки (с 1); если не указана, песня добавляется в конец.
+	Position int `json:"position,omitempty"`
+}
+
+type MovePlaylistSongRequest struct {
+	// Position — новая позиция песни (с 1).
+	Position int `json:"position"`
+}
+
+type SetLyricsRequest struct {
+	// Sections — разделы текста по порядку; позиции нумеруются с 1 в порядке списка.
+	Sections []LyricsSectionRequest `json:"sections"`
+}
+
+type LyricsSectionRequest struct {
+	// Type — тип раздела: verse, chorus, bridge, intro или outro.
+	Type string `json:"type" example:"chorus"`
+	// Text — текст раздела без пустых строк; не указывается для повтора.
+	Text string `json:"text,omitempty"`
+	// RepeatOf — позиция более раннего раздела, который повторяется; вместо text.
+	RepeatOf *int `json:"repeatOf,omitempty" example:"2"`
+}
+
+type SetTranslationRequest struct {
+	// Text — текст перевода; куплеты разделяются пустой строкой, их число должно совпадать
+	// с оригиналом.
+	Text string `json:"text"`
 }
@@ -1,12 +1,258 @@
 package models
 
 type SongResponse struct {
+	ID          int      `json:"id"`
+	Song        string   `json:"song"`
+	Group       string   `json:"group"`
+	Link        string   `json:"link"`
+	ReleaseDate string   `json:"releaseDate"`
+	Duration    int      `json:"duration,omitempty"`
+	Text        string   `json:"text"`
+	Language    string   `json:"language,omitempty"`
+	AlbumID     int      `json:"albumId,omitempty"`
+	Album       string   `json:"album,omitempty"`
+	DiscNumber  int      `json:"discNumber,omitempty"`
+	TrackNumber int      `json:"trackNumber,omitempty"`
+	Genres      []string `json:"genres,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	// EnrichmentStatus — статус обогащения данными из внешнего API.
+	EnrichmentStatus string `json:"enrichmentStatus" example:"done"`
+	// Rank — релевантность песни поисковому запросу q (от 0 до 1).
+	Rank float64 `json:"rank,omitempty"`
+	// Snippet — куплет, подходящий под поисковый запрос q, с совпадениями в тегах <b></b>.
+	Snippet string `json:"snippet,omitempty" example:"Is this the <b>real</b> life?"`
+	// Score — сходство названий песни и группы с фильтром при fuzzy=true (от 0 до 1).
+	Score float64 `json:"score,omitempty"`
+	// DeletedAt — время удаления песни в корзину в формате RFC 3339; только для песен в корзине.
+	DeletedAt string `json:"deletedAt,omitempty"`
+	// ETag — версия песни для заголовка If-Match; меняется при каждом изменении песни.
+	ETag string `json:"etag" example:"\"5d41402abc4b2a76b9719d911017c592\""`
+}
+
+// SongPageResponse описывает страницу списка песен при курсорной пагинации.
+type SongPageResponse struct {
+	Items []SongResponse `json:"items"`
+	// Total — количество песен, подходящих под фильтр; только при total=true.
+	Total *int `json:"total,omitempty"`
+	// Next и Prev — курсоры следующей и предыдущей страниц; отсутствуют, если страницы нет.
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// EnrichmentResponse описывает состояние обогащения песни данными из внешнего API.
+type EnrichmentResponse struct {
+	SongID   int    `json:"songId"`
+	Status   string `json:"status" example:"pending"`
+	Attempts int    `json:"attempts"`
+	Error    string `json:"error,omitempty"`
+	// NextAttemptAt — время следующей попытки в формате RFC 3339, если она запланирована.
+	NextAttemptAt string `json:"nextAttemptAt,omitempty"`
+}
+
+// GroupResponse описывает группу в списке групп.
+type GroupResponse struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	SongCount int    `json:"songCount"`
+}
+
+// GroupAliasResponse описывает альтернативное написание названия группы.
+type GroupAliasResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// GroupDetailsResponse описывает группу вместе с её псевдонимами и песнями.
+type GroupDetailsResponse struct {
+	ID      int                  `json:"id"`
+	Name    string               `json:"name"`
+	Aliases []GroupAliasResponse `json:"aliases"`
+	Songs   []SongResponse       `json:"songs"`
+}
+
+// AlbumResponse описывает альбом в списке альбомов.
+type AlbumResponse struct {
 	ID          int    `json:"id"`
-	Song        string `json:"song"`
+	Title       string `json:"title"`
+	GroupID     int    `json:"groupId"`
 	Group       string `json:"group"`
-	Link        string `json:"link"`
 	ReleaseDate string `json:"releaseDate"`
-	Text        string `json:"text"`
+	CoverLink   string `json:"coverLink"`
+	TrackCount  int    `json:"trackCount"`
+}
+
+// AlbumDetailsResponse описывает альбом вместе с треками по порядку.
+type AlbumDetailsResponse struct {
+	ID          int            `json:"id"`
+	Title       string         `json:"title"`
+	GroupID     int            `json:"groupId"`
+	Group       string         `json:"group"`
+	ReleaseDate string         `json:"releaseDate"`
+	CoverLink   string         `json:"coverLink"`
+	Tracks      []SongResponse `json:"tracks"`
+}
+
+// GenreResponse описывает жанр с количеством песен.
+type GenreResponse struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	SongCount int    `json:"songCount"`
+}
+
+// TagResponse описывает тег с количеством песен.
+type TagResponse struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	SongCount int    `json:"songCount"`
+}
+
+// FacetValueResponse описывает значение фасета: жанр или тег и число подходящих песен с ним.
+type FacetValueResponse struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// SongFacetsResponse описывает распределение песен, подходящих под фильтр, по жанрам и тегам.
+type SongFacetsResponse struct {
+	Total  int                  `json:"total"`
+	Genres []FacetValueResponse `json:"genres"`
+	Tags   []FacetValueResponse `json:"tags"`
+}
+
+// PlaylistResponse описывает плейлист в списке плейлистов.
+type PlaylistResponse struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	SongCount   int    `json:"songCount"`
+}
+
+// PlaylistDetailsResponse описывает плейлист вместе с песнями в порядке позиций.
+type PlaylistDetailsResponse struct {
+	ID          int            `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	CreatedAt   string         `json:"createdAt"`
+	UpdatedAt   string         `json:"updatedAt"`
+	Songs       []SongResponse `json:"songs"`
+}
+
+// LyricsResponse описывает текст песни, разбитый на разделы.
+type LyricsResponse struct {
+	SongID   int                     `json:"songId"`
+	Sections []LyricsSectionResponse `json:"sections"`
+}
+
+// LyricsSectionResponse описывает раздел текста песни.
+type LyricsSectionResponse struct {
+	Position int    `json:"position"`
+	Type     string `json:"type" example:"chorus"`
+	// Number — порядковый номер раздела среди разделов того же типа без учёта повторов;
+	// у повтора — номер повторяемого раздела.
+	Number int `json:"number"`
+	// Text — текст раздела; у повтора — текст повторяемого раздела.
+	Text string `json:"text"`
+	// RepeatOf — позиция раздела, который повторяет этот раздел.
+	RepeatOf *int `json:"repeatOf,omitempty"`
+}
+
+// SyncedLyricsResponse описывает синхронизированный текст песни.
+type SyncedLyricsResponse struct {
+	SongID int `json:"songId"`
+	// Duration — длительность песни в секундах, если известна.
+	Duration int                  `json:"duration,omitempty"`
+	Lines    []SyncedLineResponse `json:"lines"`
+}
+
+// SyncedLineResponse описывает строку синхронизированного текста.
+type SyncedLineResponse struct {
+	// Time — время начала строки в формате мм:сс.хх.
+	Time    string `json:"time" example:"01:23.45"`
+	StartMs int    `json:"startMs" example:"83450"`
+	Text    string `json:"text"`
+	// Words — слова строки со своим временем начала, если они размечены; слово с пустым
+	// текстом отмечает конец предыдущего.
+	Words []SyncedWordResponse `json:"words,omitempty"`
+}
+
+// SyncedWordResponse описывает слово строки синхронизированного текста.
+type SyncedWordResponse struct {
+	Time    string `json:"time" example:"01:23.90"`
+	StartMs int    `json:"startMs" example:"83900"`
+	Text    string `json:"text"`
+}
+
+// LyricsVariantsResponse описывает оригинальный текст песни и его переводы.
+type LyricsVariantsResponse struct {
+	SongID   int                     `json:"songId"`
+	Variants []LyricsVariantResponse `json:"variants"`
+}
+
+// LyricsVariantResponse описывает текст песни на одном языке: оригинал или перевод.
+type LyricsVariantResponse struct {
+	// Language — код языка; у оригинала пустой, если язык не указан.
+	Language string `json:"language" example:"en"`
+	// Original — true для оригинального текста, false для перевода.
+	Original bool `json:"original"`
+	// Verses — число куплетов, то есть блоков текста через пустую строку.
+	Verses int `json:"verses"`
+	// Aligned — число куплетов перевода совпадает с оригиналом, поэтому номера куплетов
+	// и разделы перевода соответствуют оригиналу. У оригинала всегда true.
+	Aligned bool   `json:"aligned"`
+	Text    string `json:"text"`
+}
+
+// SongRevisionResponse описывает ревизию песни.
+type SongRevisionResponse struct {
+	Number int    `json:"number"`
+	Author string `json:"author" example:"api"`
+	// CreatedAt — время изменения в формате RFC 3339.
+	CreatedAt string                `json:"createdAt"`
+	Changes   []FieldChangeResponse `json:"changes"`
+	// Fields — значения полей песни в этой ревизии; только в ответе с одной ревизией.
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// FieldChangeResponse описывает изменение поля песни.
+type FieldChangeResponse struct {
+	Field string `json:"field" example:"text"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// SongRevisionDiffResponse описывает различия полей песни между двумя ревизиями.
+type SongRevisionDiffResponse struct {
+	SongID  int                   `json:"songId"`
+	From    int                   `json:"from"`
+	To      int                   `json:"to"`
+	Changes []FieldChangeResponse `json:"changes"`
+}
+
+// BatchImportResponse описывает результат пакетного импорта песен.
+type BatchImportResponse struct {
+	Created int                 `json:"created"`
+	Failed  int                 `json:"failed"`
+	Items   []BatchItemResponse `json:"items"`
+	// Error — ошибка чтения входных данных, после которой импорт прекращён; элементы до неё
+	// обработаны и перечислены в items.
+	Error string `json:"error,omitempty"`
+}
+
+// BatchItemResponse описывает результат импорта одного элемента.
+type BatchItemResponse struct {
+	// Index — номер элемента во входных данных, начиная с 0.
+	Index int    `json:"index"`
+	Group string `json:"group"`
+	Song  string `json:"song"`
+	// ID — ID добавленной песни; отсутствует, если элемент не импортирован.
+	ID int `json:"id,omitempty"`
+	// EnrichmentStatus и EnrichmentError — результат обогащения при enrich=true,
+	// без него — статус pending.
+	EnrichmentStatus string `json:"enrichmentStatus,omitempty" example:"done"`
+	EnrichmentError  string `json:"enrichmentError,omitempty"`
+	// Error — причина, по которой элемент не импортирован.
+	Error string `json:"error,omitempty"`
 }
 
 // ErrorResponse описывает структуру ошибки для Swagger.
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// SongRevision — сохранённое состояние песни после изменения. Number нумерует ревизии песни
+// с 1; Fields — значения отслеживаемых полей песни (см. db.RevisionFields) строками,
+// Changes — поля, изменившиеся по сравнению с состоянием до изменения.
+type SongRevision struct {
+	ID        int               `gorm:"primaryKey"`
+	SongID    int               `json:"songID"`
+	Number    int               `json:"number"`
+	Author    string            `json:"author"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Fields    map[string]string `json:"fields" gorm:"serializer:json"`
+	Changes   []FieldChange     `json:"changes" gorm:"serializer:json"`
+}
+
+// FieldChange — изменение одного поля песни.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
@@ -2,19 +2,52 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// Статусы обогащения песни данными из внешнего API.
+const (
+	EnrichmentPending = "pending"
+	EnrichmentDone    = "done"
+	EnrichmentFailed  = "failed"
 )
 
 type Group struct {
 	ID   int    `gorm:"primaryKey"`
 	Name string `json:"name"`
+	// NormalizedName — название в нижнем регистре без лишних пробелов, по нему ищутся группы.
+	NormalizedName string `json:"-"`
+}
+
+// GroupAlias — альтернативное написание названия группы.
+type GroupAlias struct {
+	ID             int    `gorm:"primaryKey"`
+	GroupID        int    `json:"groupID"`
+	Name           string `json:"name"`
+	NormalizedName string `json:"-"`
 }
 
 type Song struct {
-	ID          int       `gorm:"primaryKey"`
-	GroupID     int       `json:"groupID"`
-	Group       Group     `gorm:"foreignKey:GroupID"`
-	Song        string    `json:"song"`
-	ReleaseDate time.Time `json:"releaseDate" gorm:"type:date"`
-	Text        string    `json:"text"`
-	Link        string    `json:"link"`
+	ID                 int        `gorm:"primaryKey"`
+	GroupID            int        `json:"groupID"`
+	Group              Group      `gorm:"foreignKey:GroupID"`
+	AlbumID            *int       `json:"albumID"`
+	Album              *Album     `gorm:"foreignKey:AlbumID"`
+	DiscNumber         int        `json:"discNumber"`
+	TrackNumber        int        `json:"trackNumber"`
+	Genres             []Genre    `gorm:"many2many:song_genres"`
+	Tags               []Tag      `gorm:"many2many:song_tags"`
+	Song               string     `json:"song"`
+	ReleaseDate        time.Time  `json:"releaseDate" gorm:"type:date"`
+	Duration           int        `json:"duration"`
+	Text               string     `json:"text"`
+	Language           string     `json:"language"`
+	Link               string     `json:"link"`
+	EnrichmentStatus   string     `json:"enrichmentStatus"`
+	EnrichmentAttempts int        `json:"enrichmentAttempts"`
+	EnrichmentError    string     `json:"enrichmentError"`
+	EnrichmentNextAt   *time.Time `json:"enrichmentNextAt"`
+	// DeletedAt — время удаления песни в корзину; песни в корзине не видны в обычных запросах.
+	DeletedAt gorm.DeletedAt `json:"deletedAt"`
 }
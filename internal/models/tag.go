@@ -0,0 +1,28 @@
+package models
+
+// Genre — жанр из общего справочника жанров.
+type Genre struct {
+	ID   int    `gorm:"primaryKey"`
+	Name string `json:"name"`
+	// NormalizedName — название в нижнем регистре без лишних пробелов, уникально.
+	NormalizedName string `json:"-"`
+}
+
+// Tag — произвольная пользовательская метка песни. Теги создаются при первом использовании.
+type Tag struct {
+	ID             int    `gorm:"primaryKey"`
+	Name           string `json:"name"`
+	NormalizedName string `json:"-"`
+}
+
+// SongGenre — связь песни с жанром.
+type SongGenre struct {
+	SongID  int `gorm:"primaryKey"`
+	GenreID int `gorm:"primaryKey"`
+}
+
+// SongTag — связь песни с тегом.
+type SongTag struct {
+	SongID int `gorm:"primaryKey"`
+	TagID  int `gorm:"primaryKey"`
+}
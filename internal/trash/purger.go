@@ -0,0 +1,89 @@
+// Package trash окончательно удаляет песни, пролежавшие в корзине дольше срока хранения.
+//
+// DELETE /songs/{id} перемещает песню в корзину, откуда её можно восстановить. Purger
+// периодически удаляет из корзины песни, удалённые раньше, чем Retention назад.
+package trash
+
+import (
+	"context"
+	"music_storage/internal/config"
+	"music_storage/internal/db"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Config задаёт параметры очистки корзины.
+type Config struct {
+	// Retention — срок хранения песен в корзине; 0 отключает автоматическую очистку.
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+// DefaultConfig возвращает параметры очистки корзины по умолчанию.
+func DefaultConfig() Config {
+	return Config{
+		Retention: 30 * 24 * time.Hour,
+		Interval:  time.Hour,
+	}
+}
+
+// ConfigFromEnv читает параметры очистки корзины из переменных окружения TRASH_RETENTION
+// и TRASH_PURGE_INTERVAL.
+func ConfigFromEnv() Config {
+	cfg := DefaultConfig()
+	cfg.Retention = config.Duration("TRASH_RETENTION", cfg.Retention)
+	cfg.Interval = config.Duration("TRASH_PURGE_INTERVAL", cfg.Interval)
+	return cfg
+}
+
+// Purger периодически очищает корзину.
+type Purger struct {
+	songs db.SongRepository
+	cfg   Config
+	now   func() time.Time
+}
+
+// New создаёт очистку корзины. Она запускается методом Start.
+func New(songs db.SongRepository, cfg Config) *Purger {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultConfig().Interval
+	}
+	return &Purger{songs: songs, cfg: cfg, now: time.Now}
+}
+
+// Start запускает периодическую очистку корзины. Останавливается при отмене ctx.
+func (p *Purger) Start(ctx context.Context) {
+	if p.cfg.Retention == 0 {
+		logrus.Info("Автоматическая очистка корзины отключена")
+		return
+	}
+	go p.run(ctx)
+	logrus.Infof("Запущена очистка корзины, срок хранения песен: %s", p.cfg.Retention)
+}
+
+// Purge окончательно удаляет песни, пролежавшие в корзине дольше срока хранения,
+// и возвращает их количество.
+func (p *Purger) Purge() (int, error) {
+	return p.songs.PurgeTrash(p.now().UTC().Add(-p.cfg.Retention))
+}
+
+func (p *Purger) run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := p.Purge()
+		if err != nil {
+			logrus.Errorf("Ошибка очистки корзины: %v", err)
+		} else if purged > 0 {
+			logrus.Infof("Из корзины окончательно удалено песен: %d", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
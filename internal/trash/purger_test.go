@@ -0,0 +1,151 @@
+package trash
+
+import (
+	"context"
+	"music_storage/internal/db"
+	"music_storage/internal/migrations"
+	"music_storage/internal/models"
+	"testing"
+	"time"
+)
+
+// storages возвращает хранилища, на которых проверяется очистка корзины: в памяти и в SQLite.
+func storages(t *testing.T) map[string]*db.Storage {
+	t.Helper()
+	t.Setenv("DB_DRIVER", db.DriverSQLite)
+	t.Setenv("DB_PATH", t.TempDir()+"/test.db")
+	conn := db.Connect()
+	m, err := migrations.New(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+	return map[string]*db.Storage{
+		"memory": db.NewMemoryStorage(),
+		"sqlite": db.NewGormStorage(conn),
+	}
+}
+
+// trashSongs добавляет песни с названиями names, перемещает их в корзину и возвращает их ID.
+func trashSongs(t *testing.T, storage *db.Storage, names ...string) []int {
+	t.Helper()
+	group, err := storage.Groups.FindOrCreate("Queen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, name := range names {
+		song := models.Song{GroupID: group.ID, Song: name}
+		if err := storage.Songs.Create(&song, "test"); err != nil {
+			t.Fatal(err)
+		}
+		if err := storage.Songs.Delete(song.ID); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, song.ID)
+	}
+	return ids
+}
+
+// trashSize возвращает число песен в корзине.
+func trashSize(t *testing.T, storage *db.Storage) int {
+	t.Helper()
+	songs, err := storage.Songs.ListTrash(100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(songs)
+}
+
+func TestPurge(t *testing.T) {
+	for name, storage := range storages(t) {
+		t.Run(name, func(t *testing.T) {
+			trashSongs(t, storage, "Innuendo", "Mustapha")
+			group, err := storage.Groups.FindOrCreate("Queen")
+			if err != nil {
+				t.Fatal(err)
+			}
+			kept := models.Song{GroupID: group.ID, Song: "Bohemian Rhapsody"}
+			if err := storage.Songs.Create(&kept, "test"); err != nil {
+				t.Fatal(err)
+			}
+
+			p := New(storage.Songs, Config{Retention: time.Hour})
+			deletedAt := time.Now()
+			for _, tc := range []struct {
+				after time.Duration
+				want  int
+				left  int
+			}{
+				{after: 30 * time.Minute, want: 0, left: 2},
+				{after: 2 * time.Hour, want: 2, left: 0},
+				{after: 3 * time.Hour, want: 0, left: 0},
+			} {
+				p.now = func() time.Time { return deletedAt.Add(tc.after) }
+				purged, err := p.Purge()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if purged != tc.want || trashSize(t, storage) != tc.left {
+					t.Errorf("через %s: удалено %d, в корзине %d, ожидалось %d и %d",
+						tc.after, purged, trashSize(t, storage), tc.want, tc.left)
+				}
+			}
+			if _, err := storage.Songs.GetByID(kept.ID); err != nil {
+				t.Errorf("песня не из корзины: %v", err)
+			}
+		})
+	}
+}
+
+func TestStartWithoutRetentionKeepsTrash(t *testing.T) {
+	storage := db.NewMemoryStorage()
+	trashSongs(t, storage, "Innuendo")
+
+	p := New(storage.Songs, Config{Retention: 0, Interval: time.Millisecond})
+	p.now = func() time.Time { return time.Now().Add(24 * time.Hour) }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p.Start(ctx)
+
+	time.Sleep(20 * time.Millisecond)
+	if size := trashSize(t, storage); size != 1 {
+		t.Errorf("при нулевом сроке хранения в корзине %d песен, ожидалась 1", size)
+	}
+}
+
+func TestStartPurgesPeriodically(t *testing.T) {
+	storage := db.NewMemoryStorage()
+	trashSongs(t, storage, "Innuendo")
+
+	p := New(storage.Songs, Config{Retention: time.Hour, Interval: time.Millisecond})
+	var now time.Time
+	clock := make(chan time.Time, 1)
+	clock <- time.Now()
+	p.now = func() time.Time {
+		select {
+		case now = <-clock:
+		default:
+		}
+		return now
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p.Start(ctx)
+
+	// Первая очистка при запуске ничего не удаляет, следующие — после сдвига часов.
+	time.Sleep(10 * time.Millisecond)
+	if size := trashSize(t, storage); size != 1 {
+		t.Fatalf("до истечения срока в корзине %d песен, ожидалась 1", size)
+	}
+	clock <- time.Now().Add(2 * time.Hour)
+	deadline := time.Now().Add(time.Second)
+	for trashSize(t, storage) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("корзина не очищена после истечения срока хранения")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
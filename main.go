@@ -1,12 +1,18 @@
 package main
 
 import (
+	"context"
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 	"github.com/swaggo/http-swagger"
 	"music_storage/internal/api"
 	"music_storage/internal/db"
+	"music_storage/internal/enrichment"
+	"music_storage/internal/external"
+	"music_storage/internal/idempotency"
+	"music_storage/internal/metadata"
+	"music_storage/internal/trash"
 	"net/http"
 	"os"
 
@@ -23,16 +29,86 @@ func main() {
 	}
 
 	logrus.Info("Подключение к базе данных...")
-	db.Connect()
+	conn := db.Connect()
 	logrus.Info("Подключение к базе данных установлено")
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCommand(conn, os.Args[2:])
+		return
+	}
+	applyMigrations(conn)
+	storage := db.NewGormStorage(conn)
+	provider, err := metadata.FromEnv(context.Background(), external.NewClient(external.ConfigFromEnv()), storage.MetadataCache)
+	if err != nil {
+		logrus.Fatal("Ошибка настройки источников метаданных: ", err)
+	}
+	enricher := enrichment.New(storage.Songs, provider, enrichment.ConfigFromEnv())
+	enricher.Start(context.Background())
+	trash.New(storage.Songs, trash.ConfigFromEnv()).Start(context.Background())
+	idempotencyStore := idempotency.New(storage.IdempotencyKeys, idempotency.ConfigFromEnv())
+	idempotencyStore.Start(context.Background())
+	server := api.NewServer(storage, enricher, idempotencyStore)
+
 	logrus.Info("Настройка маршрутов API")
 	r := mux.NewRouter()
 	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
-	r.HandleFunc("/songs", api.GetFilteredSongs).Methods("GET")
-	r.HandleFunc("/songs/{id}/text", api.GetSongText).Methods("GET")
-	r.HandleFunc("/songs/{id}", api.DeleteSong).Methods("DELETE")
-	r.HandleFunc("/songs/{id}", api.UpdateSong).Methods("PATCH")
-	r.HandleFunc("/songs", api.CreateSong).Methods("POST")
+	r.HandleFunc("/songs", server.GetFilteredSongs).Methods("GET")
+	r.HandleFunc("/songs/export", server.ExportSongs).Methods("GET")
+	r.HandleFunc("/songs/{id}/text", server.GetSongText).Methods("GET")
+	r.HandleFunc("/songs/{id}/lyrics", server.GetSongLyrics).Methods("GET")
+	r.HandleFunc("/songs/{id}/lyrics", server.SetSongLyrics).Methods("PUT")
+	r.HandleFunc("/songs/{id}/lrc", server.GetSongLRC).Methods("GET")
+	r.HandleFunc("/songs/{id}/lrc", server.SetSongLRC).Methods("PUT")
+	r.HandleFunc("/songs/{id}/lrc", server.DeleteSongLRC).Methods("DELETE")
+	r.HandleFunc("/songs/{id}/translations", server.ListSongTranslations).Methods("GET")
+	r.HandleFunc("/songs/{id}/translations/{lang}", server.SetSongTranslation).Methods("PUT")
+	r.HandleFunc("/songs/{id}/translations/{lang}", server.DeleteSongTranslation).Methods("DELETE")
+	r.HandleFunc("/songs/{id}/revisions", server.ListSongRevisions).Methods("GET")
+	r.HandleFunc("/songs/{id}/revisions/diff", server.DiffSongRevisions).Methods("GET")
+	r.HandleFunc("/songs/{id}/revisions/{rev}", server.GetSongRevision).Methods("GET")
+	r.HandleFunc("/songs/{id}/revisions/{rev}/restore", server.RestoreSongRevision).Methods("POST")
+	r.HandleFunc("/songs/{id}", server.DeleteSong).Methods("DELETE")
+	r.HandleFunc("/songs/{id}/restore", server.RestoreSong).Methods("POST")
+	r.HandleFunc("/trash", server.ListTrash).Methods("GET")
+	r.HandleFunc("/trash", server.EmptyTrash).Methods("DELETE")
+	r.HandleFunc("/trash/{id}", server.PurgeSong).Methods("DELETE")
+	r.HandleFunc("/songs/{id}", server.UpdateSong).Methods("PATCH")
+	r.HandleFunc("/songs", server.CreateSong).Methods("POST")
+	r.HandleFunc("/songs:batch", server.ImportSongs).Methods("POST")
+	r.HandleFunc("/songs/{id}/enrichment", server.GetSongEnrichment).Methods("GET")
+	r.HandleFunc("/songs/{id}/enrichment", server.RetrySongEnrichment).Methods("POST")
+	r.HandleFunc("/groups", server.ListGroups).Methods("GET")
+	r.HandleFunc("/groups/{id}", server.GetGroup).Methods("GET")
+	r.HandleFunc("/groups/{id}", server.UpdateGroup).Methods("PATCH")
+	r.HandleFunc("/groups/{id}", server.DeleteGroup).Methods("DELETE")
+	r.HandleFunc("/groups/{id}/songs", server.GetGroupSongs).Methods("GET")
+	r.HandleFunc("/groups/{id}/aliases", server.AddGroupAlias).Methods("POST")
+	r.HandleFunc("/groups/{id}/aliases/{aliasId}", server.DeleteGroupAlias).Methods("DELETE")
+	r.HandleFunc("/groups/{id}/merge", server.MergeGroups).Methods("POST")
+	r.HandleFunc("/albums", server.ListAlbums).Methods("GET")
+	r.HandleFunc("/albums", server.CreateAlbum).Methods("POST")
+	r.HandleFunc("/albums/{id}", server.GetAlbum).Methods("GET")
+	r.HandleFunc("/albums/{id}", server.UpdateAlbum).Methods("PATCH")
+	r.HandleFunc("/albums/{id}", server.DeleteAlbum).Methods("DELETE")
+	r.HandleFunc("/albums/{id}/songs", server.GetAlbumSongs).Methods("GET")
+	r.HandleFunc("/genres", server.ListGenres).Methods("GET")
+	r.HandleFunc("/genres", server.CreateGenre).Methods("POST")
+	r.HandleFunc("/genres/{id}", server.UpdateGenre).Methods("PATCH")
+	r.HandleFunc("/genres/{id}", server.DeleteGenre).Methods("DELETE")
+	r.HandleFunc("/tags", server.ListTags).Methods("GET")
+	r.HandleFunc("/tags/{id}", server.DeleteTag).Methods("DELETE")
+	r.HandleFunc("/songs/facets", server.GetSongFacets).Methods("GET")
+	r.HandleFunc("/songs/{id}/genres", server.SetSongGenres).Methods("PUT")
+	r.HandleFunc("/songs/{id}/tags", server.SetSongTags).Methods("PUT")
+	r.HandleFunc("/playlists", server.ListPlaylists).Methods("GET")
+	r.HandleFunc("/playlists", server.CreatePlaylist).Methods("POST")
+	r.HandleFunc("/playlists/{id}", server.GetPlaylist).Methods("GET")
+	r.HandleFunc("/playlists/{id}", server.UpdatePlaylist).Methods("PATCH")
+	r.HandleFunc("/playlists/{id}", server.DeletePlaylist).Methods("DELETE")
+	r.HandleFunc("/playlists/{id}/songs", server.GetPlaylistSongs).Methods("GET")
+	r.HandleFunc("/playlists/{id}/songs", server.AddPlaylistSong).Methods("POST")
+	r.HandleFunc("/playlists/{id}/songs/{position}", server.MovePlaylistSong).Methods("PATCH")
+	r.HandleFunc("/playlists/{id}/songs/{position}", server.RemovePlaylistSong).Methods("DELETE")
 
 	logrus.Info("Маршруты API настроены")
 
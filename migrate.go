@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"music_storage/internal/migrations"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// runMigrateCommand выполняет команду `migrate up|down|status`.
+func runMigrateCommand(conn *gorm.DB, args []string) {
+	migrator, err := migrations.New(conn)
+	if err != nil {
+		logrus.Fatalf("Ошибка инициализации миграций: %v", err)
+	}
+
+	if len(args) != 1 {
+		logrus.Fatal("Использование: migrate up|down|status")
+	}
+
+	switch args[0] {
+	case "up":
+		count, err := migrator.Up()
+		if err != nil {
+			logrus.Fatalf("Ошибка применения миграций: %v", err)
+		}
+		logrus.Infof("Применено миграций: %d", count)
+	case "down":
+		rolledBack, err := migrator.Down()
+		if err != nil {
+			logrus.Fatalf("Ошибка отката миграции: %v", err)
+		}
+		if !rolledBack {
+			logrus.Info("Нет применённых миграций для отката")
+		}
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			logrus.Fatalf("Ошибка получения статуса миграций: %v", err)
+		}
+		for _, status := range statuses {
+			state := "не применена"
+			if status.AppliedAt != nil {
+				state = "применена " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, state)
+		}
+	default:
+		logrus.Fatalf("Неизвестная команда миграций: %s", args[0])
+	}
+}
+
+// applyMigrations применяет неприменённые миграции при запуске сервера.
+func applyMigrations(conn *gorm.DB) {
+	migrator, err := migrations.New(conn)
+	if err != nil {
+		logrus.Fatalf("Ошибка инициализации миграций: %v", err)
+	}
+	count, err := migrator.Up()
+	if err != nil {
+		logrus.Fatalf("Ошибка применения миграций: %v", err)
+	}
+	logrus.Infof("Миграции применены, новых: %d", count)
+}